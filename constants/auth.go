@@ -5,3 +5,10 @@ const (
 	OwnerTypeMe     = "me"
 	OwnerTypePublic = "public"
 )
+
+const (
+	PermissionActionRead   = "read"
+	PermissionActionCreate = "create"
+	PermissionActionUpdate = "update"
+	PermissionActionDelete = "delete"
+)
@@ -4,3 +4,8 @@ const (
 	EngineScrapy = "scrapy"
 	EngineColly  = "colly"
 )
+
+const (
+	Spiderfile             = "Spiderfile"
+	ConfigSpiderScrapyName = "config_spider" // scrapy project and spider name of configurable spiders
+)
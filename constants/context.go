@@ -1,5 +1,6 @@
 package constants
 
 const (
-	ContextUser = "currentUser"
+	ContextUser       = "currentUser"
+	ContextOwnerQuery = "ownerQuery"
 )
@@ -0,0 +1,16 @@
+package constants
+
+const (
+	DataCollectionSchemaModeFlag   = "flag"   // non-conforming records are inserted with errors in ResultFieldErrors
+	DataCollectionSchemaModeReject = "reject" // non-conforming records are not inserted
+)
+
+const (
+	DataFieldTypeString  = "string"
+	DataFieldTypeNumber  = "number"
+	DataFieldTypeBoolean = "boolean"
+	DataFieldTypeDate    = "date"
+	DataFieldTypeObject  = "object"
+	DataFieldTypeArray   = "array"
+	DataFieldTypeNull    = "null"
+)
@@ -0,0 +1,16 @@
+package constants
+
+const (
+	DataSourceTypeMongo         = "mongo"
+	DataSourceTypePostgresql    = "postgresql"
+	DataSourceTypeMysql         = "mysql"
+	DataSourceTypeElasticsearch = "elasticsearch"
+	DataSourceTypeKafka         = "kafka"
+	DataSourceTypeFile          = "file"
+)
+
+const (
+	DefaultDataSourceBatchSize  = 100
+	DefaultDataSourceMaxRetries = 3
+	DefaultDataSourceSslMode    = "disable"
+)
@@ -1 +1,5 @@
 package constants
+
+const (
+	OwnerIdKey = "_uid" // User.Id of the creator stored in documents, empty if created by the system
+)
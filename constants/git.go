@@ -9,3 +9,21 @@ const (
 	GitRemoteNameUpstream = "upstream"
 	GitRemoteNameOrigin   = "origin"
 )
+
+const (
+	GitSyncTriggerManual = "manual"
+	GitSyncTriggerAuto   = "auto"
+)
+
+const (
+	GitSyncStatusSuccess = "success"
+	GitSyncStatusError   = "error"
+)
+
+const (
+	GitSyncLogExpireDays = 30 // retention of git sync logs
+)
+
+const (
+	GitSshKeyBits = 4096
+)
@@ -1,5 +1,20 @@
 package constants
 
 const (
-	ErrorRegexPattern = "(?:[ :,.]|^)((?:error|exception|traceback)s?)(?:[ :,.]|$)"
+	ErrorRegexPattern   = "(?:[ :,.]|^)((?:error|exception|traceback)s?)(?:[ :,.]|$)"
+	WarningRegexPattern = "(?:[ :,.\\[]|^)(warn(?:ing)?s?)(?:[ :,.\\]]|$)"
+	DebugRegexPattern   = "(?:[ :,.\\[]|^)(debug)(?:[ :,.\\]]|$)"
+)
+
+const (
+	LogLevelDebug   = "debug"
+	LogLevelInfo    = "info"
+	LogLevelWarning = "warning"
+	LogLevelError   = "error"
+)
+
+const (
+	LogStreamStdout = "stdout"
+	LogStreamStderr = "stderr"
+	LogStreamSystem = "system" // lines written by crawlab, e.g. restart events of long tasks
 )
@@ -1,13 +1,21 @@
 package constants
 
 const (
-	NotificationTriggerOnTaskEnd   = "notification_trigger_on_task_end"
-	NotificationTriggerOnTaskError = "notification_trigger_on_task_error"
-	NotificationTriggerNever       = "notification_trigger_never"
+	NotificationTriggerOnTaskEnd    = "notification_trigger_on_task_end"
+	NotificationTriggerOnTaskFinish = "notification_trigger_on_task_finish"
+	NotificationTriggerOnTaskError  = "notification_trigger_on_task_error"
+	NotificationTriggerOnTaskCancel = "notification_trigger_on_task_cancel"
+	NotificationTriggerNever        = "notification_trigger_never"
 )
 
 const (
 	NotificationTypeMail     = "notification_type_mail"
 	NotificationTypeDingTalk = "notification_type_ding_talk"
 	NotificationTypeWechat   = "notification_type_wechat"
+	NotificationTypeWebhook  = "notification_type_webhook"
+)
+
+const (
+	NotificationDeliveryStatusSuccess = "success"
+	NotificationDeliveryStatusError   = "error"
 )
@@ -0,0 +1,31 @@
+package constants
+
+const (
+	DedupMethodIgnore    = "ignore"    // skip records of which keys already exist
+	DedupMethodOverwrite = "overwrite" // overwrite existing records of the same keys
+	DedupMethodVersion   = "version"   // keep versioned history of records of the same keys
+)
+
+const (
+	ResultFieldDedupKey    = "_dk" // hash of values of dedup fields, or content hash if no dedup fields
+	ResultFieldContentHash = "_h"  // hash of content of the record
+	ResultFieldVersion     = "_v"  // version of the record in DedupMethodVersion, 0 in other dedup methods
+	ResultFieldErrors      = "_e"  // errors of the record not conforming to the schema in DataCollectionSchemaModeFlag
+)
+
+const (
+	ResultExportFormatCsv   = "csv"
+	ResultExportFormatJsonl = "jsonl"
+	ResultExportFormatXlsx  = "xlsx"
+)
+
+const (
+	ResultExportStatusPending  = "pending"
+	ResultExportStatusRunning  = "running"
+	ResultExportStatusFinished = "finished"
+	ResultExportStatusError    = "error"
+)
+
+const (
+	ResultExportExpireHours = 24 * 7 // retention of background result exports and their files
+)
@@ -8,3 +8,15 @@ const (
 	ScheduleStatusErrorNotFoundNode   = "Not Found Node"
 	ScheduleStatusErrorNotFoundSpider = "Not Found Spider"
 )
+
+const (
+	ScheduleOverlapPolicyAllow  = "allow"
+	ScheduleOverlapPolicySkip   = "skip"
+	ScheduleOverlapPolicyQueue  = "queue"
+	ScheduleOverlapPolicyCancel = "cancel"
+)
+
+const (
+	ScheduleSkipReasonStillRunning   = "previous run is still running"
+	ScheduleSkipReasonMaxConcurrency = "spider reaches max concurrency"
+)
@@ -3,3 +3,8 @@ package constants
 const ScrapyProtectedStageNames = ""
 
 const ScrapyProtectedFieldNames = "_id,task_id,ts"
+
+const (
+	ScrapySpiderTemplateBasic = "basic"
+	ScrapySpiderTemplateCrawl = "crawl"
+)
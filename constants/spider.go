@@ -5,3 +5,7 @@ const (
 	Configurable = "configurable"
 	Plugin       = "plugin"
 )
+
+// SpiderAutoCommitMessage message of the version committed automatically when
+// a spider runs with uncommitted changes of files
+const SpiderAutoCommitMessage = "auto commit of uncommitted changes before run"
@@ -6,6 +6,7 @@ const (
 	TaskStatusFinished  = "finished"
 	TaskStatusError     = "error"
 	TaskStatusCancelled = "cancelled"
+	TaskStatusTimeout   = "timeout"
 )
 
 const (
@@ -17,6 +18,12 @@ const (
 	RunTypeAllNodes      = "all-nodes"
 	RunTypeRandom        = "random"
 	RunTypeSelectedNodes = "selected-nodes"
+	RunTypeSelectedTags  = "selected-tags"
+)
+
+const (
+	NodeTagsMatchAll = "all"
+	NodeTagsMatchAny = "any"
 )
 
 const (
@@ -31,9 +38,38 @@ const (
 	TaskSignalCancel
 	TaskSignalError
 	TaskSignalLost
+	TaskSignalTimeout
 )
 
 const (
 	TaskListQueuePrefixPublic = "tasks:public"
 	TaskListQueuePrefixNodes  = "tasks:nodes"
 )
+
+const (
+	TaskPendingReasonNoMatchingNodes      = "no enabled and active nodes matching node tags"
+	TaskPendingReasonNoAvailableRunners   = "no available runners on nodes matching node tags"
+	TaskPendingReasonScheduleStillRunning = "previous run of schedule is still running"
+	TaskPendingReasonRetryBackoff         = "waiting for backoff delay of retry"
+)
+
+const (
+	TaskRetryBackoffFixed       = "fixed"
+	TaskRetryBackoffExponential = "exponential"
+)
+
+const (
+	TaskRetryOnError   = TaskStatusError
+	TaskRetryOnLost    = "lost"
+	TaskRetryOnTimeout = TaskStatusTimeout
+)
+
+const (
+	TaskLogStreamEventLog = "log"
+	TaskLogStreamEventEnd = "end"
+)
+
+const (
+	DefaultLongTaskMaxErrors          = 5
+	DefaultLongTaskBackoffMaxInterval = 60 // in seconds
+)
@@ -13,3 +13,14 @@ const (
 const (
 	UserContextKey = "user"
 )
+
+const (
+	TokenScopeAll           = "all"            // full access of the user
+	TokenScopeReadOnly      = "read-only"      // GET requests
+	TokenScopeRunTasks      = "run-tasks"      // run, restart and cancel tasks, spiders and workflows
+	TokenScopeManageSpiders = "manage-spiders" // create, update and delete spiders and their files
+)
+
+const (
+	DefaultTokenExpireHours = 24 * 7 // expiration of login tokens
+)
@@ -6,4 +6,11 @@ const (
 	Boolean = "boolean"
 	Array   = "array"
 	Object  = "object"
+
+	// Expression python expression of scrapy settings, kept as is
+	Expression = "expression"
+)
+
+const (
+	SecretMask = "******"
 )
@@ -0,0 +1,20 @@
+package constants
+
+const (
+	WorkflowRunStatusRunning   = "running"
+	WorkflowRunStatusFinished  = "finished"
+	WorkflowRunStatusError     = "error"
+	WorkflowRunStatusCancelled = "cancelled"
+)
+
+const (
+	WorkflowNodeStatusPending = "pending"
+	WorkflowNodeStatusRunning = "running"
+	WorkflowNodeStatusSkipped = "skipped"
+)
+
+const (
+	WorkflowEdgeConditionSuccess = "success"
+	WorkflowEdgeConditionFailure = "failure"
+	WorkflowEdgeConditionAlways  = "always"
+)
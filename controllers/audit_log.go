@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+)
+
+var AuditLogController *auditLogController
+
+type auditLogController struct {
+	ListControllerDelegate
+	modelSvc service.ModelService
+}
+
+// GetList get audit logs matching the filter, latest first
+func (ctr *auditLogController) GetList(c *gin.Context) {
+	// filter
+	var f entity.AuditLogFilter
+	if err := c.ShouldBindQuery(&f); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	query, err := ctr._getQuery(&f)
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// pagination
+	p := MustGetPagination(c)
+
+	// list
+	list, err := ctr.modelSvc.GetAuditLogList(query, &mongo.FindOptions{
+		Skip:  (p.Page - 1) * p.Size,
+		Limit: p.Size,
+		Sort:  bson.D{{"_id", -1}},
+	})
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleSuccessWithListData(c, nil, 0)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// total
+	total, err := ctr.modelSvc.GetBaseService(interfaces.ModelIdAuditLog).Count(query)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, list, total)
+}
+
+func (ctr *auditLogController) _getQuery(f *entity.AuditLogFilter) (query bson.M, err error) {
+	query = bson.M{}
+	if f.Col != "" {
+		query["col"] = f.Col
+	}
+	if f.ModelId != "" {
+		query["model_id"], err = primitive.ObjectIDFromHex(f.ModelId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if f.UserId != "" {
+		query["user_id"], err = primitive.ObjectIDFromHex(f.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if f.Method != "" {
+		query["method"] = f.Method
+	}
+	if f.Key != "" {
+		query["diffs.key"] = f.Key
+	}
+	if !f.StartTs.IsZero() || !f.EndTs.IsZero() {
+		tsQuery := bson.M{}
+		if !f.StartTs.IsZero() {
+			tsQuery["$gte"] = f.StartTs
+		}
+		if !f.EndTs.IsZero() {
+			tsQuery["$lte"] = f.EndTs
+		}
+		query["ts"] = tsQuery
+	}
+	return query, nil
+}
+
+func newAuditLogController() *auditLogController {
+	modelSvc, err := service.GetService()
+	if err != nil {
+		panic(err)
+	}
+
+	ctr := NewListControllerDelegate(ControllerIdAuditLog, modelSvc.GetBaseService(interfaces.ModelIdAuditLog))
+
+	return &auditLogController{
+		ListControllerDelegate: *ctr,
+		modelSvc:               modelSvc,
+	}
+}
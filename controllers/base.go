@@ -25,6 +25,11 @@ const (
 	ControllerIdPluginDo
 	ControllerIdGit
 	ControllerIdVersion
+	ControllerIdWorkflow
+	ControllerIdNotificationSetting
+	ControllerIdNotificationDelivery
+	ControllerIdAuditLog
+	ControllerIdMe
 )
 
 type ControllerId int
@@ -63,6 +63,18 @@ func (b *JsonBinder) Bind(c *gin.Context) (res interfaces.Model, err error) {
 	case ControllerIdGit:
 		err = c.ShouldBindJSON(&m.Git)
 		return &m.Git, nil
+	case ControllerIdWorkflow:
+		err = c.ShouldBindJSON(&m.Workflow)
+		return &m.Workflow, err
+	case ControllerIdNotificationSetting:
+		err = c.ShouldBindJSON(&m.NotificationSetting)
+		return &m.NotificationSetting, err
+	case ControllerIdNotificationDelivery:
+		err = c.ShouldBindJSON(&m.NotificationDelivery)
+		return &m.NotificationDelivery, err
+	case ControllerIdAuditLog:
+		err = c.ShouldBindJSON(&m.AuditLog)
+		return &m.AuditLog, err
 	default:
 		return nil, errors.ErrorControllerInvalidControllerId
 	}
@@ -1,3 +1,182 @@
 package controllers
 
-var DataCollectionController ListController
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/luke513009828/crawlab-core/result"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/dig"
+	"net/http"
+)
+
+var DataCollectionController ListActionController
+
+func getDataCollectionActions() []Action {
+	dataCollectionCtx := newDataCollectionContext()
+	return []Action{
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/fields",
+			HandlerFunc: dataCollectionCtx.getFieldList,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/fields",
+			HandlerFunc: dataCollectionCtx.postFields,
+		},
+	}
+}
+
+type dataCollectionContext struct {
+	modelSvc service.ModelService
+}
+
+// getFieldList fields of the data collection inferred from records and
+// declared by users
+func (ctx *dataCollectionContext) getFieldList(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	fields, err := ctx._getFieldList(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, fields, len(fields))
+}
+
+// postFields declare names, types and whether required of fields of the data
+// collection, which are added if not seen yet
+func (ctx *dataCollectionContext) postFields(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// data collection
+	if _, err := ctx.modelSvc.GetDataCollectionById(id); err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// payload
+	var fields []models.DataField
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	for _, f := range fields {
+		if f.Key == "" {
+			HandleErrorBadRequest(c, errors.ErrorResultEmptyFieldKey)
+			return
+		}
+		if !result.IsValidDataFieldType(f.Type) {
+			HandleErrorBadRequest(c, errors.ErrorResultInvalidFieldType)
+			return
+		}
+	}
+
+	for _, f := range fields {
+		if f.Name == "" {
+			f.Name = f.Key
+		}
+		existing, err := ctx.modelSvc.GetDataField(bson.M{"col_id": id, "key": f.Key}, nil)
+		if err == nil {
+			// observed stats are updated concurrently as records arrive
+			if err := mongo.GetMongoCol(interfaces.ModelColNameDataField).UpdateId(existing.Id, bson.M{
+				"$set": bson.M{
+					"name":     f.Name,
+					"type":     f.Type,
+					"required": f.Required,
+				},
+			}); err != nil {
+				HandleErrorInternalServerError(c, err)
+				return
+			}
+			continue
+		}
+		if err.Error() != mongo2.ErrNoDocuments.Error() {
+			HandleErrorInternalServerError(c, err)
+			return
+		}
+		d := models.DataField{
+			Id:       primitive.NewObjectID(),
+			ColId:    id,
+			Key:      f.Key,
+			Name:     f.Name,
+			Type:     f.Type,
+			Required: f.Required,
+		}
+		if err := delegate.NewModelDelegate(&d, GetUserFromContext(c)).Add(); err != nil {
+			HandleErrorInternalServerError(c, err)
+			return
+		}
+	}
+
+	list, err := ctx._getFieldList(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, list, len(list))
+}
+
+func (ctx *dataCollectionContext) _getFieldList(id primitive.ObjectID) (fields []models.DataField, err error) {
+	fields, err = ctx.modelSvc.GetDataFieldList(bson.M{"col_id": id}, &mongo.FindOptions{
+		Sort: bson.D{{"key", 1}},
+	})
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			return []models.DataField{}, nil
+		}
+		return nil, err
+	}
+	for i := range fields {
+		fields[i].NullRate = fields[i].GetNullRate()
+	}
+	return fields, nil
+}
+
+func newDataCollectionContext() *dataCollectionContext {
+	// context
+	ctx := &dataCollectionContext{}
+
+	// dependency injection
+	c := dig.New()
+	if err := c.Provide(service.GetService); err != nil {
+		panic(err)
+	}
+	if err := c.Invoke(func(modelSvc service.ModelService) {
+		ctx.modelSvc = modelSvc
+	}); err != nil {
+		panic(err)
+	}
+
+	return ctx
+}
+
+func newDataCollectionController() *ListActionControllerDelegate {
+	modelSvc, err := service.GetService()
+	if err != nil {
+		panic(err)
+	}
+
+	return NewListPostActionControllerDelegate(ControllerIdDataCollection, modelSvc.GetBaseService(interfaces.ModelIdDataCollection), getDataCollectionActions())
+}
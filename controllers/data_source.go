@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/ds"
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/dig"
+	"net/http"
+)
+
+var DataSourceController *dataSourceController
+
+func getDataSourceActions() []Action {
+	dataSourceCtx := newDataSourceContext()
+	return []Action{
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/test",
+			HandlerFunc: dataSourceCtx.test,
+		},
+	}
+}
+
+type dataSourceController struct {
+	ListActionControllerDelegate
+	ctx *dataSourceContext
+}
+
+func (ctr *dataSourceController) Get(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	dataSource, err := ctr.ctx.modelSvc.GetDataSourceById(id)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	maskDataSourcePassword(dataSource)
+	HandleSuccessWithData(c, dataSource)
+}
+
+func (ctr *dataSourceController) GetList(c *gin.Context) {
+	// params
+	query := MustGetFilterQuery(c)
+	opts := &mongo.FindOptions{
+		Sort: MustGetSortOption(c),
+	}
+	if !MustGetFilterAll(c) {
+		pagination := MustGetPagination(c)
+		opts.Skip = pagination.Size * (pagination.Page - 1)
+		opts.Limit = pagination.Size
+	}
+
+	// data sources
+	list, err := ctr.ctx.modelSvc.GetDataSourceList(query, opts)
+	if err != nil && err.Error() != mongo2.ErrNoDocuments.Error() {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	for i := range list {
+		maskDataSourcePassword(&list[i])
+	}
+
+	// total count
+	total, err := ctr.ctx.modelSvc.GetBaseService(interfaces.ModelIdDataSource).Count(query)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, list, total)
+}
+
+func (ctr *dataSourceController) Put(c *gin.Context) {
+	var dataSource models.DataSource
+	if err := c.ShouldBindJSON(&dataSource); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if err := ds.EncryptPassword(&dataSource); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	if err := delegate.NewModelDelegate(&dataSource, GetUserFromContext(c)).Add(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	maskDataSourcePassword(&dataSource)
+	HandleSuccessWithData(c, dataSource)
+}
+
+func (ctr *dataSourceController) Post(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	var dataSource models.DataSource
+	if err := c.ShouldBindJSON(&dataSource); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if dataSource.Id != id {
+		HandleErrorBadRequest(c, errors.ErrorHttpBadRequest)
+		return
+	}
+	dataSourceDb, err := ctr.ctx.modelSvc.GetDataSourceById(id)
+	if err != nil {
+		HandleErrorNotFound(c, err)
+		return
+	}
+
+	// password is unchanged if it's masked
+	if dataSource.Password == constants.SecretMask {
+		dataSource.Password = dataSourceDb.Password
+	} else if err := ds.EncryptPassword(&dataSource); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	if err := delegate.NewModelDelegate(&dataSource, GetUserFromContext(c)).Save(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	maskDataSourcePassword(&dataSource)
+	HandleSuccessWithData(c, dataSource)
+}
+
+func (ctr *dataSourceController) PostList(c *gin.Context) {
+	// payload
+	var payload entity.BatchRequestPayloadWithStringData
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// doc to update
+	var doc models.DataSource
+	if err := json.Unmarshal([]byte(payload.Data), &doc); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// password is unchanged if it's masked
+	var fields []string
+	for _, field := range payload.Fields {
+		if field == "password" {
+			if doc.Password == constants.SecretMask {
+				continue
+			}
+			if err := ds.EncryptPassword(&doc); err != nil {
+				HandleErrorInternalServerError(c, err)
+				return
+			}
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		HandleSuccess(c)
+		return
+	}
+
+	// query
+	query := WithOwnerQuery(c, bson.M{
+		"_id": bson.M{
+			"$in": payload.Ids,
+		},
+	})
+
+	// update data sources
+	if err := ctr.ctx.modelSvc.GetBaseService(interfaces.ModelIdDataSource).UpdateDoc(query, &doc, fields); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+type dataSourceContext struct {
+	modelSvc service.ModelService
+}
+
+// test check connectivity of the data source
+func (ctx *dataSourceContext) test(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	dataSource, err := ctx.modelSvc.GetDataSourceById(id)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	if err := ds.TestDataSource(dataSource); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+func newDataSourceContext() *dataSourceContext {
+	// context
+	ctx := &dataSourceContext{}
+
+	// dependency injection
+	c := dig.New()
+	if err := c.Provide(service.GetService); err != nil {
+		panic(err)
+	}
+	if err := c.Invoke(func(modelSvc service.ModelService) {
+		ctx.modelSvc = modelSvc
+	}); err != nil {
+		panic(err)
+	}
+
+	return ctx
+}
+
+// maskDataSourcePassword hide the encrypted password of the data source in responses
+func maskDataSourcePassword(dataSource *models.DataSource) {
+	if dataSource.Password != "" {
+		dataSource.Password = constants.SecretMask
+	}
+}
+
+func newDataSourceController() *dataSourceController {
+	modelSvc, err := service.GetService()
+	if err != nil {
+		panic(err)
+	}
+
+	ctr := NewListPostActionControllerDelegate(ControllerIdDataSource, modelSvc.GetBaseService(interfaces.ModelIdDataSource), getDataSourceActions())
+
+	return &dataSourceController{
+		ListActionControllerDelegate: *ctr,
+		ctx:                          newDataSourceContext(),
+	}
+}
@@ -76,11 +76,11 @@ func (d *ListControllerDelegate) PostList(c *gin.Context) {
 	}
 
 	// query
-	query := bson.M{
+	query := WithOwnerQuery(c, bson.M{
 		"_id": bson.M{
 			"$in": payload.Ids,
 		},
-	}
+	})
 
 	// update
 	if err := d.svc.UpdateDoc(query, doc, payload.Fields); err != nil {
@@ -147,11 +147,11 @@ func (d *ListControllerDelegate) DeleteList(c *gin.Context) {
 		HandleErrorBadRequest(c, err)
 		return
 	}
-	if err := d.svc.DeleteList(bson.M{
+	if err := d.svc.DeleteList(WithOwnerQuery(c, bson.M{
 		"_id": bson.M{
 			"$in": payload.Ids,
 		},
-	}); err != nil {
+	})); err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
@@ -159,8 +159,11 @@ func (d *ListControllerDelegate) DeleteList(c *gin.Context) {
 }
 
 func (d *ListControllerDelegate) getAll(c *gin.Context) {
+	// query
+	query := WithOwnerQuery(c, nil)
+
 	// get list
-	list, err := d.svc.GetList(nil, nil)
+	list, err := d.svc.GetList(query, nil)
 	if err != nil {
 		if err == mongo2.ErrNoDocuments {
 			HandleErrorNotFound(c, err)
@@ -172,7 +175,7 @@ func (d *ListControllerDelegate) getAll(c *gin.Context) {
 	data := list.Values()
 
 	// total count
-	total, err := d.svc.Count(nil)
+	total, err := d.svc.Count(query)
 	if err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
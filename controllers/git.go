@@ -1,3 +1,188 @@
 package controllers
 
-var GitController ListController
+import (
+	"encoding/json"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+)
+
+var GitController *gitController
+
+type gitController struct {
+	ListControllerDelegate
+	modelSvc service.ModelService
+}
+
+func (ctr *gitController) Get(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	g, err := ctr.modelSvc.GetGitById(id)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	maskGitPassword(g)
+	HandleSuccessWithData(c, g)
+}
+
+func (ctr *gitController) GetList(c *gin.Context) {
+	// params
+	query := MustGetFilterQuery(c)
+	opts := &mongo.FindOptions{
+		Sort: MustGetSortOption(c),
+	}
+	if !MustGetFilterAll(c) {
+		pagination := MustGetPagination(c)
+		opts.Skip = pagination.Size * (pagination.Page - 1)
+		opts.Limit = pagination.Size
+	}
+
+	// gits
+	list, err := ctr.modelSvc.GetGitList(query, opts)
+	if err != nil && err.Error() != mongo2.ErrNoDocuments.Error() {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	for i := range list {
+		maskGitPassword(&list[i])
+	}
+
+	// total count
+	total, err := ctr.modelSvc.GetBaseService(interfaces.ModelIdGit).Count(query)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, list, total)
+}
+
+func (ctr *gitController) Put(c *gin.Context) {
+	var g models.Git
+	if err := c.ShouldBindJSON(&g); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if err := delegate.NewModelDelegate(&g, GetUserFromContext(c)).Add(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	maskGitPassword(&g)
+	HandleSuccessWithData(c, g)
+}
+
+func (ctr *gitController) Post(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	var g models.Git
+	if err := c.ShouldBindJSON(&g); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if g.Id != id {
+		HandleErrorBadRequest(c, errors.ErrorHttpBadRequest)
+		return
+	}
+	gDb, err := ctr.modelSvc.GetGitById(id)
+	if err != nil {
+		HandleErrorNotFound(c, err)
+		return
+	}
+
+	// password is unchanged if it's masked
+	if g.Password == constants.SecretMask {
+		g.Password = gDb.Password
+	}
+
+	if err := delegate.NewModelDelegate(&g, GetUserFromContext(c)).Save(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	maskGitPassword(&g)
+	HandleSuccessWithData(c, g)
+}
+
+func (ctr *gitController) PostList(c *gin.Context) {
+	// payload
+	var payload entity.BatchRequestPayloadWithStringData
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// doc to update
+	var doc models.Git
+	if err := json.Unmarshal([]byte(payload.Data), &doc); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// password is unchanged if it's masked
+	var fields []string
+	for _, field := range payload.Fields {
+		if field == "password" && doc.Password == constants.SecretMask {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		HandleSuccess(c)
+		return
+	}
+
+	// query
+	query := WithOwnerQuery(c, bson.M{
+		"_id": bson.M{
+			"$in": payload.Ids,
+		},
+	})
+
+	// update gits
+	if err := ctr.modelSvc.GetBaseService(interfaces.ModelIdGit).UpdateDoc(query, &doc, fields); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+// maskGitPassword hide the password or legacy SSH private key of the git repo in responses
+func maskGitPassword(g *models.Git) {
+	if g.Password != "" {
+		g.Password = constants.SecretMask
+	}
+}
+
+func newGitController() *gitController {
+	modelSvc, err := service.GetService()
+	if err != nil {
+		panic(err)
+	}
+
+	ctr := NewListControllerDelegate(ControllerIdGit, modelSvc.GetBaseService(interfaces.ModelIdGit))
+
+	return &gitController{
+		ListControllerDelegate: *ctr,
+		modelSvc:               modelSvc,
+	}
+}
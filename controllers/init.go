@@ -16,20 +16,27 @@ func InitControllers() (err error) {
 	SpiderController = newSpiderController()
 	TaskController = newTaskController()
 	UserController = newUserController()
+	MeController = NewActionControllerDelegate(ControllerIdMe, getMeActions())
 	TagController = NewListControllerDelegate(ControllerIdTag, modelSvc.GetBaseService(interfaces.ModelIdTag))
 	SettingController = newSettingController()
 	LoginController = NewActionControllerDelegate(ControllerIdLogin, getLoginActions())
 	ColorController = NewActionControllerDelegate(ControllerIdColor, getColorActions())
 	PluginController = newPluginController()
-	DataCollectionController = NewListControllerDelegate(ControllerIdDataCollection, modelSvc.GetBaseService(interfaces.ModelIdDataCollection))
+	DataCollectionController = newDataCollectionController()
 	ResultController = NewActionControllerDelegate(ControllerIdResult, getResultActions())
 	ScheduleController = newScheduleController()
 	StatsController = NewActionControllerDelegate(ControllerIdStats, getStatsActions())
 	TokenController = newTokenController()
 	FilerController = NewActionControllerDelegate(ControllerIdFiler, getFilerActions())
 	PluginProxyController = NewActionControllerDelegate(ControllerIdPluginDo, getPluginProxyActions())
-	GitController = NewListControllerDelegate(ControllerIdGit, modelSvc.GetBaseService(interfaces.ModelIdGit))
+	GitController = newGitController()
 	VersionController = NewActionControllerDelegate(ControllerIdVersion, getVersionActions())
+	VariableController = newVariableController()
+	WorkflowController = newWorkflowController()
+	NotificationSettingController = newNotificationSettingController()
+	NotificationDeliveryController = NewListControllerDelegate(ControllerIdNotificationDelivery, modelSvc.GetBaseService(interfaces.ModelIdNotificationDelivery))
+	AuditLogController = newAuditLogController()
+	DataSourceController = newDataSourceController()
 
 	return nil
 }
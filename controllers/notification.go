@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var NotificationSettingController *notificationSettingController
+
+var NotificationDeliveryController ListController
+
+type notificationSettingController struct {
+	ListControllerDelegate
+	svc interfaces.ModelBaseService
+}
+
+func (ctr *notificationSettingController) Put(c *gin.Context) {
+	var s models.NotificationSetting
+	if err := c.ShouldBindJSON(&s); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	ctr._setUserId(c, &s)
+	if err := delegate.NewModelDelegate(&s, GetUserFromContext(c)).Add(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	HandleSuccessWithData(c, s)
+}
+
+func (ctr *notificationSettingController) Post(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	var s models.NotificationSetting
+	if err := c.ShouldBindJSON(&s); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if s.Id != id {
+		HandleErrorBadRequest(c, errors.ErrorHttpBadRequest)
+		return
+	}
+	if _, err := ctr.svc.GetById(id); err != nil {
+		HandleErrorNotFound(c, err)
+		return
+	}
+	ctr._setUserId(c, &s)
+	if err := delegate.NewModelDelegate(&s, GetUserFromContext(c)).Save(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	HandleSuccessWithData(c, s)
+}
+
+// _setUserId limit notifications of settings of non-admin users to their own
+// tasks, as settings without users apply to tasks of all users
+func (ctr *notificationSettingController) _setUserId(c *gin.Context, s *models.NotificationSetting) {
+	if isAdmin(c) {
+		return
+	}
+	if u := GetUserFromContext(c); u != nil {
+		s.UserId = u.GetId()
+	}
+}
+
+func newNotificationSettingController() *notificationSettingController {
+	modelSvc, err := service.GetService()
+	if err != nil {
+		panic(err)
+	}
+
+	svc := modelSvc.GetBaseService(interfaces.ModelIdNotificationSetting)
+	ctr := NewListControllerDelegate(ControllerIdNotificationSetting, svc)
+
+	return &notificationSettingController{
+		ListControllerDelegate: *ctr,
+		svc:                    svc,
+	}
+}
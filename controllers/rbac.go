@@ -0,0 +1,327 @@
+package controllers
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+// RbacPermissions owner types of resources on which actions are permitted, keyed by action
+type RbacPermissions map[string]string
+
+var rbacPermissionsRead = RbacPermissions{
+	constants.PermissionActionRead: constants.OwnerTypeAll,
+}
+
+var rbacPermissionsWrite = RbacPermissions{
+	constants.PermissionActionRead:   constants.OwnerTypeAll,
+	constants.PermissionActionCreate: constants.OwnerTypeAll,
+	constants.PermissionActionUpdate: constants.OwnerTypeAll,
+}
+
+var rbacPermissionsAll = RbacPermissions{
+	constants.PermissionActionRead:   constants.OwnerTypeAll,
+	constants.PermissionActionCreate: constants.OwnerTypeAll,
+	constants.PermissionActionUpdate: constants.OwnerTypeAll,
+	constants.PermissionActionDelete: constants.OwnerTypeAll,
+}
+
+var rbacPermissionsOwned = RbacPermissions{
+	constants.PermissionActionRead:   constants.OwnerTypeMe,
+	constants.PermissionActionCreate: constants.OwnerTypeAll,
+	constants.PermissionActionUpdate: constants.OwnerTypeMe,
+	constants.PermissionActionDelete: constants.OwnerTypeMe,
+}
+
+// rbacRolePermissions permissions of roles per controller, actions or controllers
+// absent are forbidden. Admin users are permitted all actions on all resources.
+var rbacRolePermissions = map[string]map[ControllerId]RbacPermissions{
+	constants.RoleNormal: {
+		ControllerIdNode:    rbacPermissionsRead,
+		ControllerIdProject: rbacPermissionsAll,
+		ControllerIdSpider: {
+			constants.PermissionActionRead:   constants.OwnerTypePublic,
+			constants.PermissionActionCreate: constants.OwnerTypeAll,
+			constants.PermissionActionUpdate: constants.OwnerTypeMe,
+			constants.PermissionActionDelete: constants.OwnerTypeMe,
+		},
+		ControllerIdTask:     rbacPermissionsOwned,
+		ControllerIdSchedule: rbacPermissionsOwned,
+		ControllerIdUser: {
+			constants.PermissionActionRead: constants.OwnerTypeMe,
+		},
+		ControllerIdSetting:        rbacPermissionsRead,
+		ControllerIdToken:          rbacPermissionsOwned,
+		ControllerIdVariable:       rbacPermissionsRead,
+		ControllerIdTag:            rbacPermissionsWrite,
+		ControllerIdColor:          rbacPermissionsRead,
+		ControllerIdPlugin:         rbacPermissionsRead,
+		ControllerIdDataCollection: rbacPermissionsWrite,
+		ControllerIdDataSource:     rbacPermissionsRead,
+		ControllerIdResult: {
+			constants.PermissionActionRead:   constants.OwnerTypeAll,
+			constants.PermissionActionCreate: constants.OwnerTypeAll,
+		},
+		ControllerIdStats:               rbacPermissionsRead,
+		ControllerIdPluginDo:            rbacPermissionsAll,
+		ControllerIdWorkflow:            rbacPermissionsOwned,
+		ControllerIdNotificationSetting: rbacPermissionsOwned,
+		ControllerIdNotificationDelivery: {
+			constants.PermissionActionRead: constants.OwnerTypeMe,
+		},
+	},
+}
+
+// rbacOwnedColNames collections of resources subject to ownership
+var rbacOwnedColNames = map[ControllerId]string{
+	ControllerIdSpider:               interfaces.ModelColNameSpider,
+	ControllerIdTask:                 interfaces.ModelColNameTask,
+	ControllerIdSchedule:             interfaces.ModelColNameSchedule,
+	ControllerIdUser:                 interfaces.ModelColNameUser,
+	ControllerIdToken:                interfaces.ModelColNameToken,
+	ControllerIdWorkflow:             interfaces.ModelColNameWorkflow,
+	ControllerIdNotificationSetting:  interfaces.ModelColNameNotificationSetting,
+	ControllerIdNotificationDelivery: interfaces.ModelColNameNotificationDelivery,
+}
+
+// rbacPublicQueries queries of resources readable by all users
+var rbacPublicQueries = map[ControllerId]bson.M{
+	ControllerIdSpider: {"is_public": true},
+}
+
+// GetPermissionAction get permission action of the http method
+func GetPermissionAction(method string) (action string) {
+	switch method {
+	case http.MethodPut:
+		return constants.PermissionActionCreate
+	case http.MethodPost:
+		return constants.PermissionActionUpdate
+	case http.MethodDelete:
+		return constants.PermissionActionDelete
+	default:
+		return constants.PermissionActionRead
+	}
+}
+
+// GetPermissionOwnerType get owner type of resources on which the role is
+// permitted the action of the controller, empty if not permitted
+func GetPermissionOwnerType(role string, id ControllerId, action string) (ownerType string) {
+	if role == constants.RoleAdmin {
+		return constants.OwnerTypeAll
+	}
+	perms, ok := rbacRolePermissions[role][id]
+	if !ok {
+		return ""
+	}
+	ownerType = perms[action]
+	if ownerType == "" || ownerType == constants.OwnerTypeAll {
+		return ownerType
+	}
+	if _, ok := rbacOwnedColNames[id]; !ok {
+		// resources of the controller are not owned by users
+		return constants.OwnerTypeAll
+	}
+	return ownerType
+}
+
+// CheckPermission check if the current user is permitted the action of the
+// request on the controller. Requests on a single resource (with "id" param)
+// are checked against the ownership of the resource, otherwise the query of
+// resources owned by the user is set in the context to limit list queries.
+func CheckPermission(c *gin.Context, id ControllerId) (err error) {
+	action := GetPermissionAction(c.Request.Method)
+
+	// single resource
+	if resId, err := primitive.ObjectIDFromHex(c.Param("id")); err == nil {
+		return CheckResourcePermission(c, id, action, resId)
+	}
+
+	// owner type
+	u, ownerType, err := getPermissionOwnerType(c, id, action)
+	if err != nil {
+		return err
+	}
+	if ownerType == constants.OwnerTypeAll {
+		return nil
+	}
+
+	// owner query of list
+	q, err := getOwnerQuery(u, id, ownerType)
+	if err != nil {
+		return err
+	}
+	c.Set(constants.ContextOwnerQuery, q)
+
+	return nil
+}
+
+// CheckResourcePermission check if the current user is permitted the action
+// on the resource of the controller
+func CheckResourcePermission(c *gin.Context, id ControllerId, action string, resId primitive.ObjectID) (err error) {
+	u, ownerType, err := getPermissionOwnerType(c, id, action)
+	if err != nil {
+		return err
+	}
+	if ownerType == constants.OwnerTypeAll {
+		return nil
+	}
+	ok, err := isResourceOwned(u, id, resId, ownerType)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.ErrorUserForbidden
+	}
+	return nil
+}
+
+// CheckDataCollectionPermission check if the current user is permitted to
+// access results of the data collection, which requires owning any spider
+// storing results in the data collection
+func CheckDataCollectionPermission(c *gin.Context, colId primitive.ObjectID) (err error) {
+	u := GetUserFromContext(c)
+	if u == nil {
+		return errors.ErrorUserForbidden
+	}
+	if u.GetRole() == constants.RoleAdmin {
+		return nil
+	}
+	total, err := mongo.GetMongoCol(interfaces.ModelColNameSpider).Count(bson.M{
+		"col_id":             colId,
+		constants.OwnerIdKey: u.GetId(),
+	})
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		return errors.ErrorUserForbidden
+	}
+	return nil
+}
+
+// GetOwnerQueryFromContext get query of resources owned by the current user, nil if not limited
+func GetOwnerQueryFromContext(c *gin.Context) (q bson.M) {
+	value, ok := c.Get(constants.ContextOwnerQuery)
+	if !ok {
+		return nil
+	}
+	q, ok = value.(bson.M)
+	if !ok {
+		return nil
+	}
+	return q
+}
+
+// WithOwnerQuery limit the query to resources owned by the current user
+func WithOwnerQuery(c *gin.Context, query bson.M) (q bson.M) {
+	ownerQuery := GetOwnerQueryFromContext(c)
+	if ownerQuery == nil {
+		return query
+	}
+	if len(query) == 0 {
+		return ownerQuery
+	}
+	return bson.M{
+		"$and": []bson.M{query, ownerQuery},
+	}
+}
+
+// FilterOwnedIds filter ids of resources of the controller to those owned by
+// the current user, as limited by the owner query in the context
+func FilterOwnedIds(c *gin.Context, id ControllerId, ids []primitive.ObjectID) (res []primitive.ObjectID, err error) {
+	if GetOwnerQueryFromContext(c) == nil {
+		return ids, nil
+	}
+	query := WithOwnerQuery(c, bson.M{
+		"_id": bson.M{
+			"$in": ids,
+		},
+	})
+	var docs []struct {
+		Id primitive.ObjectID `bson:"_id"`
+	}
+	if err := mongo.GetMongoCol(rbacOwnedColNames[id]).Find(query, nil).All(&docs); err != nil {
+		if err.Error() != mongo2.ErrNoDocuments.Error() {
+			return nil, err
+		}
+	}
+	for _, d := range docs {
+		res = append(res, d.Id)
+	}
+	return res, nil
+}
+
+// isAdmin whether the current user is an admin user
+func isAdmin(c *gin.Context) bool {
+	u := GetUserFromContext(c)
+	return u != nil && u.GetRole() == constants.RoleAdmin
+}
+
+func getPermissionOwnerType(c *gin.Context, id ControllerId, action string) (u interfaces.User, ownerType string, err error) {
+	u = GetUserFromContext(c)
+	if u == nil {
+		return nil, "", errors.ErrorUserForbidden
+	}
+	ownerType = GetPermissionOwnerType(u.GetRole(), id, action)
+	switch ownerType {
+	case constants.OwnerTypeAll, constants.OwnerTypeMe, constants.OwnerTypePublic:
+		return u, ownerType, nil
+	default:
+		return nil, "", errors.ErrorUserForbidden
+	}
+}
+
+func isResourceOwned(u interfaces.User, id ControllerId, resId primitive.ObjectID, ownerType string) (ok bool, err error) {
+	// user itself
+	if id == ControllerIdUser {
+		return resId == u.GetId(), nil
+	}
+
+	// created by user
+	total, err := mongo.GetMongoCol(rbacOwnedColNames[id]).Count(bson.M{
+		"_id":                resId,
+		constants.OwnerIdKey: u.GetId(),
+	})
+	if err != nil {
+		return false, err
+	}
+	if total > 0 {
+		return true, nil
+	}
+
+	// public
+	publicQuery, ok := rbacPublicQueries[id]
+	if ownerType != constants.OwnerTypePublic || !ok {
+		return false, nil
+	}
+	total, err = mongo.GetMongoCol(rbacOwnedColNames[id]).Count(bson.M{
+		"$and": []bson.M{{"_id": resId}, publicQuery},
+	})
+	if err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+func getOwnerQuery(u interfaces.User, id ControllerId, ownerType string) (q bson.M, err error) {
+	// user itself
+	if id == ControllerIdUser {
+		return bson.M{"_id": u.GetId()}, nil
+	}
+
+	// resources created by user
+	q = bson.M{constants.OwnerIdKey: u.GetId()}
+
+	// public
+	if publicQuery, ok := rbacPublicQueries[id]; ownerType == constants.OwnerTypePublic && ok {
+		q = bson.M{"$or": []bson.M{q, publicQuery}}
+	}
+
+	return q, nil
+}
@@ -1,14 +1,24 @@
 package controllers
 
 import (
+	"fmt"
+	"github.com/apex/log"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
 	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
 	"github.com/luke513009828/crawlab-core/result"
 	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/crawlab-team/go-trace"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/dig"
 	"net/http"
+	"time"
 )
 
 var ResultController ActionController
@@ -21,10 +31,36 @@ func getResultActions() []Action {
 			Path:        "/:id",
 			HandlerFunc: resultCtx.getList,
 		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/export",
+			HandlerFunc: resultCtx.export,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/exports",
+			HandlerFunc: resultCtx.getExportList,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/:id/exports",
+			HandlerFunc: resultCtx.putExport,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/exports/:export_id",
+			HandlerFunc: resultCtx.getExport,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/exports/:export_id/download",
+			HandlerFunc: resultCtx.downloadExport,
+		},
 	}
 }
 
 type resultContext struct {
+	modelSvc service.ModelService
 }
 
 func (ctx *resultContext) getList(c *gin.Context) {
@@ -35,6 +71,11 @@ func (ctx *resultContext) getList(c *gin.Context) {
 		return
 	}
 
+	// permission
+	if err := ctx._checkPermission(c, id); err != nil {
+		return
+	}
+
 	// service
 	svc, err := result.GetResultService(id)
 	if err != nil {
@@ -78,6 +119,284 @@ func (ctx *resultContext) getList(c *gin.Context) {
 	HandleSuccessWithListData(c, data, total)
 }
 
+// export stream results of the data collection (or of the task given by
+// "task_id") matching the filter conditions as a file in the format
+func (ctx *resultContext) export(c *gin.Context) {
+	// data collection
+	dc, err := ctx._getDataCollection(c)
+	if err != nil {
+		return
+	}
+
+	// format
+	format := c.Query("format")
+	if !ctx._isValidExportFormat(format) {
+		HandleErrorBadRequest(c, errors.ErrorResultInvalidExportFormat)
+		return
+	}
+
+	// query
+	query, err := ctx._getExportQuery(MustGetFilterQuery(c), c.Query("task_id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// stream
+	c.Header("Content-Type", ctx._getExportContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, dc.Name, format))
+	c.Status(http.StatusOK)
+	count, err := result.Export(c.Writer, dc.Name, query, format)
+	if err != nil {
+		if !c.Writer.Written() {
+			if err == errors.ErrorResultExportTooManyRows {
+				HandleErrorBadRequest(c, err)
+				return
+			}
+			HandleErrorInternalServerError(c, err)
+			return
+		}
+
+		// abort the response rather than end the file being streamed as if
+		// complete, which is then found incomplete by the client
+		trace.PrintError(err)
+		panic(http.ErrAbortHandler)
+	}
+	log.Infof("exported %d results of %s in %s", count, dc.Name, format)
+}
+
+func (ctx *resultContext) getExportList(c *gin.Context) {
+	// id
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// permission
+	if err := ctx._checkPermission(c, id); err != nil {
+		return
+	}
+
+	// pagination
+	p := MustGetPagination(c)
+
+	// list
+	query := bson.M{"col_id": id}
+	list, err := ctx.modelSvc.GetResultExportList(query, &mongo.FindOptions{
+		Skip:  (p.Page - 1) * p.Size,
+		Limit: p.Size,
+		Sort:  bson.D{{"_id", -1}},
+	})
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleSuccessWithListData(c, nil, 0)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// total
+	total, err := ctx.modelSvc.GetBaseService(interfaces.ModelIdResultExport).Count(query)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, list, total)
+}
+
+// putExport start a background export of results of the data collection (or
+// of the task) matching the filter conditions, of which the file can be
+// downloaded once it is finished
+func (ctx *resultContext) putExport(c *gin.Context) {
+	// data collection
+	dc, err := ctx._getDataCollection(c)
+	if err != nil {
+		return
+	}
+
+	// payload
+	var e models.ResultExport
+	if err := c.ShouldBindJSON(&e); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if !ctx._isValidExportFormat(e.Format) {
+		HandleErrorBadRequest(c, errors.ErrorResultInvalidExportFormat)
+		return
+	}
+
+	// query
+	var filterQuery bson.M
+	if e.Conditions != "" {
+		f, err := GetFilterFromConditions(e.Conditions)
+		if err != nil {
+			HandleErrorBadRequest(c, err)
+			return
+		}
+		filterQuery, err = FilterToQuery(f)
+		if err != nil {
+			HandleErrorBadRequest(c, err)
+			return
+		}
+	}
+	taskId := ""
+	if !e.TaskId.IsZero() {
+		taskId = e.TaskId.Hex()
+	}
+	query, err := ctx._getExportQuery(filterQuery, taskId)
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// add export
+	e.Id = primitive.NewObjectID()
+	e.ColId = dc.Id
+	e.Status = constants.ResultExportStatusPending
+	e.Error = ""
+	e.Count = 0
+	e.FileName = dc.Name + "." + e.Format
+	e.CreateTs = time.Now()
+	e.EndTs = time.Time{}
+	if err := delegate.NewModelDelegate(&e, GetUserFromContext(c)).Add(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// run in background
+	go func(e models.ResultExport) {
+		if err := result.RunExport(&e, query); err != nil {
+			trace.PrintError(err)
+		}
+	}(e)
+
+	HandleSuccessWithData(c, e)
+}
+
+func (ctx *resultContext) getExport(c *gin.Context) {
+	e, err := ctx._getExport(c)
+	if err != nil {
+		return
+	}
+	HandleSuccessWithData(c, e)
+}
+
+func (ctx *resultContext) downloadExport(c *gin.Context) {
+	e, err := ctx._getExport(c)
+	if err != nil {
+		return
+	}
+	if e.Status != constants.ResultExportStatusFinished {
+		HandleErrorBadRequest(c, errors.ErrorResultExportNotFinished)
+		return
+	}
+	c.FileAttachment(result.GetExportFilePath(e), e.FileName)
+}
+
+func (ctx *resultContext) _getDataCollection(c *gin.Context) (dc *models.DataCollection, err error) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return nil, err
+	}
+	if err := ctx._checkPermission(c, id); err != nil {
+		return nil, err
+	}
+	dc, err = ctx.modelSvc.GetDataCollectionById(id)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+		} else {
+			HandleErrorInternalServerError(c, err)
+		}
+		return nil, err
+	}
+	return dc, nil
+}
+
+func (ctx *resultContext) _getExport(c *gin.Context) (e *models.ResultExport, err error) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return nil, err
+	}
+	exportId, err := primitive.ObjectIDFromHex(c.Param("export_id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return nil, err
+	}
+	if err := ctx._checkPermission(c, id); err != nil {
+		return nil, err
+	}
+	e, err = ctx.modelSvc.GetResultExportById(exportId)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+		} else {
+			HandleErrorInternalServerError(c, err)
+		}
+		return nil, err
+	}
+	if e.ColId != id {
+		HandleErrorNotFound(c, errors.ErrorHttpNotFound)
+		return nil, errors.ErrorHttpNotFound
+	}
+	return e, nil
+}
+
+// _checkPermission check if the current user is permitted to access results
+// of the data collection, and respond with error otherwise
+func (ctx *resultContext) _checkPermission(c *gin.Context, id primitive.ObjectID) (err error) {
+	if err := CheckDataCollectionPermission(c, id); err != nil {
+		if err == errors.ErrorUserForbidden {
+			HandleErrorForbidden(c, err)
+		} else {
+			HandleErrorInternalServerError(c, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// _getExportQuery query of results to export, limited to the task if given
+func (ctx *resultContext) _getExportQuery(filterQuery bson.M, taskId string) (query bson.M, err error) {
+	query = bson.M{}
+	for k, v := range filterQuery {
+		query[k] = v
+	}
+	if taskId != "" {
+		tid, err := primitive.ObjectIDFromHex(taskId)
+		if err != nil {
+			return nil, err
+		}
+		query["_tid"] = tid
+	}
+	return query, nil
+}
+
+func (ctx *resultContext) _isValidExportFormat(format string) (ok bool) {
+	switch format {
+	case constants.ResultExportFormatCsv, constants.ResultExportFormatJsonl, constants.ResultExportFormatXlsx:
+		return true
+	default:
+		return false
+	}
+}
+
+func (ctx *resultContext) _getExportContentType(format string) (contentType string) {
+	switch format {
+	case constants.ResultExportFormatCsv:
+		return "text/csv"
+	case constants.ResultExportFormatXlsx:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
 func (ctx *resultContext) _getSvc(id primitive.ObjectID) (svc interfaces.ResultService, err error) {
 	return result.GetResultService(id)
 }
@@ -86,5 +405,16 @@ func newResultContext() *resultContext {
 	// context
 	ctx := &resultContext{}
 
+	// dependency injection
+	c := dig.New()
+	if err := c.Provide(service.GetService); err != nil {
+		panic(err)
+	}
+	if err := c.Invoke(func(modelSvc service.ModelService) {
+		ctx.modelSvc = modelSvc
+	}); err != nil {
+		panic(err)
+	}
+
 	return ctx
 }
@@ -85,7 +85,16 @@ func (ctr *scheduleController) DeleteList(c *gin.Context) {
 		HandleErrorBadRequest(c, err)
 		return
 	}
-	for _, id := range payload.Ids {
+	ids, err := FilterOwnedIds(c, ControllerIdSchedule, payload.Ids)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	if len(ids) == 0 {
+		HandleSuccess(c)
+		return
+	}
+	for _, id := range ids {
 		s, err := ctr.ctx.modelSvc.GetScheduleById(id)
 		if err != nil {
 			HandleErrorInternalServerError(c, err)
@@ -98,7 +107,7 @@ func (ctr *scheduleController) DeleteList(c *gin.Context) {
 	}
 	if err := ctr.ctx.modelSvc.GetBaseService(interfaces.ModelIdSchedule).DeleteList(bson.M{
 		"_id": bson.M{
-			"$in": payload.Ids,
+			"$in": ids,
 		},
 	}); err != nil {
 		HandleErrorInternalServerError(c, err)
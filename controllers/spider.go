@@ -2,7 +2,10 @@ package controllers
 
 import (
 	"bytes"
+	"encoding/json"
+	errors2 "errors"
 	"fmt"
+	"github.com/luke513009828/crawlab-core/config"
 	"github.com/luke513009828/crawlab-core/constants"
 	"github.com/luke513009828/crawlab-core/entity"
 	"github.com/luke513009828/crawlab-core/errors"
@@ -10,7 +13,11 @@ import (
 	delegate2 "github.com/luke513009828/crawlab-core/models/delegate"
 	"github.com/luke513009828/crawlab-core/models/models"
 	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/luke513009828/crawlab-core/schedule"
 	"github.com/luke513009828/crawlab-core/spider/admin"
+	"github.com/luke513009828/crawlab-core/spider/configspider"
+	"github.com/luke513009828/crawlab-core/spider/gitsync"
+	"github.com/luke513009828/crawlab-core/spider/scrapy"
 	"github.com/luke513009828/crawlab-core/spider/sync"
 	"github.com/luke513009828/crawlab-core/utils"
 	"github.com/crawlab-team/crawlab-db/mongo"
@@ -18,8 +25,8 @@ import (
 	"github.com/crawlab-team/go-trace"
 	"github.com/gin-gonic/gin"
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	mongo2 "go.mongodb.org/mongo-driver/mongo"
@@ -28,6 +35,7 @@ import (
 	"io/ioutil"
 	"math"
 	"net/http"
+	"os"
 	"path"
 	"strings"
 )
@@ -102,11 +110,141 @@ func getSpiderActions() []Action {
 			Path:        "/:id/git/commit",
 			HandlerFunc: spiderCtx.gitCommit,
 		},
-		//{
-		//	Method:      http.MethodPost,
-		//	Path:        "/:id/clone",
-		//	HandlerFunc: spiderCtx.clone,
-		//},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/git/sync-logs",
+			HandlerFunc: spiderCtx.getGitSyncLogList,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/git/logs",
+			HandlerFunc: spiderCtx.getGitLogList,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/git/logs/:hash",
+			HandlerFunc: spiderCtx.getGitLog,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/git/branches",
+			HandlerFunc: spiderCtx.getGitBranches,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/git/fetch",
+			HandlerFunc: spiderCtx.gitFetch,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/git/checkout",
+			HandlerFunc: spiderCtx.gitCheckout,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/git/reset",
+			HandlerFunc: spiderCtx.gitReset,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/git/ssh-key",
+			HandlerFunc: spiderCtx.getGitSshKey,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/git/ssh-key",
+			HandlerFunc: spiderCtx.saveGitSshKey,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/git/ssh-key/generate",
+			HandlerFunc: spiderCtx.generateGitSshKey,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/:id/git/ssh-key",
+			HandlerFunc: spiderCtx.deleteGitSshKey,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/versions",
+			HandlerFunc: spiderCtx.getVersionList,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/versions",
+			HandlerFunc: spiderCtx.commitVersion,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/versions/:version",
+			HandlerFunc: spiderCtx.getVersion,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/versions/:version/rollback",
+			HandlerFunc: spiderCtx.rollbackVersion,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/clone",
+			HandlerFunc: spiderCtx.clone,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/export",
+			HandlerFunc: spiderCtx.export,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/import",
+			HandlerFunc: spiderCtx._import,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/scrapy/project",
+			HandlerFunc: spiderCtx.createScrapyProject,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/scrapy/spiders",
+			HandlerFunc: spiderCtx.getScrapySpiders,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/scrapy/spiders",
+			HandlerFunc: spiderCtx.createScrapySpider,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/scrapy/settings",
+			HandlerFunc: spiderCtx.getScrapySettings,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/scrapy/settings",
+			HandlerFunc: spiderCtx.saveScrapySettings,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/scrapy/items",
+			HandlerFunc: spiderCtx.getScrapyItems,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/scrapy/items",
+			HandlerFunc: spiderCtx.saveScrapyItems,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/scrapy/pipelines",
+			HandlerFunc: spiderCtx.getScrapyPipelines,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/scrapy/pipelines",
+			HandlerFunc: spiderCtx.saveScrapyPipelines,
+		},
 	}
 }
 
@@ -125,6 +263,7 @@ func (ctr *spiderController) Put(c *gin.Context) {
 	if err != nil {
 		return
 	}
+	maskSpiderSecrets(s)
 	HandleSuccessWithData(c, s)
 }
 
@@ -133,23 +272,123 @@ func (ctr *spiderController) Post(c *gin.Context) {
 	if err != nil {
 		return
 	}
+	maskSpiderSecrets(s)
 	HandleSuccessWithData(c, s)
 }
 
+func (ctr *spiderController) Delete(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	var opts interfaces.SpiderDeleteOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if err := ctr.ctx._delete(id, &opts, GetUserFromContext(c)); err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	HandleSuccess(c)
+}
+
+func (ctr *spiderController) DeleteList(c *gin.Context) {
+	payload, err := NewJsonBinder(interfaces.ModelIdSpider).BindBatchRequestPayload(c)
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	var opts interfaces.SpiderDeleteOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	ids, err := FilterOwnedIds(c, ControllerIdSpider, payload.Ids)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	for _, id := range ids {
+		if err := ctr.ctx._delete(id, &opts, GetUserFromContext(c)); err != nil {
+			HandleErrorInternalServerError(c, err)
+			return
+		}
+	}
+	HandleSuccess(c)
+}
+
 func (ctr *spiderController) GetList(c *gin.Context) {
 	withStats := c.Query("stats")
 	if withStats == "" {
-		ctr.d.GetList(c)
+		ctr.ctx._getList(c)
 		return
 	}
 	ctr.ctx._getListWithStats(c)
 }
 
+func (ctr *spiderController) PostList(c *gin.Context) {
+	// payload
+	var payload entity.BatchRequestPayloadWithStringData
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// doc to update
+	var doc models.Spider
+	if err := json.Unmarshal([]byte(payload.Data), &doc); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// masked secrets are unchanged, while masked envs cannot be restored
+	// for multiple spiders
+	var fields []string
+	for _, field := range payload.Fields {
+		if field == "git_password" && doc.GitPassword == constants.SecretMask {
+			continue
+		}
+		if field == "envs" && utils.HasMaskedSecretEnvs(doc.Envs) {
+			HandleErrorBadRequest(c, errors.ErrorHttpBadRequest)
+			return
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		HandleSuccess(c)
+		return
+	}
+
+	// query
+	query := WithOwnerQuery(c, bson.M{
+		"_id": bson.M{
+			"$in": payload.Ids,
+		},
+	})
+
+	// update spiders
+	if err := ctr.ctx.modelSpiderSvc.UpdateDoc(query, &doc, fields); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
 type spiderContext struct {
-	modelSvc       service.ModelService
-	modelSpiderSvc interfaces.ModelBaseService
-	syncSvc        interfaces.SpiderSyncService
-	adminSvc       interfaces.SpiderAdminService
+	modelSvc        service.ModelService
+	modelSpiderSvc  interfaces.ModelBaseService
+	syncSvc         interfaces.SpiderSyncService
+	adminSvc        interfaces.SpiderAdminService
+	gitSyncSvc      interfaces.SpiderGitSyncService
+	configSpiderSvc interfaces.ConfigSpiderService
+	scheduleSvc     interfaces.ScheduleService
 }
 
 func (ctx *spiderContext) listDir(c *gin.Context) {
@@ -201,13 +440,30 @@ func (ctx *spiderContext) getFileInfo(c *gin.Context) {
 }
 
 func (ctx *spiderContext) saveFile(c *gin.Context) {
-	_, payload, fsSvc, err := ctx._processFileRequest(c, http.MethodPost)
+	id, payload, fsSvc, err := ctx._processFileRequest(c, http.MethodPost)
 	if err != nil {
 		return
 	}
 
 	data := utils.FillEmptyFileData([]byte(payload.Data))
 
+	// regenerate scrapy project files of configurable spider from Spiderfile
+	if strings.TrimPrefix(payload.Path, "/") == constants.Spiderfile {
+		s, err := ctx.modelSvc.GetSpiderById(id)
+		if err != nil {
+			HandleErrorInternalServerError(c, err)
+			return
+		}
+		if s.Type == constants.Configurable {
+			if err := ctx.configSpiderSvc.SaveSpiderfile(id, data); err != nil {
+				ctx._handleSpiderfileError(c, err)
+				return
+			}
+			HandleSuccess(c)
+			return
+		}
+	}
+
 	if err := fsSvc.Save(payload.Path, data); err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
@@ -234,7 +490,7 @@ func (ctx *spiderContext) saveDir(c *gin.Context) {
 }
 
 func (ctx *spiderContext) renameFile(c *gin.Context) {
-	_, payload, fsSvc, err := ctx._processFileRequest(c, http.MethodPost)
+	id, payload, fsSvc, err := ctx._processFileRequest(c, http.MethodPost)
 	if err != nil {
 		return
 	}
@@ -244,6 +500,14 @@ func (ctx *spiderContext) renameFile(c *gin.Context) {
 		return
 	}
 
+	// regenerate scrapy project files of configurable spider from Spiderfile
+	if strings.TrimPrefix(payload.NewPath, "/") == constants.Spiderfile {
+		if err := ctx.configSpiderSvc.Regenerate(id); err != nil {
+			ctx._handleSpiderfileError(c, err)
+			return
+		}
+	}
+
 	HandleSuccess(c)
 }
 
@@ -296,13 +560,113 @@ func (ctx *spiderContext) run(c *gin.Context) {
 
 	// schedule
 	if err := ctx.adminSvc.Schedule(id, &opts); err != nil {
-		HandleErrorInternalServerError(c, err)
+		ctx._handleVersionError(c, err)
 		return
 	}
 
 	HandleSuccess(c)
 }
 
+func (ctx *spiderContext) clone(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// options
+	var opts interfaces.SpiderCloneOptions
+	if err := c.ShouldBindJSON(&opts); err != nil && err != io.EOF {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// clone
+	cloneId, err := ctx.adminSvc.Clone(id, &opts, GetUserFromContext(c))
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// clone spider
+	s, err := ctx.modelSvc.GetSpiderById(cloneId)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, s)
+}
+
+func (ctx *spiderContext) export(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// spider
+	s, err := ctx.modelSvc.GetSpiderById(id)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// export
+	filePath, err := ctx.adminSvc.Export(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	defer os.Remove(filePath)
+
+	c.FileAttachment(filePath, fmt.Sprintf("%s.zip", s.Name))
+}
+
+func (ctx *spiderContext) _import(c *gin.Context) {
+	// archive
+	fh, err := c.FormFile("file")
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	f, err := ioutil.TempFile("", "crawlab-spider-*.zip")
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	_ = f.Close()
+	defer os.Remove(f.Name())
+	if err := c.SaveUploadedFile(fh, f.Name()); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// import
+	id, err := ctx.adminSvc.Import(f.Name(), GetUserFromContext(c))
+	if err != nil {
+		if err == errors.ErrorSpiderInvalidArchive {
+			HandleErrorBadRequest(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// imported spider
+	s, err := ctx.modelSvc.GetSpiderById(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, s)
+}
+
 func (ctx *spiderContext) getGit(c *gin.Context) {
 	// spider id
 	id, err := ctx._processActionRequest(c)
@@ -317,6 +681,11 @@ func (ctx *spiderContext) getGit(c *gin.Context) {
 		return
 	}
 
+	// one sync, commit or run of the spider at a time
+	mu := ctx.syncSvc.GetLock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
 	// sync from remote to workspace
 	if err := fsSvc.GetFsService().SyncToWorkspace(); err != nil {
 		HandleErrorInternalServerError(c, err)
@@ -331,7 +700,7 @@ func (ctx *spiderContext) getGit(c *gin.Context) {
 	}
 
 	// current branch
-	currentBranch, err := ctx._getCurrentBranch(gitClient)
+	currentBranch, err := ctx._getCurrentBranch(id, gitClient)
 	if err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
@@ -394,23 +763,755 @@ func (ctx *spiderContext) getGit(c *gin.Context) {
 		"ignore":         ignore,
 		"git":            _git,
 	}
+	if _git != nil {
+		maskGitPassword(_git)
+	}
+
+	HandleSuccessWithData(c, res)
+}
+
+func (ctx *spiderContext) getGitRemoteRefs(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// remote name
+	remoteName := c.Query("remote")
+	if remoteName == "" {
+		remoteName = vcs.GitRemoteNameUpstream
+	}
+
+	// spider fs service
+	fsSvc, err := ctx.syncSvc.GetFsService(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// sync from remote to workspace
+	//if err := fsSvc.GetFsService().SyncToWorkspace(); err != nil {
+	//	HandleErrorInternalServerError(c, err)
+	//	return
+	//}
+
+	// git client
+	gitClient, err := ctx._getGitClient(id, fsSvc)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// refs, of which the upstream remote is with auth of git settings
+	var refs []vcs.GitRef
+	if remoteName == vcs.GitRemoteNameUpstream {
+		refs, err = ctx.gitSyncSvc.GetRemoteRefs(id)
+	} else {
+		refs, err = gitClient.GetRemoteRefs(remoteName)
+	}
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, refs)
+}
+
+func (ctx *spiderContext) gitPull(c *gin.Context) {
+	// payload
+	var payload entity.GitPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// pull with target branch
+	opts := &interfaces.SpiderGitSyncOptions{
+		Branch:  payload.Branch,
+		Trigger: constants.GitSyncTriggerManual,
+	}
+	if _, err := ctx.gitSyncSvc.Sync(id, opts, GetUserFromContext(c)); err != nil {
+		if err == errors.ErrorSpiderGitNotFound {
+			HandleErrorBadRequest(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+func (ctx *spiderContext) getGitSyncLogList(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// pagination
+	p, err := GetPagination(c)
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// query
+	query := bson.M{
+		"spider_id": id,
+	}
+
+	// list
+	logs, err := ctx.modelSvc.GetGitSyncLogList(query, &mongo.FindOptions{
+		Skip:  (p.Page - 1) * p.Size,
+		Limit: p.Size,
+		Sort:  bson.D{{"_id", -1}},
+	})
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			HandleSuccessWithListData(c, nil, 0)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// total
+	total, err := ctx.modelSvc.GetBaseService(interfaces.ModelIdGitSyncLog).Count(query)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, logs, total)
+}
+
+func (ctx *spiderContext) getGitLogList(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// spider fs service
+	fsSvc, err := ctx.syncSvc.GetFsService(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// head
+	head, err := fsSvc.GetVersion()
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	if head == "" {
+		HandleSuccessWithListData(c, nil, 0)
+		return
+	}
+
+	// commits from the ref, by default head
+	repo := fsSvc.GetFsService().GetGitClient().GetRepository()
+	from := plumbing.NewHash(head)
+	if ref := c.Query("ref"); ref != "" {
+		h, err := repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			HandleErrorNotFound(c, errors.ErrorSpiderGitRefNotFound)
+			return
+		}
+		from = *h
+	}
+	iter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// refs
+	refsMap, err := ctx._getGitRefsMap(repo)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// commits of the page with changes
+	pagination := MustGetPagination(c)
+	skip := pagination.Size * (pagination.Page - 1)
+	var commits []entity.GitCommit
+	total := 0
+	if err := iter.ForEach(func(commit *object.Commit) error {
+		total++
+		if total <= skip || total > skip+pagination.Size {
+			return nil
+		}
+		v, err := ctx._getVersion(commit, head, false)
+		if err != nil {
+			return err
+		}
+		commits = append(commits, entity.GitCommit{
+			SpiderVersion: v,
+			Refs:          refsMap[v.Hash],
+		})
+		return nil
+	}); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, commits, total)
+}
+
+func (ctx *spiderContext) getGitLog(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// spider fs service
+	fsSvc, err := ctx.syncSvc.GetFsService(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// commit
+	repo := fsSvc.GetFsService().GetGitClient().GetRepository()
+	h, err := gitsync.ResolveRevision(repo, &interfaces.SpiderGitRefOptions{Commit: c.Param("hash")})
+	if err != nil {
+		ctx._handleVersionError(c, err)
+		return
+	}
+	commit, err := repo.CommitObject(h)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// head
+	head, err := fsSvc.GetVersion()
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// refs
+	refsMap, err := ctx._getGitRefsMap(repo)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// commit with diff
+	v, err := ctx._getVersion(commit, head, true)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, entity.GitCommit{
+		SpiderVersion: v,
+		Refs:          refsMap[v.Hash],
+	})
+}
+
+func (ctx *spiderContext) getGitBranches(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// spider fs service
+	fsSvc, err := ctx.syncSvc.GetFsService(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// head
+	var res entity.GitBranches
+	res.Head, err = fsSvc.GetVersion()
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// current branch
+	repo := fsSvc.GetFsService().GetGitClient().GetRepository()
+	if ref, err := repo.Head(); err == nil && ref.Name().IsBranch() {
+		res.Current = ref.Name().Short()
+	}
+
+	// local and remote-tracking branches
+	iter, err := repo.References()
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	if err := iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		b := vcs.GitRef{
+			Type:     vcs.GitRefTypeBranch,
+			Name:     ref.Name().Short(),
+			FullName: ref.Name().String(),
+			Hash:     ref.Hash().String(),
+		}
+		if commit, err := repo.CommitObject(ref.Hash()); err == nil {
+			b.Timestamp = commit.Author.When
+		}
+		if ref.Name().IsBranch() {
+			res.Local = append(res.Local, b)
+		} else if strings.HasPrefix(ref.Name().String(), "refs/remotes/"+constants.GitRemoteNameUpstream+"/") {
+			res.Remote = append(res.Remote, b)
+		}
+		return nil
+	}); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, res)
+}
+
+func (ctx *spiderContext) gitFetch(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// fetch from upstream remote
+	if err := ctx.gitSyncSvc.Fetch(id); err != nil {
+		if err == errors.ErrorSpiderGitNotFound {
+			HandleErrorBadRequest(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+func (ctx *spiderContext) gitCheckout(c *gin.Context) {
+	// payload
+	var payload entity.GitPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// checkout
+	if err := ctx.gitSyncSvc.Checkout(id, ctx._getGitRefOptions(&payload), GetUserFromContext(c)); err != nil {
+		ctx._handleVersionError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+func (ctx *spiderContext) gitReset(c *gin.Context) {
+	// payload
+	var payload entity.GitPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// hard reset of the current branch and workspace
+	if err := ctx.gitSyncSvc.Reset(id, ctx._getGitRefOptions(&payload)); err != nil {
+		ctx._handleVersionError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+func (ctx *spiderContext) getGitSshKey(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// ssh key with public key only
+	k, err := ctx.modelSvc.GetGitSshKeyById(id)
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			HandleErrorNotFound(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, k)
+}
+
+func (ctx *spiderContext) saveGitSshKey(c *gin.Context) {
+	// payload
+	var payload entity.GitSshKeyPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// save uploaded private key
+	if err := ctx.gitSyncSvc.SaveSshKey(id, payload.PrivateKey, GetUserFromContext(c)); err != nil {
+		if err == errors.ErrorGitInvalidSshKey {
+			HandleErrorBadRequest(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	ctx._returnGitSshKey(c, id)
+}
+
+func (ctx *spiderContext) generateGitSshKey(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// generate key pair
+	if err := ctx.gitSyncSvc.GenerateSshKey(id, GetUserFromContext(c)); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	ctx._returnGitSshKey(c, id)
+}
+
+func (ctx *spiderContext) deleteGitSshKey(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// delete
+	if err := ctx.modelSvc.GetBaseService(interfaces.ModelIdGitSshKey).DeleteList(bson.M{"_id": id}, GetUserFromContext(c)); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+func (ctx *spiderContext) createScrapyProject(c *gin.Context) {
+	// payload
+	var payload entity.ScrapyPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// spider fs service
+	fsSvc, err := ctx.syncSvc.GetFsService(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// scaffold
+	if err := scrapy.CreateProject(fsSvc, payload.Name); err != nil {
+		ctx._handleScrapyError(c, err)
+		return
+	}
+
+	// mark as scrapy spider
+	if err := ctx.modelSpiderSvc.UpdateById(id, bson.M{"is_scrapy": true}, GetUserFromContext(c)); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+func (ctx *spiderContext) getScrapySpiders(c *gin.Context) {
+	_, p, err := ctx._getScrapyProject(c)
+	if err != nil {
+		return
+	}
+
+	spiders, err := p.GetSpiders()
+	if err != nil {
+		ctx._handleScrapyError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, spiders)
+}
+
+func (ctx *spiderContext) createScrapySpider(c *gin.Context) {
+	// payload
+	var payload entity.ScrapyPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// scrapy project
+	id, p, err := ctx._getScrapyProject(c)
+	if err != nil {
+		return
+	}
+
+	// scaffold
+	if err := p.CreateSpider(payload.Name, payload.Domain, payload.Template); err != nil {
+		ctx._handleScrapyError(c, err)
+		return
+	}
+
+	// update spider names
+	spiders, err := p.GetSpiders()
+	if err != nil {
+		ctx._handleScrapyError(c, err)
+		return
+	}
+	spiderNames := []string{}
+	for _, s := range spiders {
+		spiderNames = append(spiderNames, s.Name)
+	}
+	if err := ctx.modelSpiderSvc.UpdateById(id, bson.M{"is_scrapy": true, "spider_names": spiderNames}, GetUserFromContext(c)); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, spiders)
+}
+
+func (ctx *spiderContext) getScrapySettings(c *gin.Context) {
+	_, p, err := ctx._getScrapyProject(c)
+	if err != nil {
+		return
+	}
+
+	settings, err := p.GetSettings()
+	if err != nil {
+		ctx._handleScrapyError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, settings)
+}
+
+func (ctx *spiderContext) saveScrapySettings(c *gin.Context) {
+	// payload
+	var payload []entity.ScrapySettingParam
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// scrapy project
+	_, p, err := ctx._getScrapyProject(c)
+	if err != nil {
+		return
+	}
+
+	// save
+	if err := p.SaveSettings(payload); err != nil {
+		ctx._handleScrapyError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+func (ctx *spiderContext) getScrapyItems(c *gin.Context) {
+	_, p, err := ctx._getScrapyProject(c)
+	if err != nil {
+		return
+	}
+
+	items, err := p.GetItems()
+	if err != nil {
+		ctx._handleScrapyError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, items)
+}
+
+func (ctx *spiderContext) saveScrapyItems(c *gin.Context) {
+	// payload
+	var payload []entity.ScrapyItem
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// scrapy project
+	_, p, err := ctx._getScrapyProject(c)
+	if err != nil {
+		return
+	}
+
+	// save
+	if err := p.SaveItems(payload); err != nil {
+		ctx._handleScrapyError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+func (ctx *spiderContext) getScrapyPipelines(c *gin.Context) {
+	_, p, err := ctx._getScrapyProject(c)
+	if err != nil {
+		return
+	}
+
+	pipelines, err := p.GetPipelines()
+	if err != nil {
+		ctx._handleScrapyError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, pipelines)
+}
+
+func (ctx *spiderContext) saveScrapyPipelines(c *gin.Context) {
+	// payload
+	var payload []entity.ScrapyPipeline
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// scrapy project
+	_, p, err := ctx._getScrapyProject(c)
+	if err != nil {
+		return
+	}
+
+	// save
+	if err := p.SavePipelines(payload); err != nil {
+		ctx._handleScrapyError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+func (ctx *spiderContext) gitCommit(c *gin.Context) {
+	// payload
+	var payload entity.GitPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// spider id
+	id, err := ctx._processActionRequest(c)
+	if err != nil {
+		return
+	}
+
+	// spider fs service
+	fsSvc, err := ctx.syncSvc.GetFsService(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// one sync, commit or run of the spider at a time
+	mu := ctx.syncSvc.GetLock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// sync from remote to workspace
+	if err := fsSvc.GetFsService().SyncToWorkspace(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// git client
+	gitClient, err := ctx._getGitClient(id, fsSvc)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// add
+	for _, p := range payload.Paths {
+		if err := gitClient.Add(p); err != nil {
+			HandleErrorInternalServerError(c, err)
+			return
+		}
+	}
+
+	// commit
+	if err := gitClient.Commit(payload.CommitMessage); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// auth
+	auth, err := ctx.gitSyncSvc.GetAuth(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
 
-	HandleSuccessWithData(c, res)
+	// push
+	if err := gitClient.Push(
+		vcs.WithRemoteNamePush(vcs.GitRemoteNameUpstream),
+		vcs.WithAuthPush(auth),
+	); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
 }
 
-func (ctx *spiderContext) getGitRemoteRefs(c *gin.Context) {
+func (ctx *spiderContext) getVersionList(c *gin.Context) {
 	// spider id
 	id, err := ctx._processActionRequest(c)
 	if err != nil {
 		return
 	}
 
-	// remote name
-	remoteName := c.Query("remote")
-	if remoteName == "" {
-		remoteName = vcs.GitRemoteNameUpstream
-	}
-
 	// spider fs service
 	fsSvc, err := ctx.syncSvc.GetFsService(id)
 	if err != nil {
@@ -418,36 +1519,61 @@ func (ctx *spiderContext) getGitRemoteRefs(c *gin.Context) {
 		return
 	}
 
-	// sync from remote to workspace
-	//if err := fsSvc.GetFsService().SyncToWorkspace(); err != nil {
-	//	HandleErrorInternalServerError(c, err)
-	//	return
-	//}
-
-	// git client
-	gitClient, err := ctx._getGitClient(id, fsSvc)
+	// latest version
+	latest, err := fsSvc.GetVersion()
 	if err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
+	if latest == "" {
+		HandleSuccessWithListData(c, nil, 0)
+		return
+	}
 
-	// refs
-	refs, err := gitClient.GetRemoteRefs(remoteName)
+	// versions from the latest one
+	iter, err := fsSvc.GetFsService().GetGitClient().GetRepository().Log(&git.LogOptions{
+		From: plumbing.NewHash(latest),
+	})
 	if err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
 
-	HandleSuccessWithData(c, refs)
+	// versions of the page with changes
+	pagination := MustGetPagination(c)
+	skip := pagination.Size * (pagination.Page - 1)
+	var versions []entity.SpiderVersion
+	total := 0
+	if err := iter.ForEach(func(commit *object.Commit) error {
+		total++
+		if total <= skip || total > skip+pagination.Size {
+			return nil
+		}
+		v, err := ctx._getVersion(commit, latest, false)
+		if err != nil {
+			return err
+		}
+		versions = append(versions, v)
+		return nil
+	}); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, versions, total)
 }
 
-func (ctx *spiderContext) gitPull(c *gin.Context) {
+func (ctx *spiderContext) commitVersion(c *gin.Context) {
 	// payload
 	var payload entity.GitPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		HandleErrorBadRequest(c, err)
 		return
 	}
+	if payload.CommitMessage == "" {
+		HandleErrorBadRequest(c, errors.ErrorControllerMissingRequestFields)
+		return
+	}
 
 	// spider id
 	id, err := ctx._processActionRequest(c)
@@ -462,86 +1588,67 @@ func (ctx *spiderContext) gitPull(c *gin.Context) {
 		return
 	}
 
-	// git
-	g, err := ctx.modelSvc.GetGitById(id)
-	if err != nil {
+	// commit current files as a new version, one commit or run of the spider at a time
+	mu := ctx.syncSvc.GetLock(id)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := fsSvc.Commit(payload.CommitMessage); err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
 
-	// git client
-	gitClient, err := ctx._getGitClient(id, fsSvc)
+	// new version
+	version, err := fsSvc.GetVersion()
 	if err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
 
-	// set remote
-	r, err := gitClient.GetRemote(constants.GitRemoteNameUpstream)
+	HandleSuccessWithData(c, version)
+}
+
+func (ctx *spiderContext) getVersion(c *gin.Context) {
+	// spider id
+	id, err := ctx._processActionRequest(c)
 	if err != nil {
-		if err == git.ErrRemoteNotFound {
-			// create upstream remote if not exists
-			r, err = ctx._createGitRemote(gitClient, g)
-			if err != nil {
-				HandleErrorInternalServerError(c, err)
-				return
-			}
-		} else {
-			// error
-			HandleErrorInternalServerError(c, err)
-			return
-		}
-	} else {
-		// re-create upstream remote if remote urls not matched
-		if len(r.Config().URLs) == 0 || r.Config().URLs[0] != g.Url {
-			// delete existing upstream remote
-			if err := gitClient.DeleteRemote(constants.GitRemoteNameUpstream); err != nil {
-				HandleErrorInternalServerError(c, err)
-				return
-			}
+		return
+	}
 
-			// create upstream remote if not exists
-			r, err = ctx._createGitRemote(gitClient, g)
-			if err != nil {
-				HandleErrorInternalServerError(c, err)
-				return
-			}
-		}
+	// spider fs service
+	fsSvc, err := ctx.syncSvc.GetFsService(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
 	}
 
-	// branch to pull
-	var branch string
-	if payload.Branch == "" {
-		// by default current branch
-		branch, err = gitClient.GetCurrentBranch()
-		if err != nil {
-			HandleErrorInternalServerError(c, err)
-			return
-		}
-	} else {
-		// payload branch
-		branch = payload.Branch
+	// version
+	commit, err := fsSvc.GetVersionCommit(c.Param("version"))
+	if err != nil {
+		ctx._handleVersionError(c, err)
+		return
 	}
 
-	// attempt to pull with target branch
-	if err := ctx._gitPull(gitClient, constants.GitRemoteNameUpstream, branch); err != nil {
+	// latest version
+	latest, err := fsSvc.GetVersion()
+	if err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
 
-	// sync to fs
-	if err := fsSvc.GetFsService().SyncToFs(interfaces.WithOnlyFromWorkspace()); err != nil {
+	// version with diff
+	v, err := ctx._getVersion(commit, latest, true)
+	if err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
 
-	HandleSuccess(c)
+	HandleSuccessWithData(c, v)
 }
 
-func (ctx *spiderContext) gitCommit(c *gin.Context) {
+func (ctx *spiderContext) rollbackVersion(c *gin.Context) {
 	// payload
 	var payload entity.GitPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
+	if err := c.ShouldBindJSON(&payload); err != nil && err != io.EOF {
 		HandleErrorBadRequest(c, err)
 		return
 	}
@@ -559,42 +1666,36 @@ func (ctx *spiderContext) gitCommit(c *gin.Context) {
 		return
 	}
 
-	// sync from remote to workspace
-	if err := fsSvc.GetFsService().SyncToWorkspace(); err != nil {
-		HandleErrorInternalServerError(c, err)
-		return
-	}
-
-	// git client
-	gitClient, err := ctx._getGitClient(id, fsSvc)
+	// version
+	commit, err := fsSvc.GetVersionCommit(c.Param("version"))
 	if err != nil {
-		HandleErrorInternalServerError(c, err)
+		ctx._handleVersionError(c, err)
 		return
 	}
 
-	// add
-	for _, p := range payload.Paths {
-		if err := gitClient.Add(p); err != nil {
-			HandleErrorInternalServerError(c, err)
-			return
-		}
+	// commit message
+	msg := payload.CommitMessage
+	if msg == "" {
+		msg = fmt.Sprintf("Rollback to %s", commit.Hash.String()[:7])
 	}
 
-	// commit
-	if err := gitClient.Commit(payload.CommitMessage); err != nil {
+	// rollback, one commit or run of the spider at a time
+	mu := ctx.syncSvc.GetLock(id)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := fsSvc.Rollback(commit.Hash.String(), msg); err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
 
-	// push
-	if err := gitClient.Push(
-		vcs.WithRemoteNamePush(vcs.GitRemoteNameUpstream),
-	); err != nil {
+	// new version
+	version, err := fsSvc.GetVersion()
+	if err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
 
-	HandleSuccess(c)
+	HandleSuccessWithData(c, version)
 }
 
 func (ctx *spiderContext) _get(c *gin.Context) {
@@ -633,9 +1734,42 @@ func (ctx *spiderContext) _get(c *gin.Context) {
 		}
 	}
 
+	maskSpiderSecrets(s)
 	HandleSuccessWithData(c, s)
 }
 
+func (ctx *spiderContext) _getList(c *gin.Context) {
+	// params
+	query := MustGetFilterQuery(c)
+	opts := &mongo.FindOptions{
+		Sort: MustGetSortOption(c),
+	}
+	if !MustGetFilterAll(c) {
+		pagination := MustGetPagination(c)
+		opts.Skip = pagination.Size * (pagination.Page - 1)
+		opts.Limit = pagination.Size
+	}
+
+	// spiders
+	list, err := ctx.modelSvc.GetSpiderList(query, opts)
+	if err != nil && err.Error() != mongo2.ErrNoDocuments.Error() {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	for i := range list {
+		maskSpiderSecrets(&list[i])
+	}
+
+	// total count
+	total, err := ctx.modelSpiderSvc.Count(query)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, list, total)
+}
+
 func (ctx *spiderContext) _post(c *gin.Context) (s *models.Spider, err error) {
 	// bind
 	s = &models.Spider{}
@@ -644,6 +1778,12 @@ func (ctx *spiderContext) _post(c *gin.Context) (s *models.Spider, err error) {
 		return nil, err
 	}
 
+	// masked secrets are unchanged
+	if err := ctx._restoreSpiderSecrets(s); err != nil {
+		HandleErrorNotFound(c, err)
+		return nil, err
+	}
+
 	// upsert data collection
 	if err := ctx._upsertDataCollection(c, s); err != nil {
 		HandleErrorInternalServerError(c, err)
@@ -688,6 +1828,14 @@ func (ctx *spiderContext) _put(c *gin.Context) (s *models.Spider, err error) {
 		return nil, err
 	}
 
+	// generate scrapy project files of configurable spider
+	if s.Type == constants.Configurable {
+		if err := ctx.configSpiderSvc.InitSpiderfile(s.Id); err != nil {
+			HandleErrorInternalServerError(c, err)
+			return nil, err
+		}
+	}
+
 	return s, nil
 }
 
@@ -805,6 +1953,9 @@ func (ctx *spiderContext) _getListWithStats(c *gin.Context) {
 	for _, d := range list.Values() {
 		s := d.(*models.Spider)
 
+		// secrets
+		maskSpiderSecrets(s)
+
 		// spider stat
 		st, ok := dict[s.GetId()]
 		if ok {
@@ -825,6 +1976,22 @@ func (ctx *spiderContext) _getListWithStats(c *gin.Context) {
 	HandleSuccessWithListData(c, data, total)
 }
 
+// _restoreSpiderSecrets keep secrets of the spider unchanged if they are masked
+func (ctx *spiderContext) _restoreSpiderSecrets(s *models.Spider) (err error) {
+	if s.GitPassword != constants.SecretMask && !utils.HasMaskedSecretEnvs(s.Envs) {
+		return nil
+	}
+	sDb, err := ctx.modelSvc.GetSpiderById(s.Id)
+	if err != nil {
+		return err
+	}
+	if s.GitPassword == constants.SecretMask {
+		s.GitPassword = sDb.GitPassword
+	}
+	s.Envs = utils.RestoreSecretEnvs(s.Envs, sDb.Envs)
+	return nil
+}
+
 func (ctx *spiderContext) _processFileRequest(c *gin.Context, method string) (id primitive.ObjectID, payload entity.FileRequestPayload, fsSvc interfaces.SpiderFsService, err error) {
 	// id
 	id, err = primitive.ObjectIDFromHex(c.Param("id"))
@@ -943,87 +2110,25 @@ func (ctx *spiderContext) _getGitIgnore(fsSvc interfaces.SpiderFsService) (ignor
 	return ignore, nil
 }
 
-func (ctx *spiderContext) _createGitRemote(gitClient *vcs.GitClient, g *models.Git) (r *git.Remote, err error) {
-	r, err = gitClient.CreateRemote(&config.RemoteConfig{
-		Name: constants.GitRemoteNameUpstream,
-		URLs: []string{g.Url},
-	})
+func (ctx *spiderContext) _returnGitSshKey(c *gin.Context, id primitive.ObjectID) {
+	k, err := ctx.modelSvc.GetGitSshKeyById(id)
 	if err != nil {
-		return nil, trace.TraceError(err)
-	}
-	return r, nil
-}
-
-func (ctx *spiderContext) _gitPull(gitClient *vcs.GitClient, remote, branch string) (err error) {
-	// remote refs
-	//remoteRefs, err := gitClient.GetRemoteRefs(remote)
-	//if err != nil {
-	//	return err
-	//}
-
-	// ref
-	//var ref *plumbing.Reference
-	//for _, remoteRef := range remoteRefs {
-	//	if remoteRef.Type == vcs.GitRefTypeBranch && remoteRef.Name == branch {
-	//		ref = plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), plumbing.NewHash(remoteRef.Hash))
-	//		break
-	//	}
-	//}
-
-	// reset
-	_ = gitClient.Reset()
-
-	// checkout to target branch
-	//_ = gitClient.CheckoutBranchWithRemoteFromRef(branch, remote, ref)
-
-	// pull
-	if err := gitClient.Pull(
-		vcs.WithRemoteNamePull(remote),
-		vcs.WithBranchNamePull(branch),
-	); err != nil {
-		return trace.TraceError(err)
+		HandleErrorInternalServerError(c, err)
+		return
 	}
-
-	// reset
-	_ = gitClient.Reset()
-
-	return nil
+	HandleSuccessWithData(c, k)
 }
 
 func (ctx *spiderContext) _getGitClient(id primitive.ObjectID, fsSvc interfaces.SpiderFsService) (gitClient *vcs.GitClient, err error) {
-	// auth type
-	authType := ""
-
-	// git
-	g, err := ctx.modelSvc.GetGitById(id)
-	if err != nil {
-		if err != mongo2.ErrNoDocuments {
-			return nil, trace.TraceError(err)
-		}
-	} else {
-		authType = g.AuthType
-	}
+	return ctx.gitSyncSvc.GetGitClient(id, fsSvc)
+}
 
-	// git client
-	gitClient = fsSvc.GetFsService().GetGitClient()
-
-	switch authType {
-	case constants.GitAuthTypeHttp:
-		gitClient.SetAuthType(vcs.GitAuthTypeHTTP)
-		gitClient.SetUsername(g.Username)
-		gitClient.SetPassword(g.Password)
-	case constants.GitAuthTypeSsh:
-		gitClient.SetAuthType(vcs.GitAuthTypeSSH)
-		gitClient.SetUsername(g.Username)
-		gitClient.SetPrivateKey(g.Password)
-	default:
-		return gitClient, nil
+func (ctx *spiderContext) _getCurrentBranch(id primitive.ObjectID, gitClient *vcs.GitClient) (currentBranch string, err error) {
+	// empty if head is detached after checking out a tag or commit
+	if ref, err := gitClient.GetRepository().Head(); err == nil && !ref.Name().IsBranch() {
+		return "", nil
 	}
 
-	return gitClient, nil
-}
-
-func (ctx *spiderContext) _getCurrentBranch(gitClient *vcs.GitClient) (currentBranch string, err error) {
 	// current branch from repo
 	currentBranch, err = gitClient.GetCurrentBranch()
 	if err != nil {
@@ -1031,7 +2136,7 @@ func (ctx *spiderContext) _getCurrentBranch(gitClient *vcs.GitClient) (currentBr
 	}
 
 	// remote refs
-	remoteRefs, err := gitClient.GetRemoteRefs(constants.GitRemoteNameUpstream)
+	remoteRefs, err := ctx.gitSyncSvc.GetRemoteRefs(id)
 	if err != nil {
 		return currentBranch, err
 	}
@@ -1080,6 +2185,178 @@ func (ctx *spiderContext) _getCurrentBranch(gitClient *vcs.GitClient) (currentBr
 	return vcs.GitBranchNameMain, nil
 }
 
+// _getVersion version of the commit with files changed from its parent, and
+// the diff if withDiff is true
+func (ctx *spiderContext) _getVersion(commit *object.Commit, latest string, withDiff bool) (v entity.SpiderVersion, err error) {
+	v = entity.SpiderVersion{
+		Hash:        commit.Hash.String(),
+		Msg:         commit.Message,
+		AuthorName:  commit.Author.Name,
+		AuthorEmail: commit.Author.Email,
+		Timestamp:   commit.Author.When,
+		IsLatest:    commit.Hash.String() == latest,
+	}
+
+	// trees of the version and its parent (empty if the first version)
+	tree, err := commit.Tree()
+	if err != nil {
+		return v, trace.TraceError(err)
+	}
+	parentTree := &object.Tree{}
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return v, trace.TraceError(err)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return v, trace.TraceError(err)
+		}
+	}
+
+	// changes
+	patch, err := parentTree.Patch(tree)
+	if err != nil {
+		return v, trace.TraceError(err)
+	}
+	for _, st := range patch.Stats() {
+		v.Files = append(v.Files, entity.SpiderVersionFile{
+			Name:      st.Name,
+			Additions: st.Addition,
+			Deletions: st.Deletion,
+		})
+	}
+	if withDiff {
+		v.Diff = patch.String()
+	}
+
+	return v, nil
+}
+
+// _delete delete the spider, of which schedules are disabled first so that
+// they are removed from cron right away
+func (ctx *spiderContext) _delete(id primitive.ObjectID, opts *interfaces.SpiderDeleteOptions, u interfaces.User) (err error) {
+	schedules, err := ctx.modelSvc.GetScheduleList(bson.M{"spider_id": id, "enabled": true}, nil)
+	if err != nil && err != mongo2.ErrNoDocuments {
+		return err
+	}
+	for i := range schedules {
+		if err := ctx.scheduleSvc.Disable(&schedules[i], u); err != nil {
+			return err
+		}
+	}
+	return ctx.adminSvc.Delete(id, opts, u)
+}
+
+func (ctx *spiderContext) _getGitRefOptions(payload *entity.GitPayload) (opts *interfaces.SpiderGitRefOptions) {
+	return &interfaces.SpiderGitRefOptions{
+		Branch: payload.Branch,
+		Tag:    payload.Tag,
+		Commit: payload.Commit,
+	}
+}
+
+// _getGitRefsMap branches, remote-tracking branches and tags of the repo by
+// the commit hash they point to
+func (ctx *spiderContext) _getGitRefsMap(repo *git.Repository) (refsMap map[string][]vcs.GitRef, err error) {
+	refsMap = map[string][]vcs.GitRef{}
+	iter, err := repo.References()
+	if err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		refType := vcs.GitRefTypeBranch
+		h := ref.Hash()
+		if ref.Name().IsTag() {
+			refType = vcs.GitRefTypeTag
+			// commit of annotated tags
+			if tag, err := repo.TagObject(h); err == nil {
+				commit, err := tag.Commit()
+				if err != nil {
+					return nil
+				}
+				h = commit.Hash
+			}
+		} else if !ref.Name().IsBranch() && !ref.Name().IsRemote() {
+			return nil
+		}
+		refsMap[h.String()] = append(refsMap[h.String()], vcs.GitRef{
+			Type:     refType,
+			Name:     ref.Name().Short(),
+			FullName: ref.Name().String(),
+			Hash:     h.String(),
+		})
+		return nil
+	}); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	return refsMap, nil
+}
+
+func (ctx *spiderContext) _handleVersionError(c *gin.Context, err error) {
+	switch err {
+	case errors.ErrorSpiderInvalidVersion, errors.ErrorSpiderMissingRequiredOption, errors.ErrorSpiderGitDetachedAutoSync:
+		HandleErrorBadRequest(c, err)
+	case errors.ErrorSpiderVersionNotFound, errors.ErrorSpiderGitRefNotFound:
+		HandleErrorNotFound(c, err)
+	default:
+		HandleErrorInternalServerError(c, err)
+	}
+}
+
+// _getScrapyProject scrapy project in the spider fs, of which errors are handled
+func (ctx *spiderContext) _getScrapyProject(c *gin.Context) (id primitive.ObjectID, p *scrapy.Project, err error) {
+	// spider id
+	id, err = ctx._processActionRequest(c)
+	if err != nil {
+		return id, nil, err
+	}
+
+	// spider fs service
+	fsSvc, err := ctx.syncSvc.GetFsService(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return id, nil, err
+	}
+
+	// scrapy project
+	p, err = scrapy.NewProject(fsSvc)
+	if err != nil {
+		ctx._handleScrapyError(c, err)
+		return id, nil, err
+	}
+
+	return id, p, nil
+}
+
+// _handleSpiderfileError respond with bad request if the Spiderfile is invalid
+func (ctx *spiderContext) _handleSpiderfileError(c *gin.Context, err error) {
+	if errors2.Is(err, errors.ErrorSpiderInvalidSpiderfile) {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	HandleErrorInternalServerError(c, err)
+}
+
+func (ctx *spiderContext) _handleScrapyError(c *gin.Context, err error) {
+	switch err {
+	case errors.ErrorSpiderInvalidScrapyName,
+		errors.ErrorSpiderInvalidScrapyTemplate,
+		errors.ErrorSpiderInvalidScrapySetting,
+		errors.ErrorSpiderScrapyProjectExists,
+		errors.ErrorSpiderScrapySpiderExists,
+		errors.ErrorSpiderMissingRequiredOption:
+		HandleErrorBadRequest(c, err)
+	case errors.ErrorSpiderScrapyProjectNotFound:
+		HandleErrorNotFound(c, err)
+	default:
+		HandleErrorInternalServerError(c, err)
+	}
+}
+
 var _spiderCtx *spiderContext
 
 func newSpiderContext() *spiderContext {
@@ -1101,14 +2378,29 @@ func newSpiderContext() *spiderContext {
 	if err := c.Provide(admin.NewSpiderAdminService); err != nil {
 		panic(err)
 	}
+	if err := c.Provide(gitsync.ProvideGetSpiderGitSyncService("")); err != nil {
+		panic(err)
+	}
+	if err := c.Provide(configspider.NewConfigSpiderService); err != nil {
+		panic(err)
+	}
+	if err := c.Provide(schedule.ProvideGetScheduleService(config.DefaultConfigPath)); err != nil {
+		panic(err)
+	}
 	if err := c.Invoke(func(
 		modelSvc service.ModelService,
 		syncSvc interfaces.SpiderSyncService,
 		adminSvc interfaces.SpiderAdminService,
+		gitSyncSvc interfaces.SpiderGitSyncService,
+		configSpiderSvc interfaces.ConfigSpiderService,
+		scheduleSvc interfaces.ScheduleService,
 	) {
 		ctx.modelSvc = modelSvc
 		ctx.syncSvc = syncSvc
 		ctx.adminSvc = adminSvc
+		ctx.gitSyncSvc = gitSyncSvc
+		ctx.configSpiderSvc = configSpiderSvc
+		ctx.scheduleSvc = scheduleSvc
 	}); err != nil {
 		panic(err)
 	}
@@ -1121,6 +2413,14 @@ func newSpiderContext() *spiderContext {
 	return ctx
 }
 
+// maskSpiderSecrets hide secrets of the spider in responses
+func maskSpiderSecrets(s *models.Spider) {
+	if s.GitPassword != "" {
+		s.GitPassword = constants.SecretMask
+	}
+	s.Envs = utils.MaskSecretEnvs(s.Envs)
+}
+
 func newSpiderController() *spiderController {
 	actions := getSpiderActions()
 	modelSvc, err := service.GetService()
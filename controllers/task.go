@@ -2,28 +2,41 @@ package controllers
 
 import (
 	"github.com/luke513009828/crawlab-core/config"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/entity"
 	"github.com/luke513009828/crawlab-core/errors"
 	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
 	"github.com/luke513009828/crawlab-core/models/models"
 	"github.com/luke513009828/crawlab-core/models/service"
 	"github.com/luke513009828/crawlab-core/result"
 	"github.com/luke513009828/crawlab-core/spider/admin"
 	"github.com/luke513009828/crawlab-core/task/scheduler"
+	"github.com/luke513009828/crawlab-core/task/stats"
 	"github.com/luke513009828/crawlab-core/utils"
 	"github.com/crawlab-team/crawlab-db/mongo"
 	clog "github.com/crawlab-team/crawlab-log"
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	mongo2 "go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/dig"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 var TaskController *taskController
 
+const (
+	taskLogStreamPageSize      = 1000
+	taskLogStreamCheckInterval = 3 * time.Second
+)
+
 func getTaskActions() []Action {
 	taskCtx := newTaskContext()
 	return []Action{
@@ -47,6 +60,11 @@ func getTaskActions() []Action {
 			Path:        "/:id/logs",
 			HandlerFunc: taskCtx.getLogs,
 		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/logs/stream",
+			HandlerFunc: taskCtx.streamLogs,
+		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/:id/data",
@@ -61,20 +79,72 @@ type taskController struct {
 	ctx *taskContext
 }
 
+func (ctr *taskController) Get(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	t, err := ctr.ctx.modelSvc.GetTaskById(id)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	t.Envs = utils.MaskSecretEnvs(t.Envs)
+	HandleSuccessWithData(c, t)
+}
+
 func (ctr *taskController) GetList(c *gin.Context) {
 	withStats := c.Query("stats")
 	if withStats == "" {
-		ctr.d.GetList(c)
+		ctr.ctx.getList(c)
 		return
 	}
 	ctr.ctx.getListWithStats(c)
 }
 
+func (ctr *taskController) Post(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	var t models.Task
+	if err := c.ShouldBindJSON(&t); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if t.Id != id {
+		HandleErrorBadRequest(c, errors.ErrorHttpBadRequest)
+		return
+	}
+	tDb, err := ctr.ctx.modelSvc.GetTaskById(id)
+	if err != nil {
+		HandleErrorNotFound(c, err)
+		return
+	}
+
+	// values of secret envs are unchanged if they are masked
+	t.Envs = utils.RestoreSecretEnvs(t.Envs, tDb.Envs)
+
+	if err := delegate.NewModelDelegate(&t, GetUserFromContext(c)).Save(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	t.Envs = utils.MaskSecretEnvs(t.Envs)
+	HandleSuccessWithData(c, t)
+}
+
 type taskContext struct {
 	modelSvc     service.ModelService
 	modelTaskSvc interfaces.ModelBaseService
 	adminSvc     interfaces.SpiderAdminService
 	schedulerSvc interfaces.TaskSchedulerService
+	statsSvc     interfaces.TaskStatsService
 	l            clog.Driver
 
 	// internals
@@ -95,6 +165,16 @@ func (ctx *taskContext) run(c *gin.Context) {
 		return
 	}
 
+	// permission to run the spider
+	if err := CheckResourcePermission(c, ControllerIdSpider, constants.PermissionActionUpdate, t.GetSpiderId()); err != nil {
+		if err == errors.ErrorUserForbidden {
+			HandleErrorForbidden(c, err)
+		} else {
+			HandleErrorInternalServerError(c, err)
+		}
+		return
+	}
+
 	// spider
 	s, err := ctx.modelSvc.GetSpiderById(t.GetSpiderId())
 	if err != nil {
@@ -104,10 +184,18 @@ func (ctx *taskContext) run(c *gin.Context) {
 
 	// options
 	opts := &interfaces.SpiderRunOptions{
-		Mode:     t.Mode,
-		NodeIds:  t.NodeIds,
-		Param:    t.Param,
-		Priority: t.Priority,
+		Mode:          t.Mode,
+		NodeIds:       t.NodeIds,
+		NodeTags:      t.NodeTags,
+		NodeTagsMatch: t.NodeTagsMatch,
+		Param:         t.Param,
+		Priority:      t.Priority,
+		Envs:          ctx._getEnvsMap(t.Envs),
+		Timeout:       t.Timeout,
+	}
+	if t.IsVersionPinned {
+		// run the same version of spider files
+		opts.Version = t.Version
 	}
 
 	// user
@@ -143,10 +231,18 @@ func (ctx *taskContext) restart(c *gin.Context) {
 
 	// options
 	opts := &interfaces.SpiderRunOptions{
-		Mode:     t.Mode,
-		NodeIds:  t.NodeIds,
-		Param:    t.Param,
-		Priority: t.Priority,
+		Mode:          t.Mode,
+		NodeIds:       t.NodeIds,
+		NodeTags:      t.NodeTags,
+		NodeTagsMatch: t.NodeTagsMatch,
+		Param:         t.Param,
+		Priority:      t.Priority,
+		Envs:          ctx._getEnvsMap(t.Envs),
+		Timeout:       t.Timeout,
+	}
+	if t.IsVersionPinned {
+		// run the same version of spider files
+		opts.Version = t.Version
 	}
 
 	// user
@@ -215,8 +311,19 @@ func (ctx *taskContext) getLogs(c *gin.Context) {
 		return
 	}
 
+	// filter
+	var f entity.TaskLogFilter
+	if err := c.ShouldBindQuery(&f); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if !f.IsEmpty() {
+		ctx._getFilteredLogs(c, l, &f, p)
+		return
+	}
+
 	// logs
-	logs, err := l.Find("", (p.Page-1)*p.Size, p.Size)
+	lines, err := l.Find("", (p.Page-1)*p.Size, p.Size)
 	if err != nil {
 		if strings.HasSuffix(err.Error(), "Status:404 Not Found") {
 			HandleSuccess(c)
@@ -231,9 +338,134 @@ func (ctx *taskContext) getLogs(c *gin.Context) {
 		return
 	}
 
+	// content of lines
+	logs := make([]string, len(lines))
+	for i, line := range lines {
+		logs[i] = utils.DecodeTaskLogLine(line).Content
+	}
+
 	HandleSuccessWithListData(c, logs, total)
 }
 
+// streamLogs stream log lines of the task as server-sent events, starting from
+// the line offset given by query "from" or header "Last-Event-ID", and end the
+// stream when the task reaches a terminal status
+func (ctx *taskContext) streamLogs(c *gin.Context) {
+	// id
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// offset
+	next, err := ctx._getLogStreamOffset(c)
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// task
+	t, err := ctx.modelSvc.GetTaskById(id)
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			HandleErrorNotFound(c, err)
+		} else {
+			HandleErrorInternalServerError(c, err)
+		}
+		return
+	}
+
+	// log driver
+	l, err := ctx._getLogDriver(id)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// subscribe before reading stored lines so that no lines are missed
+	ch, unsubscribe := ctx.statsSvc.SubscribeLogs(id)
+	defer unsubscribe()
+
+	// headers
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// stored lines
+	next = ctx._sendStoredLogs(c, l, next, -1)
+
+	// end if the task has ended already
+	if utils.IsTaskEnded(t.Status) {
+		c.SSEvent(constants.TaskLogStreamEventEnd, t.Status)
+		return
+	}
+
+	// live lines
+	ticker := time.NewTicker(taskLogStreamCheckInterval)
+	defer ticker.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case line := <-ch:
+			next = ctx._sendLogLine(c, l, next, line)
+			return true
+		case <-ticker.C:
+			t, err := ctx.modelSvc.GetTaskById(id)
+			if err != nil || !utils.IsTaskEnded(t.Status) {
+				// keep the connection alive
+				_, _ = w.Write([]byte(":\n\n"))
+				return true
+			}
+
+			// send remaining lines and end
+			for drained := false; !drained; {
+				select {
+				case line := <-ch:
+					next = ctx._sendLogLine(c, l, next, line)
+				default:
+					drained = true
+				}
+			}
+			c.SSEvent(constants.TaskLogStreamEventEnd, t.Status)
+			return false
+		}
+	})
+}
+
+func (ctx *taskContext) getList(c *gin.Context) {
+	// params
+	query := MustGetFilterQuery(c)
+	opts := &mongo.FindOptions{
+		Sort: MustGetSortOption(c),
+	}
+	if !MustGetFilterAll(c) {
+		pagination := MustGetPagination(c)
+		opts.Skip = pagination.Size * (pagination.Page - 1)
+		opts.Limit = pagination.Size
+	}
+
+	// tasks
+	list, err := ctx.modelSvc.GetTaskList(query, opts)
+	if err != nil && err.Error() != mongo2.ErrNoDocuments.Error() {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	for i := range list {
+		list[i].Envs = utils.MaskSecretEnvs(list[i].Envs)
+	}
+
+	// total count
+	total, err := ctx.modelTaskSvc.Count(query)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, list, total)
+}
+
 func (ctx *taskContext) getListWithStats(c *gin.Context) {
 	// params
 	pagination := MustGetPagination(c)
@@ -301,6 +533,7 @@ func (ctx *taskContext) getListWithStats(c *gin.Context) {
 		if ok {
 			t.Stat = &s
 		}
+		t.Envs = utils.MaskSecretEnvs(t.Envs)
 		data = append(data, *t)
 	}
 
@@ -370,6 +603,135 @@ func (ctx *taskContext) getData(c *gin.Context) {
 	HandleSuccessWithListData(c, data, total)
 }
 
+func (ctx *taskContext) _getEnvsMap(envs []models.Env) (res map[string]string) {
+	res = map[string]string{}
+	for _, env := range envs {
+		if env.Name == "" {
+			continue
+		}
+		res[env.Name] = env.Value
+	}
+	return res
+}
+
+// _getFilteredLogs scan log lines of the task until the page of lines matching
+// the filter is full, and respond with them along with their line numbers and
+// metadata. The total is the number of matching lines scanned, plus one if
+// lines are left unscanned, which may match as well.
+func (ctx *taskContext) _getFilteredLogs(c *gin.Context, l clog.Driver, f *entity.TaskLogFilter, p *entity.Pagination) {
+	if err := f.Compile(); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	skip := (p.Page - 1) * p.Size
+	total := 0
+	logs := make([]interfaces.TaskLogLine, 0, p.Size)
+	for offset := 0; ; {
+		lines, err := l.Find("", offset, taskLogStreamPageSize)
+		if err != nil {
+			if strings.HasSuffix(err.Error(), "Status:404 Not Found") {
+				break
+			}
+			HandleErrorInternalServerError(c, err)
+			return
+		}
+		for _, content := range lines {
+			if len(logs) == p.Size {
+				// page is full, of which following lines are left unscanned
+				HandleSuccessWithListData(c, logs, total+1)
+				return
+			}
+			line := utils.DecodeTaskLogLine(content)
+			line.Number = offset
+			offset++
+			if !f.Match(line) {
+				continue
+			}
+			if total >= skip {
+				logs = append(logs, line)
+			}
+			total++
+		}
+		if len(lines) < taskLogStreamPageSize {
+			break
+		}
+	}
+
+	HandleSuccessWithListData(c, logs, total)
+}
+
+// _getLogStreamOffset get the line offset to start streaming logs from, which
+// is the line after "Last-Event-ID" when resuming an event stream
+func (ctx *taskContext) _getLogStreamOffset(c *gin.Context) (offset int, err error) {
+	if lastId := c.GetHeader("Last-Event-ID"); lastId != "" {
+		offset, err = strconv.Atoi(lastId)
+		if err != nil {
+			return 0, err
+		}
+		return offset + 1, nil
+	}
+	if from := c.Query("from"); from != "" {
+		offset, err = strconv.Atoi(from)
+		if err != nil {
+			return 0, err
+		}
+		if offset < 0 {
+			return 0, errors.ErrorHttpBadRequest
+		}
+	}
+	return offset, nil
+}
+
+// _sendLogLine send the live log line and return the offset of the next line,
+// where lines missed before it are read from the log driver
+func (ctx *taskContext) _sendLogLine(c *gin.Context, l clog.Driver, next int, line interfaces.TaskLogLine) int {
+	if line.Number < next {
+		return next
+	}
+	if line.Number > next {
+		ctx._sendStoredLogs(c, l, next, line.Number)
+	}
+	c.Render(-1, sse.Event{
+		Id:    strconv.Itoa(line.Number),
+		Event: constants.TaskLogStreamEventLog,
+		Data:  line,
+	})
+	c.Writer.Flush()
+	return line.Number + 1
+}
+
+// _sendStoredLogs send log lines from the offset until the end offset (or all
+// stored lines if end is negative) read from the log driver, and return the
+// offset of the next line
+func (ctx *taskContext) _sendStoredLogs(c *gin.Context, l clog.Driver, next, end int) int {
+	for end < 0 || next < end {
+		limit := taskLogStreamPageSize
+		if end >= 0 && end-next < limit {
+			limit = end - next
+		}
+		lines, err := l.Find("", next, limit)
+		if err != nil || len(lines) == 0 {
+			break
+		}
+		for _, content := range lines {
+			line := utils.DecodeTaskLogLine(content)
+			line.Number = next
+			c.Render(-1, sse.Event{
+				Id:    strconv.Itoa(next),
+				Event: constants.TaskLogStreamEventLog,
+				Data:  line,
+			})
+			next++
+		}
+		c.Writer.Flush()
+		if len(lines) < limit {
+			break
+		}
+	}
+	return next
+}
+
 func (ctx *taskContext) _getLogDriver(id primitive.ObjectID) (l clog.Driver, err error) {
 	// attempt to get from cache
 	res, ok := ctx.drivers.Load(id)
@@ -407,14 +769,19 @@ func newTaskContext() *taskContext {
 	if err := c.Provide(scheduler.ProvideGetTaskSchedulerService(config.DefaultConfigPath)); err != nil {
 		panic(err)
 	}
+	if err := c.Provide(stats.ProvideGetTaskStatsService(config.DefaultConfigPath)); err != nil {
+		panic(err)
+	}
 	if err := c.Invoke(func(
 		modelSvc service.ModelService,
 		adminSvc interfaces.SpiderAdminService,
 		schedulerSvc interfaces.TaskSchedulerService,
+		statsSvc interfaces.TaskStatsService,
 	) {
 		ctx.modelSvc = modelSvc
 		ctx.adminSvc = adminSvc
 		ctx.schedulerSvc = schedulerSvc
+		ctx.statsSvc = statsSvc
 	}); err != nil {
 		panic(err)
 	}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/controllers"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/user"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"testing"
+)
+
+func TestRbac_GetPermissionOwnerType(t *testing.T) {
+	require.Equal(t, constants.OwnerTypeAll, controllers.GetPermissionOwnerType(constants.RoleAdmin, controllers.ControllerIdNode, constants.PermissionActionDelete))
+	require.Equal(t, constants.OwnerTypeAll, controllers.GetPermissionOwnerType(constants.RoleNormal, controllers.ControllerIdNode, constants.PermissionActionRead))
+	require.Empty(t, controllers.GetPermissionOwnerType(constants.RoleNormal, controllers.ControllerIdNode, constants.PermissionActionDelete))
+	require.Empty(t, controllers.GetPermissionOwnerType(constants.RoleNormal, controllers.ControllerIdUser, constants.PermissionActionDelete))
+	require.Equal(t, constants.OwnerTypeMe, controllers.GetPermissionOwnerType(constants.RoleNormal, controllers.ControllerIdUser, constants.PermissionActionRead))
+	require.Empty(t, controllers.GetPermissionOwnerType(constants.RoleNormal, controllers.ControllerIdUser, constants.PermissionActionUpdate))
+	require.Equal(t, constants.OwnerTypePublic, controllers.GetPermissionOwnerType(constants.RoleNormal, controllers.ControllerIdSpider, constants.PermissionActionRead))
+	require.Equal(t, constants.OwnerTypeAll, controllers.GetPermissionOwnerType(constants.RoleNormal, controllers.ControllerIdSpider, constants.PermissionActionCreate))
+	require.Equal(t, constants.OwnerTypeMe, controllers.GetPermissionOwnerType(constants.RoleNormal, controllers.ControllerIdTask, constants.PermissionActionDelete))
+	require.Empty(t, controllers.GetPermissionOwnerType(constants.RoleNormal, controllers.ControllerIdGit, constants.PermissionActionRead))
+	require.Empty(t, controllers.GetPermissionOwnerType("unknown", controllers.ControllerIdTask, constants.PermissionActionRead))
+}
+
+func TestRbac_NormalUser(t *testing.T) {
+	T.Setup(t)
+	e := T.NewExpect(t)
+
+	// spiders of admin
+	privateSpider := models.Spider{Name: "private spider"}
+	res := T.WithAuth(e.PUT("/spiders")).WithJSON(privateSpider).Expect().Status(http.StatusOK).JSON().Object()
+	privateId := res.Path("$.data._id").String().Raw()
+	publicSpider := models.Spider{Name: "public spider", IsPublic: true}
+	res = T.WithAuth(e.PUT("/spiders")).WithJSON(publicSpider).Expect().Status(http.StatusOK).JSON().Object()
+	publicId := res.Path("$.data._id").String().Raw()
+
+	// normal user
+	userSvc, err := user.GetUserService()
+	require.Nil(t, err)
+	err = userSvc.Create(&interfaces.UserCreateOptions{
+		Username: "normal",
+		Password: "normal",
+		Role:     constants.RoleNormal,
+	})
+	require.Nil(t, err)
+	token := e.POST("/login").WithJSON(map[string]string{
+		"username": "normal",
+		"password": "normal",
+	}).Expect().JSON().Object().Path("$.data").String().Raw()
+	withAuth := func(req *httpexpect.Request) *httpexpect.Request {
+		return req.WithHeader("Authorization", token)
+	}
+
+	// read-only resources
+	withAuth(e.GET("/nodes")).Expect().Status(http.StatusOK)
+	withAuth(e.DELETE("/settings")).WithJSON(map[string]interface{}{"ids": []string{}}).Expect().Status(http.StatusForbidden)
+
+	// git credentials
+	withAuth(e.GET("/gits")).Expect().Status(http.StatusForbidden)
+
+	// results of data collections without own spiders
+	withAuth(e.GET("/results/" + primitive.NewObjectID().Hex())).Expect().Status(http.StatusForbidden)
+
+	// spiders of others
+	withAuth(e.GET("/spiders/" + privateId)).Expect().Status(http.StatusForbidden)
+	withAuth(e.GET("/spiders/" + publicId)).Expect().Status(http.StatusOK)
+	withAuth(e.DELETE("/spiders/" + publicId)).Expect().Status(http.StatusForbidden)
+	res = withAuth(e.GET("/spiders")).Expect().Status(http.StatusOK).JSON().Object()
+	res.Path("$.total").Equal(1)
+
+	// own spider
+	ownSpider := models.Spider{Name: "own spider"}
+	res = withAuth(e.PUT("/spiders")).WithJSON(ownSpider).Expect().Status(http.StatusOK).JSON().Object()
+	ownId := res.Path("$.data._id").String().Raw()
+	withAuth(e.GET("/spiders/" + ownId)).Expect().Status(http.StatusOK)
+	res = withAuth(e.GET("/spiders")).Expect().Status(http.StatusOK).JSON().Object()
+	res.Path("$.total").Equal(2)
+}
@@ -1,13 +1,22 @@
 package controllers
 
 import (
+	"encoding/json"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/luke513009828/crawlab-core/errors"
 	"github.com/luke513009828/crawlab-core/interfaces"
 	"github.com/luke513009828/crawlab-core/models/delegate"
 	"github.com/luke513009828/crawlab-core/models/models"
 	"github.com/luke513009828/crawlab-core/models/service"
 	"github.com/luke513009828/crawlab-core/user"
+	"github.com/luke513009828/crawlab-core/utils"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/dig"
+	"time"
 )
 
 var TokenController *tokenController
@@ -27,20 +36,127 @@ func (ctr *tokenController) Put(c *gin.Context) {
 		HandleErrorBadRequest(c, err)
 		return
 	}
+
+	// scopes
+	if len(t.Scopes) == 0 {
+		t.Scopes = []string{constants.TokenScopeReadOnly}
+	}
+	for _, scope := range t.Scopes {
+		if !utils.IsValidTokenScope(scope) {
+			HandleErrorBadRequest(c, errors.ErrorUserTokenInvalidScope)
+			return
+		}
+	}
+
+	// expiration
+	if !t.ExpireTs.IsZero() && t.IsExpired(time.Now()) {
+		HandleErrorBadRequest(c, errors.ErrorUserTokenExpired)
+		return
+	}
+
 	u, err := ctr.ctx.userSvc.GetCurrentUser(c)
 	if err != nil {
 		HandleErrorUnauthorized(c, err)
 		return
 	}
-	t.Token, err = ctr.ctx.userSvc.MakeToken(u)
+	t.Id = primitive.NewObjectID()
+	t.UserId = u.GetId()
+	t.LastUsedTs = time.Time{}
+	t.Token, err = ctr.ctx.userSvc.MakeApiToken(u, &interfaces.UserTokenOptions{
+		Id:       t.Id,
+		ExpireTs: t.ExpireTs,
+	})
 	if err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
+	t.TokenHash = utils.HashToken(t.Token)
 	if err := delegate.NewModelDelegate(&t, GetUserFromContext(c)).Add(); err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
+	HandleSuccessWithData(c, t)
+}
+
+func (ctr *tokenController) Post(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	var t models.Token
+	if err := c.ShouldBindJSON(&t); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if t.Id != id {
+		HandleErrorBadRequest(c, errors.ErrorHttpBadRequest)
+		return
+	}
+
+	// existing token
+	_t, err := ctr.ctx.modelSvc.GetTokenById(id)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// scopes, expiration and owner are signed in the token or granted on
+	// creation, which cannot be changed
+	if !ctr.ctx._isScopesEqual(t.Scopes, _t.Scopes) || !t.ExpireTs.Equal(_t.ExpireTs) || t.UserId != _t.UserId {
+		HandleErrorBadRequest(c, errors.ErrorUserTokenImmutableField)
+		return
+	}
+
+	// only name can be changed
+	_t.Name = t.Name
+	if err := delegate.NewModelDelegate(_t, GetUserFromContext(c)).Save(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	HandleSuccessWithData(c, _t)
+}
+
+func (ctr *tokenController) PostList(c *gin.Context) {
+	// payload
+	var payload entity.BatchRequestPayloadWithStringData
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// doc to update
+	var doc models.Token
+	if err := json.Unmarshal([]byte(payload.Data), &doc); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// only name can be changed
+	for _, field := range payload.Fields {
+		if field != "name" {
+			HandleErrorBadRequest(c, errors.ErrorUserTokenImmutableField)
+			return
+		}
+	}
+
+	// query
+	query := WithOwnerQuery(c, bson.M{
+		"_id": bson.M{
+			"$in": payload.Ids,
+		},
+	})
+
+	// update tokens
+	if err := ctr.ctx.modelSvc.GetBaseService(interfaces.ModelIdToken).UpdateDoc(query, &doc, payload.Fields); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
 	HandleSuccess(c)
 }
 
@@ -49,6 +165,18 @@ type tokenContext struct {
 	userSvc  interfaces.UserService
 }
 
+func (ctx *tokenContext) _isScopesEqual(scopes1, scopes2 []string) (ok bool) {
+	if len(scopes1) != len(scopes2) {
+		return false
+	}
+	for _, scope := range scopes1 {
+		if !utils.Contains(scopes2, scope) {
+			return false
+		}
+	}
+	return true
+}
+
 func newTokenContext() *tokenContext {
 	// context
 	ctx := &tokenContext{}
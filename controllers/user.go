@@ -2,10 +2,10 @@ package controllers
 
 import (
 	"encoding/json"
-	"github.com/luke513009828/crawlab-core/constants"
 	"github.com/luke513009828/crawlab-core/entity"
 	"github.com/luke513009828/crawlab-core/errors"
 	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
 	"github.com/luke513009828/crawlab-core/models/models"
 	"github.com/luke513009828/crawlab-core/models/service"
 	"github.com/luke513009828/crawlab-core/user"
@@ -20,6 +20,29 @@ import (
 
 var UserController *userController
 
+var MeController ActionController
+
+func getMeActions() []Action {
+	userCtx := newUserContext()
+	return []Action{
+		{
+			Method:      http.MethodGet,
+			Path:        "",
+			HandlerFunc: userCtx.me,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "",
+			HandlerFunc: userCtx.postMe,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/change-password",
+			HandlerFunc: userCtx.changeMyPassword,
+		},
+	}
+}
+
 func getUserActions() []Action {
 	userCtx := newUserContext()
 	return []Action{
@@ -55,6 +78,40 @@ func (ctr *userController) Put(c *gin.Context) {
 	HandleSuccess(c)
 }
 
+func (ctr *userController) Post(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	var u models.User
+	if err := c.ShouldBindJSON(&u); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if u.Id != id {
+		HandleErrorBadRequest(c, errors.ErrorHttpBadRequest)
+		return
+	}
+	uDb, err := ctr.ctx.modelSvc.GetUserById(id)
+	if err != nil {
+		HandleErrorNotFound(c, err)
+		return
+	}
+
+	// only admin users can change roles
+	if !isAdmin(c) && u.Role != uDb.Role {
+		HandleErrorForbidden(c, errors.ErrorUserForbidden)
+		return
+	}
+
+	if err := delegate.NewModelDelegate(&u, GetUserFromContext(c)).Save(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	HandleSuccessWithData(c, u)
+}
+
 func (ctr *userController) PostList(c *gin.Context) {
 	// payload
 	var payload entity.BatchRequestPayloadWithStringData
@@ -70,12 +127,18 @@ func (ctr *userController) PostList(c *gin.Context) {
 		return
 	}
 
+	// only admin users can change roles or passwords of users
+	if !isAdmin(c) && (utils.Contains(payload.Fields, "role") || utils.Contains(payload.Fields, "password")) {
+		HandleErrorForbidden(c, errors.ErrorUserForbidden)
+		return
+	}
+
 	// query
-	query := bson.M{
+	query := WithOwnerQuery(c, bson.M{
 		"_id": bson.M{
 			"$in": payload.Ids,
 		},
-	}
+	})
 
 	// update users
 	if err := ctr.ctx.modelSvc.GetBaseService(interfaces.ModelIdUser).UpdateDoc(query, &doc, payload.Fields); err != nil {
@@ -128,6 +191,54 @@ func (ctx *userContext) changePassword(c *gin.Context) {
 		HandleErrorBadRequest(c, err)
 		return
 	}
+	ctx._changePassword(c, id)
+}
+
+func (ctx *userContext) changeMyPassword(c *gin.Context) {
+	u := GetUserFromContext(c)
+	if u == nil {
+		HandleErrorUnauthorized(c, errors.ErrorUserUnauthorized)
+		return
+	}
+	ctx._changePassword(c, u.GetId())
+}
+
+func (ctx *userContext) me(c *gin.Context) {
+	u := GetUserFromContext(c)
+	if u == nil {
+		HandleErrorUnauthorized(c, errors.ErrorUserUnauthorized)
+		return
+	}
+	HandleSuccessWithData(c, u)
+}
+
+// postMe update profile of the current user, of which only email is
+// updatable as username and role are managed by admin users
+func (ctx *userContext) postMe(c *gin.Context) {
+	u := GetUserFromContext(c)
+	if u == nil {
+		HandleErrorUnauthorized(c, errors.ErrorUserUnauthorized)
+		return
+	}
+	var payload models.User
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	uDb, err := ctx.modelSvc.GetUserById(u.GetId())
+	if err != nil {
+		HandleErrorNotFound(c, err)
+		return
+	}
+	uDb.Email = payload.Email
+	if err := delegate.NewModelDelegate(uDb, u).Save(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	HandleSuccessWithData(c, uDb)
+}
+
+func (ctx *userContext) _changePassword(c *gin.Context, id primitive.ObjectID) {
 	var payload map[string]string
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		HandleErrorBadRequest(c, err)
@@ -142,27 +253,13 @@ func (ctx *userContext) changePassword(c *gin.Context) {
 		HandleErrorBadRequest(c, errors.ErrorUserInvalidPassword)
 		return
 	}
-	if err := ctx.userSvc.ChangePassword(id, password); err != nil {
+	if err := ctx.userSvc.ChangePassword(id, password, GetUserFromContext(c)); err != nil {
 		HandleErrorInternalServerError(c, err)
 		return
 	}
 	HandleSuccess(c)
 }
 
-func (ctx *userContext) me(c *gin.Context) {
-	res, ok := c.Get(constants.UserContextKey)
-	if !ok {
-		HandleErrorUnauthorized(c, errors.ErrorUserUnauthorized)
-		return
-	}
-	u, ok := res.(interfaces.User)
-	if !ok {
-		HandleErrorUnauthorized(c, errors.ErrorUserUnauthorized)
-		return
-	}
-	HandleSuccessWithData(c, u)
-}
-
 func newUserContext() *userContext {
 	// context
 	ctx := &userContext{}
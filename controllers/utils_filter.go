@@ -14,8 +14,12 @@ import (
 
 // GetFilter Get entity.Filter from gin.Context
 func GetFilter(c *gin.Context) (f *entity.Filter, err error) {
+	return GetFilterFromConditions(c.Query(constants.FilterQueryFieldConditions))
+}
+
+// GetFilterFromConditions Get entity.Filter from conditions in json
+func GetFilterFromConditions(condStr string) (f *entity.Filter, err error) {
 	// bind
-	condStr := c.Query(constants.FilterQueryFieldConditions)
 	var conditions []entity.Condition
 	if err := json.Unmarshal([]byte(condStr), &conditions); err != nil {
 		return nil, err
@@ -80,12 +84,13 @@ func GetFilterQuery(c *gin.Context) (q bson.M, err error) {
 	return FilterToQuery(f)
 }
 
+// MustGetFilterQuery Get bson.M from gin.Context limited to resources owned by the current user
 func MustGetFilterQuery(c *gin.Context) (q bson.M) {
 	q, err := GetFilterQuery(c)
 	if err != nil {
-		return nil
+		return WithOwnerQuery(c, nil)
 	}
-	return q
+	return WithOwnerQuery(c, q)
 }
 
 // FilterToQuery Translate entity.Filter to bson.M
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+)
+
+var VariableController *variableController
+
+type variableController struct {
+	ListControllerDelegate
+	modelSvc service.ModelService
+}
+
+func (ctr *variableController) Get(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	variable, err := ctr.modelSvc.GetVariableById(id)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			HandleErrorNotFound(c, err)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	maskVariableValue(variable)
+	HandleSuccessWithData(c, variable)
+}
+
+func (ctr *variableController) GetList(c *gin.Context) {
+	// params
+	query := MustGetFilterQuery(c)
+	opts := &mongo.FindOptions{
+		Sort: MustGetSortOption(c),
+	}
+	if !MustGetFilterAll(c) {
+		pagination := MustGetPagination(c)
+		opts.Skip = pagination.Size * (pagination.Page - 1)
+		opts.Limit = pagination.Size
+	}
+
+	// variables
+	list, err := ctr.modelSvc.GetVariableList(query, opts)
+	if err != nil && err.Error() != mongo2.ErrNoDocuments.Error() {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	for i := range list {
+		maskVariableValue(&list[i])
+	}
+
+	// total count
+	total, err := ctr.modelSvc.GetBaseService(interfaces.ModelIdVariable).Count(query)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, list, total)
+}
+
+func (ctr *variableController) Put(c *gin.Context) {
+	var variable models.Variable
+	if err := c.ShouldBindJSON(&variable); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if err := delegate.NewModelDelegate(&variable, GetUserFromContext(c)).Add(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	maskVariableValue(&variable)
+	HandleSuccessWithData(c, variable)
+}
+
+func (ctr *variableController) Post(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	var variable models.Variable
+	if err := c.ShouldBindJSON(&variable); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if variable.Id != id {
+		HandleErrorBadRequest(c, errors.ErrorHttpBadRequest)
+		return
+	}
+	variableDb, err := ctr.modelSvc.GetVariableById(id)
+	if err != nil {
+		HandleErrorNotFound(c, err)
+		return
+	}
+
+	// value is unchanged if it's masked
+	if variableDb.Secret && variable.Value == constants.SecretMask {
+		variable.Value = variableDb.Value
+	}
+
+	if err := delegate.NewModelDelegate(&variable, GetUserFromContext(c)).Save(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	maskVariableValue(&variable)
+	HandleSuccessWithData(c, variable)
+}
+
+func (ctr *variableController) PostList(c *gin.Context) {
+	// payload
+	var payload entity.BatchRequestPayloadWithStringData
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// doc to update
+	var doc models.Variable
+	if err := json.Unmarshal([]byte(payload.Data), &doc); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// values of secret variables are unchanged if masked
+	var fields []string
+	for _, field := range payload.Fields {
+		if field == "value" && doc.Value == constants.SecretMask {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		HandleSuccess(c)
+		return
+	}
+
+	// query
+	query := WithOwnerQuery(c, bson.M{
+		"_id": bson.M{
+			"$in": payload.Ids,
+		},
+	})
+
+	// update variables
+	if err := ctr.modelSvc.GetBaseService(interfaces.ModelIdVariable).UpdateDoc(query, &doc, fields); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccess(c)
+}
+
+// maskVariableValue hide the value of the secret variable in responses
+func maskVariableValue(variable *models.Variable) {
+	if variable.Secret && variable.Value != "" {
+		variable.Value = constants.SecretMask
+	}
+}
+
+func newVariableController() *variableController {
+	modelSvc, err := service.GetService()
+	if err != nil {
+		panic(err)
+	}
+
+	ctr := NewListControllerDelegate(ControllerIdVariable, modelSvc.GetBaseService(interfaces.ModelIdVariable))
+
+	return &variableController{
+		ListControllerDelegate: *ctr,
+		modelSvc:               modelSvc,
+	}
+}
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"github.com/luke513009828/crawlab-core/config"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/luke513009828/crawlab-core/workflow"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/dig"
+	"net/http"
+)
+
+var WorkflowController *workflowController
+
+func getWorkflowActions() []Action {
+	workflowCtx := newWorkflowContext()
+	return []Action{
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/run",
+			HandlerFunc: workflowCtx.run,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/runs",
+			HandlerFunc: workflowCtx.getRunList,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:id/runs/:run_id",
+			HandlerFunc: workflowCtx.getRun,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/:id/runs/:run_id/cancel",
+			HandlerFunc: workflowCtx.cancelRun,
+		},
+	}
+}
+
+type workflowController struct {
+	ListActionControllerDelegate
+	d   ListActionControllerDelegate
+	ctx *workflowContext
+}
+
+func (ctr *workflowController) Put(c *gin.Context) {
+	var w models.Workflow
+	if err := c.ShouldBindJSON(&w); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if err := w.Validate(); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if err := delegate.NewModelDelegate(&w, GetUserFromContext(c)).Add(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	HandleSuccessWithData(c, w)
+}
+
+func (ctr *workflowController) Post(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	var w models.Workflow
+	if err := c.ShouldBindJSON(&w); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if w.Id != id {
+		HandleErrorBadRequest(c, errors.ErrorHttpBadRequest)
+		return
+	}
+	if err := w.Validate(); err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+	if _, err := ctr.ctx.modelSvc.GetWorkflowById(id); err != nil {
+		HandleErrorNotFound(c, err)
+		return
+	}
+	if err := delegate.NewModelDelegate(&w, GetUserFromContext(c)).Save(); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	HandleSuccessWithData(c, w)
+}
+
+type workflowContext struct {
+	modelSvc    service.ModelService
+	workflowSvc interfaces.WorkflowService
+}
+
+func (ctx *workflowContext) run(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	runId, err := ctx.workflowSvc.Run(id, GetUserFromContext(c))
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithData(c, runId)
+}
+
+func (ctx *workflowContext) getRunList(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// pagination
+	p, err := GetPagination(c)
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return
+	}
+
+	// query
+	query := bson.M{
+		"workflow_id": id,
+	}
+
+	// list
+	runs, err := ctx.modelSvc.GetWorkflowRunList(query, &mongo.FindOptions{
+		Skip:  (p.Page - 1) * p.Size,
+		Limit: p.Size,
+		Sort:  bson.D{{"_id", -1}},
+	})
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			HandleSuccessWithListData(c, nil, 0)
+			return
+		}
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	// total
+	total, err := ctx.modelSvc.GetBaseService(interfaces.ModelIdWorkflowRun).Count(query)
+	if err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+
+	HandleSuccessWithListData(c, runs, total)
+}
+
+func (ctx *workflowContext) getRun(c *gin.Context) {
+	r, err := ctx._getRun(c)
+	if err != nil {
+		return
+	}
+	HandleSuccessWithData(c, r)
+}
+
+func (ctx *workflowContext) cancelRun(c *gin.Context) {
+	r, err := ctx._getRun(c)
+	if err != nil {
+		return
+	}
+	if err := ctx.workflowSvc.Cancel(r.Id, GetUserFromContext(c)); err != nil {
+		HandleErrorInternalServerError(c, err)
+		return
+	}
+	HandleSuccess(c)
+}
+
+func (ctx *workflowContext) _getRun(c *gin.Context) (r *models.WorkflowRun, err error) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return nil, err
+	}
+	runId, err := primitive.ObjectIDFromHex(c.Param("run_id"))
+	if err != nil {
+		HandleErrorBadRequest(c, err)
+		return nil, err
+	}
+
+	r, err = ctx.modelSvc.GetWorkflowRunById(runId)
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			HandleErrorNotFound(c, err)
+		} else {
+			HandleErrorInternalServerError(c, err)
+		}
+		return nil, err
+	}
+	if r.WorkflowId != id {
+		HandleErrorNotFound(c, errors.ErrorHttpNotFound)
+		return nil, errors.ErrorHttpNotFound
+	}
+
+	return r, nil
+}
+
+func newWorkflowContext() *workflowContext {
+	// context
+	ctx := &workflowContext{}
+
+	// dependency injection
+	c := dig.New()
+	if err := c.Provide(service.GetService); err != nil {
+		panic(err)
+	}
+	if err := c.Provide(workflow.ProvideGetWorkflowService(config.DefaultConfigPath)); err != nil {
+		panic(err)
+	}
+	if err := c.Invoke(func(
+		modelSvc service.ModelService,
+		workflowSvc interfaces.WorkflowService,
+	) {
+		ctx.modelSvc = modelSvc
+		ctx.workflowSvc = workflowSvc
+	}); err != nil {
+		panic(err)
+	}
+
+	return ctx
+}
+
+func newWorkflowController() *workflowController {
+	actions := getWorkflowActions()
+	modelSvc, err := service.GetService()
+	if err != nil {
+		panic(err)
+	}
+
+	ctr := NewListPostActionControllerDelegate(ControllerIdWorkflow, modelSvc.GetBaseService(interfaces.ModelIdWorkflow), actions)
+	d := NewListPostActionControllerDelegate(ControllerIdWorkflow, modelSvc.GetBaseService(interfaces.ModelIdWorkflow), actions)
+	ctx := newWorkflowContext()
+
+	return &workflowController{
+		ListActionControllerDelegate: *ctr,
+		d:                            *d,
+		ctx:                          ctx,
+	}
+}
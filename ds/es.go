@@ -0,0 +1,82 @@
+package ds
+
+import (
+	"context"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/olivere/elastic/v7"
+	"go.mongodb.org/mongo-driver/bson"
+	"time"
+)
+
+// esWriter write records to the index of elasticsearch, of which the ids of
+// documents are ids of records
+type esWriter struct {
+	client *elastic.Client
+	hosts  []string
+	index  string
+}
+
+func newEsWriter(ds *models.DataSource, index string) (w *esWriter, err error) {
+	if len(ds.Hosts) == 0 {
+		return nil, errors.ErrorDataSourceEmptyHosts
+	}
+	opts := []elastic.ClientOptionFunc{
+		elastic.SetURL(ds.Hosts...),
+		elastic.SetSniff(false),
+		elastic.SetHealthcheck(false),
+	}
+	if ds.Username != "" {
+		opts = append(opts, elastic.SetBasicAuth(ds.Username, ds.Password))
+	}
+	client, err := elastic.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &esWriter{
+		client: client,
+		hosts:  ds.Hosts,
+		index:  index,
+	}, nil
+}
+
+func (w *esWriter) test() (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _, err = w.client.Ping(w.hosts[0]).Do(ctx)
+	return err
+}
+
+func (w *esWriter) write(records []bson.M) (err error) {
+	if w.index == "" {
+		return errors.ErrorDataSourceEmptyTarget
+	}
+
+	bulk := w.client.Bulk().Index(w.index)
+	for _, r := range records {
+		// _id is not allowed in the source of documents
+		doc := bson.M{}
+		for k, v := range r {
+			if k != "_id" {
+				doc[k] = v
+			}
+		}
+		bulk.Add(elastic.NewBulkIndexRequest().Id(toStringValue(r["_id"])).Doc(doc))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	res, err := bulk.Do(ctx)
+	if err != nil {
+		return err
+	}
+	if failed := res.Failed(); len(failed) > 0 && failed[0].Error != nil {
+		return errors.NewDataSourceError(failed[0].Error.Reason)
+	}
+	return nil
+}
+
+func (w *esWriter) close() (err error) {
+	w.client.Stop()
+	return nil
+}
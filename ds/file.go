@@ -0,0 +1,137 @@
+package ds
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileWriter append records to the result file in the directory, of which
+// the header of csv is decided by the first batch written to the file
+type fileWriter struct {
+	dir    string
+	name   string
+	format string
+	mu     sync.Mutex
+	keys   []string
+}
+
+func newFileWriter(ds *models.DataSource, name string) (w *fileWriter, err error) {
+	if ds.Path == "" {
+		return nil, errors.ErrorDataSourceEmptyPath
+	}
+	format := ds.Format
+	switch format {
+	case "":
+		format = constants.ResultExportFormatJsonl
+	case constants.ResultExportFormatJsonl, constants.ResultExportFormatCsv:
+	default:
+		return nil, errors.ErrorDataSourceInvalidFormat
+	}
+	return &fileWriter{
+		dir:    ds.Path,
+		name:   name,
+		format: format,
+	}, nil
+}
+
+func (w *fileWriter) test() (err error) {
+	if err := os.MkdirAll(w.dir, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(w.dir, ".test-")
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
+func (w *fileWriter) write(records []bson.M) (err error) {
+	if w.name == "" {
+		return errors.ErrorDataSourceEmptyTarget
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := os.MkdirAll(w.dir, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.getPath(), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if w.format == constants.ResultExportFormatCsv {
+		return w.writeCsv(f, records)
+	}
+	return w.writeJsonl(f, records)
+}
+
+func (w *fileWriter) close() (err error) {
+	return nil
+}
+
+func (w *fileWriter) getPath() (path string) {
+	return filepath.Join(w.dir, w.name+"."+w.format)
+}
+
+func (w *fileWriter) writeJsonl(f *os.File, records []bson.M) (err error) {
+	bw := bufio.NewWriter(f)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func (w *fileWriter) writeCsv(f *os.File, records []bson.M) (err error) {
+	cw := csv.NewWriter(f)
+
+	// header
+	if w.keys == nil {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() > 0 {
+			// header of the existing file
+			w.keys, err = csv.NewReader(f).Read()
+			if err != nil {
+				return err
+			}
+		} else {
+			keys := getRecordKeys(records)
+			if err := cw.Write(keys); err != nil {
+				return err
+			}
+			w.keys = keys
+		}
+	}
+
+	// rows
+	for _, r := range records {
+		row := make([]string, len(w.keys))
+		for i, key := range w.keys {
+			row[i] = toStringValue(r[key])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
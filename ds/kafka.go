@@ -0,0 +1,86 @@
+package ds
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"go.mongodb.org/mongo-driver/bson"
+	"time"
+)
+
+// kafkaWriter write records as json messages to the topic of kafka, keyed by
+// ids of records
+type kafkaWriter struct {
+	w       *kafka.Writer
+	dialer  *kafka.Dialer
+	brokers []string
+}
+
+func newKafkaWriter(ds *models.DataSource, topic string) (w *kafkaWriter, err error) {
+	if len(ds.Hosts) == 0 {
+		return nil, errors.ErrorDataSourceEmptyHosts
+	}
+	w = &kafkaWriter{
+		dialer: &kafka.Dialer{
+			Timeout:   10 * time.Second,
+			DualStack: true,
+		},
+		brokers: ds.Hosts,
+	}
+	if ds.Username != "" {
+		w.dialer.SASLMechanism = plain.Mechanism{
+			Username: ds.Username,
+			Password: ds.Password,
+		}
+	}
+	if topic != "" {
+		// writer without topic is not allowed
+		w.w = kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  ds.Hosts,
+			Topic:    topic,
+			Dialer:   w.dialer,
+			Balancer: &kafka.Hash{},
+		})
+	}
+	return w, nil
+}
+
+func (w *kafkaWriter) test() (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := w.dialer.DialContext(ctx, "tcp", w.brokers[0])
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (w *kafkaWriter) write(records []bson.M) (err error) {
+	if w.w == nil {
+		return errors.ErrorDataSourceEmptyTarget
+	}
+	msgs := make([]kafka.Message, len(records))
+	for i, r := range records {
+		value, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		msgs[i] = kafka.Message{
+			Key:   []byte(toStringValue(r["_id"])),
+			Value: value,
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	return w.w.WriteMessages(ctx, msgs...)
+}
+
+func (w *kafkaWriter) close() (err error) {
+	if w.w == nil {
+		return nil
+	}
+	return w.w.Close()
+}
@@ -0,0 +1,43 @@
+package ds
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/utils"
+	"github.com/crawlab-team/go-trace"
+)
+
+// EncryptPassword encrypt the password of the data source to be saved, which
+// is decrypted only when connecting to the data source
+func EncryptPassword(ds *models.DataSource) (err error) {
+	if ds.Password == "" {
+		return nil
+	}
+	key := utils.GetSecretKey()
+	if key == "" {
+		return errors.ErrorDataSourceNoSecretKey
+	}
+	ds.Password, err = utils.EncryptAes(ds.Password, key)
+	if err != nil {
+		return trace.TraceError(err)
+	}
+	return nil
+}
+
+// decryptPassword copy of the data source with the password decrypted
+func decryptPassword(ds *models.DataSource) (res *models.DataSource, err error) {
+	res = &models.DataSource{}
+	*res = *ds
+	if res.Password == "" {
+		return res, nil
+	}
+	key := utils.GetSecretKey()
+	if key == "" {
+		return nil, errors.ErrorDataSourceNoSecretKey
+	}
+	res.Password, err = utils.DecryptAes(res.Password, key)
+	if err != nil {
+		return nil, trace.TraceError(err)
+	}
+	return res, nil
+}
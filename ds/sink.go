@@ -0,0 +1,170 @@
+package ds
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/crawlab-team/go-trace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"sync"
+)
+
+// sinkWriter writer of records to the data source
+type sinkWriter interface {
+	test() (err error)
+	write(records []bson.M) (err error)
+	close() (err error)
+}
+
+type Sink struct {
+	w          sinkWriter
+	batchSize  int
+	maxRetries int
+}
+
+func (s *Sink) Test() (err error) {
+	return s.w.test()
+}
+
+func (s *Sink) Insert(docs ...interface{}) (err error) {
+	// records with ids, which are generated if absent to keep retries idempotent
+	records := make([]bson.M, len(docs))
+	for i, doc := range docs {
+		records[i], err = toRecord(doc)
+		if err != nil {
+			return trace.TraceError(err)
+		}
+		if _, ok := records[i]["_id"]; !ok {
+			records[i]["_id"] = primitive.NewObjectID()
+		}
+	}
+
+	// write in batches
+	for start := 0; start < len(records); start += s.batchSize {
+		end := start + s.batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		batch := records[start:end]
+		b := backoff.WithMaxRetries(backoff.NewExponentialBackOff(), uint64(s.maxRetries))
+		if err := backoff.Retry(func() error {
+			return s.w.write(batch)
+		}, b); err != nil {
+			return trace.TraceError(err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Sink) Close() (err error) {
+	return s.w.close()
+}
+
+// NewResultSink result sink of the data source writing to the target, which
+// is the table of postgresql or mysql, the index of elasticsearch, the topic
+// of kafka, or the name of the result file
+func NewResultSink(ds *models.DataSource, target string) (sink interfaces.ResultSink, err error) {
+	ds, err = decryptPassword(ds)
+	if err != nil {
+		return nil, err
+	}
+
+	var w sinkWriter
+	switch ds.Type {
+	case constants.DataSourceTypePostgresql, constants.DataSourceTypeMysql:
+		w, err = newSqlWriter(ds, target)
+	case constants.DataSourceTypeElasticsearch:
+		w, err = newEsWriter(ds, target)
+	case constants.DataSourceTypeKafka:
+		w, err = newKafkaWriter(ds, target)
+	case constants.DataSourceTypeFile:
+		w, err = newFileWriter(ds, target)
+	case "", constants.DataSourceTypeMongo:
+		return nil, errors.ErrorDataSourceNotSupported
+	default:
+		return nil, errors.ErrorDataSourceInvalidType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sink{
+		w:          w,
+		batchSize:  ds.BatchSize,
+		maxRetries: ds.MaxRetries,
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = constants.DefaultDataSourceBatchSize
+	}
+	if s.maxRetries <= 0 {
+		s.maxRetries = constants.DefaultDataSourceMaxRetries
+	}
+
+	return s, nil
+}
+
+// TestDataSource check connectivity of the data source
+func TestDataSource(ds *models.DataSource) (err error) {
+	if ds.Type == "" || ds.Type == constants.DataSourceTypeMongo {
+		// results are saved in the database of crawlab
+		return nil
+	}
+	sink, err := NewResultSink(ds, "")
+	if err != nil {
+		return err
+	}
+	defer sink.Close()
+	return sink.Test()
+}
+
+type sinkStoreEntry struct {
+	ds   models.DataSource
+	sink interfaces.ResultSink
+}
+
+var store = sync.Map{}
+
+var storeMu sync.Mutex
+
+// GetResultSink get the result sink of the data source writing to the target,
+// which is re-created if the data source is changed
+func GetResultSink(ds *models.DataSource, target string) (sink interfaces.ResultSink, err error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	key := ds.Id.Hex() + "/" + target
+	res, ok := store.Load(key)
+	if ok {
+		e := res.(*sinkStoreEntry)
+		if reflect.DeepEqual(e.ds, *ds) {
+			return e.sink, nil
+		}
+		if err := e.sink.Close(); err != nil {
+			trace.PrintError(err)
+		}
+		store.Delete(key)
+	}
+
+	sink, err = NewResultSink(ds, target)
+	if err != nil {
+		return nil, err
+	}
+	store.Store(key, &sinkStoreEntry{ds: *ds, sink: sink})
+	return sink, nil
+}
+
+func toRecord(doc interface{}) (r bson.M, err error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,174 @@
+package ds
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sqlDriverPostgresql = "postgres"
+	sqlDriverMysql      = "mysql"
+)
+
+// sqlWriter write records to the table of postgresql or mysql, where only
+// fields matching columns of the table are written
+type sqlWriter struct {
+	db      *sql.DB
+	driver  string
+	table   string
+	mu      sync.Mutex
+	columns map[string]bool
+}
+
+func newSqlWriter(ds *models.DataSource, table string) (w *sqlWriter, err error) {
+	if len(ds.Hosts) == 0 {
+		return nil, errors.ErrorDataSourceEmptyHosts
+	}
+	driver, dsn := getSqlDriverDsn(ds)
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlWriter{
+		db:     db,
+		driver: driver,
+		table:  table,
+	}, nil
+}
+
+func (w *sqlWriter) test() (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return w.db.PingContext(ctx)
+}
+
+func (w *sqlWriter) write(records []bson.M) (err error) {
+	columns, err := w.getColumns()
+	if err != nil {
+		return err
+	}
+
+	// fields matching columns
+	var keys []string
+	for _, key := range getRecordKeys(records) {
+		if columns[key] {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	query, args := getSqlInsertStatement(w.driver, w.table, keys, records)
+	if _, err := w.db.Exec(query, args...); err != nil {
+		// columns are reloaded on retry in case the table is altered
+		w.mu.Lock()
+		w.columns = nil
+		w.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (w *sqlWriter) close() (err error) {
+	return w.db.Close()
+}
+
+// getColumns get columns of the table, which are cached
+func (w *sqlWriter) getColumns() (columns map[string]bool, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.columns != nil {
+		return w.columns, nil
+	}
+	rows, err := w.db.Query(fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", quoteSqlIdentifier(w.driver, w.table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	w.columns = map[string]bool{}
+	for _, name := range names {
+		w.columns[name] = true
+	}
+	return w.columns, nil
+}
+
+func getSqlDriverDsn(ds *models.DataSource) (driver, dsn string) {
+	switch ds.Type {
+	case constants.DataSourceTypeMysql:
+		cfg := mysql.NewConfig()
+		cfg.User = ds.Username
+		cfg.Passwd = ds.Password
+		cfg.Net = "tcp"
+		cfg.Addr = ds.Hosts[0]
+		cfg.DBName = ds.Database
+		cfg.ParseTime = true
+		return sqlDriverMysql, cfg.FormatDSN()
+	default:
+		sslMode := ds.SslMode
+		if sslMode == "" {
+			sslMode = constants.DefaultDataSourceSslMode
+		}
+		u := url.URL{
+			Scheme:   "postgres",
+			Host:     ds.Hosts[0],
+			Path:     "/" + ds.Database,
+			RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+		}
+		if ds.Username != "" {
+			u.User = url.UserPassword(ds.Username, ds.Password)
+		}
+		return sqlDriverPostgresql, u.String()
+	}
+}
+
+// getSqlInsertStatement statement inserting records into the table in a
+// single query, of which missing fields are null
+func getSqlInsertStatement(driver, table string, keys []string, records []bson.M) (query string, args []interface{}) {
+	columns := make([]string, len(keys))
+	for i, key := range keys {
+		columns[i] = quoteSqlIdentifier(driver, key)
+	}
+	rows := make([]string, len(records))
+	for i, r := range records {
+		placeholders := make([]string, len(keys))
+		for j, key := range keys {
+			args = append(args, toPlainValue(r[key]))
+			if driver == sqlDriverPostgresql {
+				placeholders[j] = fmt.Sprintf("$%d", len(args))
+			} else {
+				placeholders[j] = "?"
+			}
+		}
+		rows[i] = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	query = fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES %s",
+		quoteSqlIdentifier(driver, table),
+		strings.Join(columns, ", "),
+		strings.Join(rows, ", "),
+	)
+	return query, args
+}
+
+func quoteSqlIdentifier(driver, name string) (res string) {
+	if driver == sqlDriverMysql {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,58 @@
+package ds
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"time"
+)
+
+// getRecordKeys sorted keys of all records
+func getRecordKeys(records []bson.M) (keys []string) {
+	keySet := map[string]bool{}
+	for _, r := range records {
+		for key := range r {
+			if !keySet[key] {
+				keySet[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toPlainValue convert the value of a record to a scalar, where documents and
+// arrays are converted to json
+func toPlainValue(v interface{}) (res interface{}) {
+	switch v.(type) {
+	case nil, string, bool, int32, int64, int, float64, time.Time:
+		return v
+	case primitive.ObjectID:
+		return v.(primitive.ObjectID).Hex()
+	case primitive.DateTime:
+		return v.(primitive.DateTime).Time()
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// toStringValue convert the value of a record to a string
+func toStringValue(v interface{}) (s string) {
+	switch res := toPlainValue(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return res
+	case time.Time:
+		return res.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(res)
+	}
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// AuditLogFilter query of audit logs filtered by model, user, method, changed
+// field and time range
+type AuditLogFilter struct {
+	Col     string    `form:"col"`
+	ModelId string    `form:"model_id"`
+	UserId  string    `form:"user_id"`
+	Method  string    `form:"method"`
+	Key     string    `form:"key"`
+	StartTs time.Time `form:"start_ts" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTs   time.Time `form:"end_ts" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -1,12 +1,32 @@
 package entity
 
+import vcs "github.com/crawlab-team/crawlab-vcs"
+
 type GitPayload struct {
 	Paths         []string `json:"paths"`
 	CommitMessage string   `json:"commit_message"`
 	Branch        string   `json:"branch"`
 	Tag           string   `json:"tag"`
+	Commit        string   `json:"commit"`
 }
 
 type GitConfig struct {
 	Url string `json:"url" bson:"url"`
 }
+
+// GitCommit commit of the spider repo with branches and tags pointing to it
+type GitCommit struct {
+	SpiderVersion
+	Refs []vcs.GitRef `json:"refs"`
+}
+
+type GitBranches struct {
+	Current string       `json:"current"` // current branch, empty if HEAD is detached
+	Head    string       `json:"head"`    // commit hash of HEAD
+	Local   []vcs.GitRef `json:"local"`
+	Remote  []vcs.GitRef `json:"remote"` // remote-tracking branches of the upstream remote
+}
+
+type GitSshKeyPayload struct {
+	PrivateKey string `json:"private_key"` // private key in PEM
+}
@@ -1,5 +1,7 @@
 package entity
 
+import "time"
+
 type SpiderType struct {
 	Type  string `json:"type" bson:"_id"`
 	Count int    `json:"count" bson:"count"`
@@ -15,3 +17,40 @@ type ScrapyItem struct {
 	Name   string   `json:"name"`
 	Fields []string `json:"fields"`
 }
+
+type ScrapySpider struct {
+	Name      string `json:"name"`
+	ClassName string `json:"class_name"`
+	Path      string `json:"path"` // path of the spider file in the spider fs
+}
+
+type ScrapyPipeline struct {
+	Name     string `json:"name"` // import path, e.g. tutorial.pipelines.TutorialPipeline
+	Enabled  bool   `json:"enabled"`
+	Priority int    `json:"priority"` // order in ITEM_PIPELINES
+}
+
+// ScrapyPayload payload to scaffold a scrapy project or spider
+type ScrapyPayload struct {
+	Name     string `json:"name"`
+	Domain   string `json:"domain"`   // allowed domain of the spider
+	Template string `json:"template"` // constants.ScrapySpiderTemplate*, basic by default
+}
+
+// SpiderVersion version (commit) of spider files with changes from the previous version
+type SpiderVersion struct {
+	Hash        string              `json:"hash"`
+	Msg         string              `json:"msg"`
+	AuthorName  string              `json:"author_name"`
+	AuthorEmail string              `json:"author_email"`
+	Timestamp   time.Time           `json:"timestamp"`
+	IsLatest    bool                `json:"is_latest"`
+	Files       []SpiderVersionFile `json:"files"`
+	Diff        string              `json:"diff,omitempty"` // unified diff, only for a single version
+}
+
+type SpiderVersionFile struct {
+	Name      string `json:"name"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
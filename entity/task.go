@@ -2,7 +2,10 @@ package entity
 
 import (
 	"encoding/json"
+	"github.com/luke513009828/crawlab-core/interfaces"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"regexp"
+	"time"
 )
 
 type TaskMessage struct {
@@ -24,7 +27,59 @@ type TaskRunOptions struct {
 }
 
 type StreamMessageTaskData struct {
-	TaskId  primitive.ObjectID `json:"task_id"`
-	Records []Result           `json:"data"`
-	Logs    []string           `json:"logs"`
+	TaskId   primitive.ObjectID       `json:"task_id"`
+	Records  []Result                 `json:"data"`
+	Logs     []string                 `json:"logs"`
+	LogLines []interfaces.TaskLogLine `json:"log_lines"` // log lines with metadata
+}
+
+// TaskLogFilter query of task logs filtered by levels, regex pattern and time range
+type TaskLogFilter struct {
+	Levels  []string  `form:"level"`
+	Pattern string    `form:"pattern"`
+	StartTs time.Time `form:"start_ts" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTs   time.Time `form:"end_ts" time_format:"2006-01-02T15:04:05Z07:00"`
+
+	// internals
+	re *regexp.Regexp
+}
+
+func (f *TaskLogFilter) IsEmpty() bool {
+	return len(f.Levels) == 0 && f.Pattern == "" && f.StartTs.IsZero() && f.EndTs.IsZero()
+}
+
+// Compile compile the regex pattern, which should be called before Match
+func (f *TaskLogFilter) Compile() (err error) {
+	if f.Pattern == "" {
+		return nil
+	}
+	f.re, err = regexp.Compile(f.Pattern)
+	return err
+}
+
+// Match whether the log line matches the filter, where lines without
+// timestamps do not match any time range
+func (f *TaskLogFilter) Match(l interfaces.TaskLogLine) bool {
+	if len(f.Levels) > 0 {
+		matched := false
+		for _, level := range f.Levels {
+			if level == l.Level {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.re != nil && !f.re.MatchString(l.Content) {
+		return false
+	}
+	if !f.StartTs.IsZero() && (l.Ts.IsZero() || l.Ts.Before(f.StartTs)) {
+		return false
+	}
+	if !f.EndTs.IsZero() && (l.Ts.IsZero() || l.Ts.After(f.EndTs)) {
+		return false
+	}
+	return true
 }
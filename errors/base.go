@@ -6,23 +6,27 @@ import (
 )
 
 const (
-	ErrorPrefixController = "controller"
-	ErrorPrefixModel      = "model"
-	ErrorPrefixFilter     = "filter"
-	ErrorPrefixHttp       = "http"
-	ErrorPrefixGrpc       = "grpc"
-	ErrorPrefixNode       = "node"
-	ErrorPrefixInject     = "inject"
-	ErrorPrefixSpider     = "spider"
-	ErrorPrefixFs         = "fs"
-	ErrorPrefixTask       = "task"
-	ErrorPrefixSchedule   = "schedule"
-	ErrorPrefixUser       = "user"
-	ErrorPrefixStats      = "stats"
-	ErrorPrefixEvent      = "event"
-	ErrorPrefixPlugin     = "plugin"
-	ErrorPrefixProcess    = "process"
-	ErrorPrefixGit        = "git"
+	ErrorPrefixController   = "controller"
+	ErrorPrefixModel        = "model"
+	ErrorPrefixFilter       = "filter"
+	ErrorPrefixHttp         = "http"
+	ErrorPrefixGrpc         = "grpc"
+	ErrorPrefixNode         = "node"
+	ErrorPrefixInject       = "inject"
+	ErrorPrefixSpider       = "spider"
+	ErrorPrefixFs           = "fs"
+	ErrorPrefixTask         = "task"
+	ErrorPrefixSchedule     = "schedule"
+	ErrorPrefixUser         = "user"
+	ErrorPrefixStats        = "stats"
+	ErrorPrefixEvent        = "event"
+	ErrorPrefixPlugin       = "plugin"
+	ErrorPrefixProcess      = "process"
+	ErrorPrefixGit          = "git"
+	ErrorPrefixWorkflow     = "workflow"
+	ErrorPrefixNotification = "notification"
+	ErrorPrefixResult       = "result"
+	ErrorPrefixDataSource   = "data_source"
 )
 
 type ErrorPrefix string
@@ -0,0 +1,15 @@
+package errors
+
+func NewDataSourceError(msg string) (err error) {
+	return NewError(ErrorPrefixDataSource, msg)
+}
+
+var (
+	ErrorDataSourceInvalidType   = NewDataSourceError("invalid type")
+	ErrorDataSourceEmptyHosts    = NewDataSourceError("empty hosts")
+	ErrorDataSourceEmptyPath     = NewDataSourceError("empty path")
+	ErrorDataSourceEmptyTarget   = NewDataSourceError("empty target")
+	ErrorDataSourceInvalidFormat = NewDataSourceError("invalid file format")
+	ErrorDataSourceNotSupported  = NewDataSourceError("not supported by the mongo data source")
+	ErrorDataSourceNoSecretKey   = NewDataSourceError("no secret key configured (secret.key) to encrypt passwords")
+)
@@ -7,4 +7,6 @@ func NewGitError(msg string) (err error) {
 var (
 	ErrorGitInvalidAuthType = NewGitError("invalid auth type")
 	ErrorGitNoMainBranch    = NewGitError("no main branch")
+	ErrorGitInvalidSshKey   = NewGitError("invalid ssh key")
+	ErrorGitNoSecretKey     = NewGitError("no secret key configured (secret.key) to encrypt ssh keys")
 )
@@ -0,0 +1,12 @@
+package errors
+
+func NewNotificationError(msg string) (err error) {
+	return NewError(ErrorPrefixNotification, msg)
+}
+
+var (
+	ErrorNotificationInvalidType    = NewNotificationError("invalid type")
+	ErrorNotificationEmptyWebhook   = NewNotificationError("empty webhook")
+	ErrorNotificationEmptyMail      = NewNotificationError("empty mail recipients")
+	ErrorNotificationMailNotEnabled = NewNotificationError("mail server not configured")
+)
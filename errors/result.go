@@ -0,0 +1,14 @@
+package errors
+
+func NewResultError(msg string) (err error) {
+	return NewError(ErrorPrefixResult, msg)
+}
+
+var (
+	ErrorResultInvalidExportFormat = NewResultError("invalid export format")
+	ErrorResultExportNotFinished   = NewResultError("export not finished")
+	ErrorResultExportInterrupted   = NewResultError("export interrupted by restart")
+	ErrorResultExportTooManyRows   = NewResultError("too many results to export in xlsx")
+	ErrorResultEmptyFieldKey       = NewResultError("empty field key")
+	ErrorResultInvalidFieldType    = NewResultError("invalid field type")
+)
@@ -7,4 +7,18 @@ func NewSpiderError(msg string) (err error) {
 var (
 	ErrorSpiderMissingRequiredOption = NewSpiderError("missing required option")
 	ErrorSpiderForbidden             = NewSpiderError("forbidden")
+	ErrorSpiderEmptyNodeTags         = NewSpiderError("empty node tags")
+	ErrorSpiderInvalidVersion        = NewSpiderError("invalid version")
+	ErrorSpiderVersionNotFound       = NewSpiderError("version not found")
+	ErrorSpiderInvalidArchive        = NewSpiderError("invalid archive")
+	ErrorSpiderGitNotFound           = NewSpiderError("git not found")
+	ErrorSpiderGitRefNotFound        = NewSpiderError("git ref not found")
+	ErrorSpiderGitDetachedAutoSync   = NewSpiderError("cannot check out a tag or commit while git auto sync is enabled")
+	ErrorSpiderScrapyProjectNotFound = NewSpiderError("scrapy project not found")
+	ErrorSpiderScrapyProjectExists   = NewSpiderError("scrapy project already exists")
+	ErrorSpiderScrapySpiderExists    = NewSpiderError("scrapy spider already exists")
+	ErrorSpiderInvalidScrapyName     = NewSpiderError("invalid scrapy name")
+	ErrorSpiderInvalidSpiderfile     = NewSpiderError("invalid spiderfile")
+	ErrorSpiderInvalidScrapyTemplate = NewSpiderError("invalid scrapy spider template")
+	ErrorSpiderInvalidScrapySetting  = NewSpiderError("invalid scrapy setting")
 )
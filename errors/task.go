@@ -13,4 +13,5 @@ var (
 	ErrorTaskForbidden          = NewTaskError("forbidden")
 	ErrorTaskNoAvailableRunners = NewTaskError("no available runner")
 	ErrorTaskEmptySpiderId      = NewTaskError("empty spider id")
+	ErrorTaskReachedMaxErrors   = NewTaskError("reached max errors")
 )
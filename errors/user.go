@@ -13,4 +13,9 @@ var (
 	ErrorUserMissingRequiredFields = NewUserError("missing required fields")
 	ErrorUserUnauthorized          = NewUserError("unauthorized")
 	ErrorUserInvalidPassword       = NewUserError("invalid password (length must be no less than 5)")
+	ErrorUserTokenRevoked          = NewUserError("token revoked")
+	ErrorUserTokenExpired          = NewUserError("token expired")
+	ErrorUserTokenInvalidScope     = NewUserError("invalid token scope")
+	ErrorUserTokenImmutableField   = NewUserError("token scopes, expiration and owner cannot be changed")
+	ErrorUserForbidden             = NewUserError("forbidden")
 )
@@ -0,0 +1,17 @@
+package errors
+
+func NewWorkflowError(msg string) (err error) {
+	return NewError(ErrorPrefixWorkflow, msg)
+}
+
+var (
+	ErrorWorkflowEmptyNodes         = NewWorkflowError("empty nodes")
+	ErrorWorkflowEmptyNodeKey       = NewWorkflowError("empty node key")
+	ErrorWorkflowDuplicateNodeKey   = NewWorkflowError("duplicate node key")
+	ErrorWorkflowEmptySpiderId      = NewWorkflowError("empty spider id")
+	ErrorWorkflowInvalidNodeType    = NewWorkflowError("invalid node type")
+	ErrorWorkflowInvalidEdge        = NewWorkflowError("invalid edge")
+	ErrorWorkflowInvalidCondition   = NewWorkflowError("invalid edge condition")
+	ErrorWorkflowCyclicDependencies = NewWorkflowError("cyclic dependencies")
+	ErrorWorkflowRunNotCancellable  = NewWorkflowError("run not cancellable")
+)
@@ -286,8 +286,8 @@ func (svc *Service) Commit(msg string) (err error) {
 	//	return err
 	//}
 
-	// commit
-	if err := svc.gitClient.CommitAll(msg); err != nil {
+	// commit (including deleted files)
+	if err := svc.gitClient.CommitAll(msg, vcs.WithAll(true)); err != nil {
 		return trace.TraceError(err)
 	}
 
@@ -75,6 +75,24 @@ func (b *ModelDelegateBinder) Bind() (res interface{}, err error) {
 		return b.process(&m.PluginStatus)
 	case interfaces.ModelIdGit:
 		return b.process(&m.Git)
+	case interfaces.ModelIdWorkflow:
+		return b.process(&m.Workflow)
+	case interfaces.ModelIdWorkflowRun:
+		return b.process(&m.WorkflowRun)
+	case interfaces.ModelIdNotificationSetting:
+		return b.process(&m.NotificationSetting)
+	case interfaces.ModelIdNotificationDelivery:
+		return b.process(&m.NotificationDelivery)
+	case interfaces.ModelIdAuditLog:
+		return b.process(&m.AuditLog)
+	case interfaces.ModelIdResultExport:
+		return b.process(&m.ResultExport)
+	case interfaces.ModelIdDataField:
+		return b.process(&m.DataField)
+	case interfaces.ModelIdGitSyncLog:
+		return b.process(&m.GitSyncLog)
+	case interfaces.ModelIdGitSshKey:
+		return b.process(&m.GitSshKey)
 	default:
 		return nil, errors.ErrorModelInvalidModelId
 	}
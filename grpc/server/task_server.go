@@ -87,6 +87,9 @@ func (svr TaskServer) handleInsertLogs(msg *grpc.StreamMessage) (err error) {
 	if err != nil {
 		return err
 	}
+	if len(data.LogLines) > 0 {
+		return svr.statsSvc.InsertLogLines(data.TaskId, data.LogLines...)
+	}
 	return svr.statsSvc.InsertLogs(data.TaskId, data.Logs...)
 }
 
@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ConfigSpiderService interface {
+	WithConfigPath
+	// InitSpiderfile save the default Spiderfile of the configurable spider if
+	// not exists, and generate scrapy project files from the Spiderfile
+	InitSpiderfile(id primitive.ObjectID) (err error)
+	// Validate the content of a Spiderfile
+	Validate(data []byte) (err error)
+	// SaveSpiderfile validate and save the Spiderfile of the configurable
+	// spider, and regenerate scrapy project files
+	SaveSpiderfile(id primitive.ObjectID, data []byte) (err error)
+	// Generate scrapy project files into the spider fs from its Spiderfile
+	Generate(id primitive.ObjectID) (err error)
+	// Regenerate scrapy project files of the spider if it is configurable and
+	// has a Spiderfile, which is called wherever spider files are changed in
+	// bulk or renamed, e.g. git pull, import, upload and rename
+	Regenerate(id primitive.ObjectID) (err error)
+}
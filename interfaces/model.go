@@ -35,31 +35,49 @@ const (
 	ModelIdExtraValue
 	ModelIdPluginStatus
 	ModelIdGit
+	ModelIdWorkflow
+	ModelIdWorkflowRun
+	ModelIdNotificationSetting
+	ModelIdNotificationDelivery
+	ModelIdAuditLog
+	ModelIdResultExport
+	ModelIdDataField
+	ModelIdGitSyncLog
+	ModelIdGitSshKey
 )
 
 const (
-	ModelColNameArtifact       = "artifacts"
-	ModelColNameTag            = "tags"
-	ModelColNameNode           = "nodes"
-	ModelColNameProject        = "projects"
-	ModelColNameSpider         = "spiders"
-	ModelColNameTask           = "tasks"
-	ModelColNameJob            = "jobs"
-	ModelColNameSchedule       = "schedules"
-	ModelColNameUser           = "users"
-	ModelColNameSetting        = "settings"
-	ModelColNameToken          = "tokens"
-	ModelColNameVariable       = "variables"
-	ModelColNameTaskQueue      = "task_queue"
-	ModelColNameTaskStat       = "task_stats"
-	ModelColNamePlugin         = "plugins"
-	ModelColNameSpiderStat     = "spider_stats"
-	ModelColNameDataSource     = "data_sources"
-	ModelColNameDataCollection = "data_collections"
-	ModelColNamePasswords      = "passwords"
-	ModelColNameExtraValues    = "extra_values"
-	ModelColNamePluginStatus   = "plugin_status"
-	ModelColNameGit            = "gits"
+	ModelColNameArtifact             = "artifacts"
+	ModelColNameTag                  = "tags"
+	ModelColNameNode                 = "nodes"
+	ModelColNameProject              = "projects"
+	ModelColNameSpider               = "spiders"
+	ModelColNameTask                 = "tasks"
+	ModelColNameJob                  = "jobs"
+	ModelColNameSchedule             = "schedules"
+	ModelColNameUser                 = "users"
+	ModelColNameSetting              = "settings"
+	ModelColNameToken                = "tokens"
+	ModelColNameVariable             = "variables"
+	ModelColNameTaskQueue            = "task_queue"
+	ModelColNameTaskStat             = "task_stats"
+	ModelColNamePlugin               = "plugins"
+	ModelColNameSpiderStat           = "spider_stats"
+	ModelColNameDataSource           = "data_sources"
+	ModelColNameDataCollection       = "data_collections"
+	ModelColNamePasswords            = "passwords"
+	ModelColNameExtraValues          = "extra_values"
+	ModelColNamePluginStatus         = "plugin_status"
+	ModelColNameGit                  = "gits"
+	ModelColNameWorkflow             = "workflows"
+	ModelColNameWorkflowRun          = "workflow_runs"
+	ModelColNameNotificationSetting  = "notification_settings"
+	ModelColNameNotificationDelivery = "notification_deliveries"
+	ModelColNameAuditLog             = "audit_logs"
+	ModelColNameResultExport         = "result_exports"
+	ModelColNameDataField            = "data_fields"
+	ModelColNameGitSyncLog           = "git_sync_logs"
+	ModelColNameGitSshKey            = "git_ssh_keys"
 )
 
 type ModelWithTags interface {
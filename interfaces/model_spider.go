@@ -18,4 +18,5 @@ type Spider interface {
 	SetParam(param string)
 	GetPriority() (p int)
 	SetPriority(p int)
+	GetIsLongTask() (ok bool)
 }
@@ -17,6 +17,11 @@ type Task interface {
 	GetCmd() (cmd string)
 	GetParam() (param string)
 	GetPriority() (p int)
+	GetTimeout() (timeout int)
 	GetUserId() (id primitive.ObjectID)
 	SetUserId(id primitive.ObjectID)
+	GetVersion() (version string)
+	GetIsVersionPinned() (ok bool)
+	GetRestartCount() (c int)
+	SetRestartCount(c int)
 }
@@ -20,4 +20,6 @@ type TaskStat interface {
 	SetResultCount(c int64)
 	GetErrorLogCount() (c int64)
 	SetErrorLogCount(c int64)
+	GetTimedOut() (ok bool)
+	SetTimedOut(ok bool)
 }
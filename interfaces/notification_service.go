@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationService interface {
+	WithConfigPath
+	Module
+	// Notify send notifications of the ended task to matched notification
+	// settings and the web hook of its spider, which are sent once per task
+	Notify(taskId primitive.ObjectID) (err error)
+}
@@ -12,4 +12,19 @@ type ResultService interface {
 	GetList(query bson.M, opts *mongo.FindOptions) (results []Result, err error)
 	Count(query bson.M) (total int, err error)
 	Insert(docs ...interface{}) (err error)
+	// InsertWithDedup insert docs deduplicated by keys of dedup fields (or
+	// content hash if no fields) according to the dedup method
+	InsertWithDedup(opts *ResultDedupOptions, docs ...interface{}) (stats ResultInsertStats, err error)
+}
+
+type ResultDedupOptions struct {
+	Method string   // constants.DedupMethod*
+	Fields []string // dedup key fields, content hash is used if empty
+}
+
+type ResultInsertStats struct {
+	Inserted int // new records inserted
+	Skipped  int // duplicated records skipped
+	Updated  int // existing records overwritten or versioned
+	Errors   int // records not conforming to the schema, which are flagged or rejected
 }
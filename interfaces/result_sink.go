@@ -0,0 +1,10 @@
+package interfaces
+
+// ResultSink destination of results of a data source other than MongoDB
+type ResultSink interface {
+	// Test check connectivity of the data source
+	Test() (err error)
+	// Insert write docs in batches, of which each is retried on failure
+	Insert(docs ...interface{}) (err error)
+	Close() (err error)
+}
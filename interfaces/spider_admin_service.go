@@ -12,8 +12,13 @@ type SpiderAdminService interface {
 	// Schedule a new task of the spider and return task id
 	ScheduleWithTaskId(id primitive.ObjectID, opts *SpiderRunOptions) (taskIds []primitive.ObjectID, err error)
 
-	// Clone the spider
-	Clone(id primitive.ObjectID, opts *SpiderCloneOptions) (err error)
-	// Delete the spider
-	Delete(id primitive.ObjectID) (err error)
+	// Clone the spider with its files and schedules, and return id of the clone
+	Clone(id primitive.ObjectID, opts *SpiderCloneOptions, args ...interface{}) (cloneId primitive.ObjectID, err error)
+	// Delete the spider with its files, schedules and stats
+	Delete(id primitive.ObjectID, opts *SpiderDeleteOptions, args ...interface{}) (err error)
+
+	// Export the spider with its files and schedules to a zip file
+	Export(id primitive.ObjectID) (filePath string, err error)
+	// Import a spider from a zip file exported by Export, and return id of the spider
+	Import(filePath string, args ...interface{}) (id primitive.ObjectID, err error)
 }
@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -16,9 +17,18 @@ type SpiderFsService interface {
 	Delete(path string) (err error)
 	Copy(path, newPath string) (err error)
 	Commit(msg string) (err error)
+	GetVersion() (version string, err error)
+	IsDirty() (res bool, err error)
+	GetVersionCommit(version string) (commit *object.Commit, err error)
+	SyncVersionToFs(version string) (err error)
+	SyncVersionToWorkspace(version string) (err error)
+	PruneVersions(keep []string) (err error)
+	Rollback(version, msg string) (err error)
 	GetFsPath() (res string)
 	GetWorkspacePath() (res string)
 	GetRepoPath() (res string)
+	GetVersionFsPath(version string) (res string)
+	GetVersionWorkspacePath(version string) (res string)
 	SetFsPathBase(path string)
 	SetWorkspacePathBase(path string)
 	SetRepoPathBase(path string)
@@ -0,0 +1,35 @@
+package interfaces
+
+import (
+	vcs "github.com/crawlab-team/crawlab-vcs"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SpiderGitSyncService interface {
+	WithConfigPath
+	Module
+	// GetGitClient git client of the spider repo with http auth of its git settings
+	GetGitClient(id primitive.ObjectID, fsSvc SpiderFsService) (gitClient *vcs.GitClient, err error)
+	// GetAuth auth of the git remote with http credentials or ssh key of git
+	// settings, nil if no credentials
+	GetAuth(id primitive.ObjectID) (auth transport.AuthMethod, err error)
+	// GetRemoteRefs branches and tags of the git remote
+	GetRemoteRefs(id primitive.ObjectID) (gitRefs []vcs.GitRef, err error)
+	// Fetch branches and tags of the git remote into the spider repo
+	Fetch(id primitive.ObjectID) (err error)
+	// Sync pull the branch of the git remote into the spider repo and fs, and
+	// record the result in the sync history and Spider.GitSyncError
+	Sync(id primitive.ObjectID, opts *SpiderGitSyncOptions, args ...interface{}) (logId primitive.ObjectID, err error)
+	// Checkout check out the branch, tag or commit in the spider repo and fs,
+	// of which the branch is pulled by following syncs
+	Checkout(id primitive.ObjectID, opts *SpiderGitRefOptions, args ...interface{}) (err error)
+	// Reset hard reset the current branch of the spider repo and fs to the
+	// branch, tag or commit
+	Reset(id primitive.ObjectID, opts *SpiderGitRefOptions) (err error)
+	// GenerateSshKey generate an ssh key of the git repo, replacing the existing one
+	GenerateSshKey(id primitive.ObjectID, args ...interface{}) (err error)
+	// SaveSshKey save the uploaded private key as the ssh key of the git repo,
+	// replacing the existing one
+	SaveSshKey(id primitive.ObjectID, privateKey string, args ...interface{}) (err error)
+}
@@ -3,16 +3,36 @@ package interfaces
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
 type SpiderRunOptions struct {
-	Mode       string               `json:"mode"`
-	NodeIds    []primitive.ObjectID `json:"node_ids"`
-	NodeTags   []string             `json:"node_tags"`
-	Cmd        string               `json:"cmd"`
-	Param      string               `json:"param"`
-	ScheduleId primitive.ObjectID   `json:"schedule_id"`
-	Priority   int                  `json:"priority"`
-	UserId     primitive.ObjectID   `json:"-"`
+	Mode          string               `json:"mode"`
+	NodeIds       []primitive.ObjectID `json:"node_ids"`
+	NodeTags      []string             `json:"node_tags"`
+	NodeTagsMatch string               `json:"node_tags_match"`
+	Cmd           string               `json:"cmd"`
+	Param         string               `json:"param"`
+	ScheduleId    primitive.ObjectID   `json:"schedule_id"`
+	Priority      int                  `json:"priority"`
+	Timeout       int                  `json:"timeout"`
+	Envs          map[string]string    `json:"envs"`
+	Version       string               `json:"version"` // commit hash of the version of spider files to run, the latest files if empty
+	UserId        primitive.ObjectID   `json:"-"`
 }
 
 type SpiderCloneOptions struct {
-	Name string
+	Name    string `json:"name"`     // name of the clone, "<name> (copy)" by default
+	ColName string `json:"col_name"` // data collection of the clone, the same as the spider if empty
+}
+
+type SpiderDeleteOptions struct {
+	DeleteDataCollection bool `json:"delete_data_collection" form:"delete_data_collection"` // whether to drop the data collection if no other spiders use it
+}
+
+type SpiderGitSyncOptions struct {
+	Branch  string `json:"branch"` // branch to pull, Spider.GitBranch or the current branch if empty
+	Trigger string `json:"-"`      // constants.GitSyncTrigger*
+}
+
+type SpiderGitRefOptions struct {
+	Branch string `json:"branch"`
+	Tag    string `json:"tag"`
+	Commit string `json:"commit"`
 }
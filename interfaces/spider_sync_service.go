@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sync"
 )
 
 type SpiderSyncService interface {
@@ -13,4 +14,5 @@ type SpiderSyncService interface {
 	ForceGetFsService(id primitive.ObjectID) (fsSvc SpiderFsService, err error)
 	SyncToFs(id primitive.ObjectID) (err error)
 	SyncToWorkspace(id primitive.ObjectID) (err error)
+	GetLock(id primitive.ObjectID) (mu *sync.Mutex)
 }
@@ -12,5 +12,7 @@ type TaskRunner interface {
 	Dispose() (err error)
 	SetLogDriverType(driverType string)
 	SetSubscribeTimeout(timeout time.Duration)
+	SetLongTaskMaxErrors(maxErrors int)
+	SetLongTaskBackoffMaxInterval(interval time.Duration)
 	GetTaskId() (id primitive.ObjectID)
 }
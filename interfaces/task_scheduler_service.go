@@ -21,4 +21,10 @@ type TaskSchedulerService interface {
 	Cancel(id primitive.ObjectID, args ...interface{}) (err error)
 	// SetInterval set the interval or duration between two adjacent fetches
 	SetInterval(interval time.Duration)
+	// SetBatchSize set the max number of task queue items to fetch in each dequeue
+	SetBatchSize(size int)
+	// SetMaxRunningPerSpider set the max number of running tasks of each spider, 0 means no limit
+	SetMaxRunningPerSpider(max int)
+	// SetMaxRunningPerUser set the max number of running tasks of each user, 0 means no limit
+	SetMaxRunningPerUser(max int)
 }
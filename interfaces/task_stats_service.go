@@ -1,9 +1,26 @@
 package interfaces
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
 type TaskStatsService interface {
 	TaskBaseService
 	InsertData(id primitive.ObjectID, records ...interface{}) (err error)
 	InsertLogs(id primitive.ObjectID, logs ...string) (err error)
+	// InsertLogLines insert log lines with metadata, and count error lines
+	// into TaskStat.ErrorLogCount
+	InsertLogLines(id primitive.ObjectID, lines ...TaskLogLine) (err error)
+	// SubscribeLogs subscribe to log lines of the task inserted from now on,
+	// which should be unsubscribed once done
+	SubscribeLogs(id primitive.ObjectID) (ch <-chan TaskLogLine, unsubscribe func())
+}
+
+type TaskLogLine struct {
+	Number  int       `json:"n"` // 0-based number of the line in the task log, not stored
+	Content string    `json:"line"`
+	Ts      time.Time `json:"ts"`
+	Stream  string    `json:"stream,omitempty"` // constants.LogStream*
+	Level   string    `json:"level,omitempty"`  // constants.LogLevel*
 }
@@ -13,7 +13,13 @@ type UserService interface {
 	Create(opts *UserCreateOptions, args ...interface{}) (err error)
 	Login(opts *UserLoginOptions) (token string, u User, err error)
 	CheckToken(token string) (u User, err error)
+	// CheckTokenWithScopes check the token and get scopes of it, which are nil
+	// for login tokens with full access
+	CheckTokenWithScopes(token string) (u User, scopes []string, err error)
 	ChangePassword(id primitive.ObjectID, password string, args ...interface{}) (err error)
 	MakeToken(user User) (tokenStr string, err error)
+	// MakeApiToken make a token of the user for API access, which is revoked
+	// once the Token of opts.Id is deleted
+	MakeApiToken(user User, opts *UserTokenOptions) (tokenStr string, err error)
 	GetCurrentUser(c *gin.Context) (u User, err error)
 }
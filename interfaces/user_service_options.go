@@ -1,5 +1,10 @@
 package interfaces
 
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
 type UserCreateOptions struct {
 	Username string
 	Password string
@@ -11,3 +16,8 @@ type UserLoginOptions struct {
 	Username string
 	Password string
 }
+
+type UserTokenOptions struct {
+	Id       primitive.ObjectID // Token.Id, which is checked for revocation if not empty
+	ExpireTs time.Time          // never expires if empty
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WorkflowService interface {
+	WithConfigPath
+	Module
+	// Run start a new run of the workflow and enqueue tasks of its root nodes
+	Run(id primitive.ObjectID, args ...interface{}) (runId primitive.ObjectID, err error)
+	// Cancel cancel the workflow run and its running tasks
+	Cancel(runId primitive.ObjectID, args ...interface{}) (err error)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/luke513009828/crawlab-core/controllers"
 	"github.com/luke513009828/crawlab-core/errors"
 	"github.com/luke513009828/crawlab-core/user"
+	"github.com/luke513009828/crawlab-core/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,13 +16,19 @@ func AuthorizationMiddleware() gin.HandlerFunc {
 		tokenStr := c.GetHeader("Authorization")
 
 		// validate token
-		u, err := userSvc.CheckToken(tokenStr)
+		u, scopes, err := userSvc.CheckTokenWithScopes(tokenStr)
 		if err != nil {
 			// validation failed, return error response
 			controllers.HandleErrorUnauthorized(c, errors.ErrorHttpUnauthorized)
 			return
 		}
 
+		// validate token scopes
+		if !utils.IsTokenScopeAllowed(scopes, c.Request.Method, c.FullPath()) {
+			controllers.HandleErrorForbidden(c, errors.ErrorUserForbidden)
+			return
+		}
+
 		// set user in context
 		c.Set(constants.ContextUser, u)
 
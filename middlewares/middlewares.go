@@ -7,7 +7,7 @@ func InitMiddlewares(app *gin.Engine) (err error) {
 	app.Use(gin.Logger())
 
 	// recovery from panics
-	app.Use(gin.Recovery())
+	app.Use(RecoveryMiddleware())
 
 	// cors
 	app.Use(CORSMiddleware())
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"github.com/luke513009828/crawlab-core/controllers"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/gin-gonic/gin"
+)
+
+func RbacMiddleware(id controllers.ControllerId) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// check permission of current user
+		if err := controllers.CheckPermission(c, id); err != nil {
+			if err == errors.ErrorUserForbidden {
+				controllers.HandleErrorForbidden(c, err)
+			} else {
+				controllers.HandleErrorInternalServerError(c, err)
+			}
+			return
+		}
+
+		// permitted
+		c.Next()
+	}
+}
@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RecoveryMiddleware recover from panics with status 500, except that
+// http.ErrAbortHandler is panicked again for the server to abort the
+// response, e.g. of a file failing to be streamed
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, err interface{}) {
+		if err == http.ErrAbortHandler {
+			panic(err)
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
@@ -71,6 +71,24 @@ func (b *BasicBinder) Bind() (res interfaces.Model, err error) {
 		return b.Process(&m.PluginStatus)
 	case interfaces.ModelIdGit:
 		return b.Process(&m.Git)
+	case interfaces.ModelIdWorkflow:
+		return b.Process(&m.Workflow)
+	case interfaces.ModelIdWorkflowRun:
+		return b.Process(&m.WorkflowRun)
+	case interfaces.ModelIdNotificationSetting:
+		return b.Process(&m.NotificationSetting)
+	case interfaces.ModelIdNotificationDelivery:
+		return b.Process(&m.NotificationDelivery)
+	case interfaces.ModelIdAuditLog:
+		return b.Process(&m.AuditLog)
+	case interfaces.ModelIdResultExport:
+		return b.Process(&m.ResultExport)
+	case interfaces.ModelIdDataField:
+		return b.Process(&m.DataField)
+	case interfaces.ModelIdGitSyncLog:
+		return b.Process(&m.GitSyncLog)
+	case interfaces.ModelIdGitSshKey:
+		return b.Process(&m.GitSshKey)
 	default:
 		return nil, errors.ErrorModelInvalidModelId
 	}
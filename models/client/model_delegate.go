@@ -62,6 +62,24 @@ func NewModelDelegate(doc interfaces.Model, opts ...ModelDelegateOption) interfa
 		return newModelDelegate(interfaces.ModelIdPluginStatus, doc, opts...)
 	case *models.Git:
 		return newModelDelegate(interfaces.ModelIdGit, doc, opts...)
+	case *models.Workflow:
+		return newModelDelegate(interfaces.ModelIdWorkflow, doc, opts...)
+	case *models.WorkflowRun:
+		return newModelDelegate(interfaces.ModelIdWorkflowRun, doc, opts...)
+	case *models.NotificationSetting:
+		return newModelDelegate(interfaces.ModelIdNotificationSetting, doc, opts...)
+	case *models.NotificationDelivery:
+		return newModelDelegate(interfaces.ModelIdNotificationDelivery, doc, opts...)
+	case *models.AuditLog:
+		return newModelDelegate(interfaces.ModelIdAuditLog, doc, opts...)
+	case *models.ResultExport:
+		return newModelDelegate(interfaces.ModelIdResultExport, doc, opts...)
+	case *models.DataField:
+		return newModelDelegate(interfaces.ModelIdDataField, doc, opts...)
+	case *models.GitSyncLog:
+		return newModelDelegate(interfaces.ModelIdGitSyncLog, doc, opts...)
+	case *models.GitSshKey:
+		return newModelDelegate(interfaces.ModelIdGitSshKey, doc, opts...)
 	default:
 		_ = trace.TraceError(errors.ErrorModelInvalidType)
 		return nil
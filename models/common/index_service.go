@@ -59,6 +59,13 @@ func CreateIndexes() {
 		{Keys: bson.M{"has_sub": 1}},
 	})
 
+	// task queue
+	mongo.GetMongoCol(interfaces.ModelColNameTaskQueue).MustCreateIndexes([]mongo2.IndexModel{
+		{Keys: bson.D{{"p", 1}, {"_id", 1}}},
+		{Keys: bson.D{{"d", 1}, {"p", 1}, {"_id", 1}}},
+		{Keys: bson.M{"sid": 1}},
+	})
+
 	// schedules
 	mongo.GetMongoCol(interfaces.ModelColNameSchedule).MustCreateIndexes([]mongo2.IndexModel{
 		{Keys: bson.M{"name": 1}},
@@ -104,6 +111,20 @@ func CreateIndexes() {
 		{Keys: bson.M{"name": 1}},
 	})
 
+	// data fields
+	mongo.GetMongoCol(interfaces.ModelColNameDataField).MustCreateIndexes([]mongo2.IndexModel{
+		{Keys: bson.D{{"col_id", 1}, {"key", 1}}, Options: options.Index().SetUnique(true)},
+	})
+
+	// git sync logs
+	mongo.GetMongoCol(interfaces.ModelColNameGitSyncLog).MustCreateIndexes([]mongo2.IndexModel{
+		{Keys: bson.D{{"spider_id", 1}, {"_id", -1}}},
+		{
+			Keys:    bson.M{"end_ts": 1},
+			Options: options.Index().SetExpireAfterSeconds(3600 * 24 * constants.GitSyncLogExpireDays),
+		},
+	})
+
 	// extra values
 	mongo.GetMongoCol(interfaces.ModelColNameExtraValues).MustCreateIndexes([]mongo2.IndexModel{
 		{Keys: bson.M{"oid": 1}},
@@ -120,6 +141,20 @@ func CreateIndexes() {
 		{Keys: bson.D{{"plugin_id", 1}, {"node_id", 1}}, Options: options.Index().SetUnique(true)},
 	})
 
+	// notification deliveries
+	mongo.GetMongoCol(interfaces.ModelColNameNotificationDelivery).MustCreateIndexes([]mongo2.IndexModel{
+		{Keys: bson.M{"task_id": 1}},
+		{Keys: bson.M{"setting_id": 1}},
+		{Keys: bson.M{"ts": -1}},
+	})
+
+	// audit logs
+	mongo.GetMongoCol(interfaces.ModelColNameAuditLog).MustCreateIndexes([]mongo2.IndexModel{
+		{Keys: bson.D{{"col", 1}, {"model_id", 1}}},
+		{Keys: bson.M{"user_id": 1}},
+		{Keys: bson.M{"ts": -1}},
+	})
+
 	// cache
 	mongo.GetMongoCol(constants.CacheColName).MustCreateIndexes([]mongo2.IndexModel{
 		{
@@ -127,4 +162,11 @@ func CreateIndexes() {
 			Options: options.Index().SetExpireAfterSeconds(3600 * 24),
 		},
 	})
+
+	// owners
+	for _, colName := range ownedColNames {
+		mongo.GetMongoCol(colName).MustCreateIndexes([]mongo2.IndexModel{
+			{Keys: bson.M{constants.OwnerIdKey: 1}},
+		})
+	}
 }
@@ -0,0 +1,92 @@
+package common
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/crawlab-team/go-trace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+)
+
+// ownedColNames collections of which documents are owned by the users who created them
+var ownedColNames = []string{
+	interfaces.ModelColNameSpider,
+	interfaces.ModelColNameTask,
+	interfaces.ModelColNameSchedule,
+	interfaces.ModelColNameToken,
+	interfaces.ModelColNameWorkflow,
+	interfaces.ModelColNameNotificationSetting,
+	interfaces.ModelColNameNotificationDelivery,
+}
+
+// ownerIdsMigrateBatchSize number of documents of which owner ids are set at a time
+const ownerIdsMigrateBatchSize = 1000
+
+// MigrateOwnerIds set owner ids of documents created before owner ids were
+// stored in the documents, from the creators in their artifacts
+func MigrateOwnerIds() (err error) {
+	for _, colName := range ownedColNames {
+		if err := migrateOwnerIds(colName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateOwnerIds(colName string) (err error) {
+	for {
+		// documents without owner ids
+		var docs []struct {
+			Id primitive.ObjectID `bson:"_id"`
+		}
+		if err := mongo.GetMongoCol(colName).Find(bson.M{
+			constants.OwnerIdKey: bson.M{"$exists": false},
+		}, &mongo.FindOptions{
+			Limit: ownerIdsMigrateBatchSize,
+		}).All(&docs); err != nil {
+			if err.Error() == mongo2.ErrNoDocuments.Error() {
+				return nil
+			}
+			return trace.TraceError(err)
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		var ids []primitive.ObjectID
+		for _, d := range docs {
+			ids = append(ids, d.Id)
+		}
+
+		// creators in artifacts
+		var artifacts []struct {
+			Id  primitive.ObjectID `bson:"_id"`
+			Sys struct {
+				CreateUid primitive.ObjectID `bson:"create_uid"`
+			} `bson:"_sys"`
+		}
+		if err := mongo.GetMongoCol(interfaces.ModelColNameArtifact).Find(bson.M{
+			"_id": bson.M{"$in": ids},
+		}, nil).All(&artifacts); err != nil && err.Error() != mongo2.ErrNoDocuments.Error() {
+			return trace.TraceError(err)
+		}
+		uids := map[primitive.ObjectID]primitive.ObjectID{}
+		for _, a := range artifacts {
+			uids[a.Id] = a.Sys.CreateUid
+		}
+
+		// set owner ids, which are empty for documents created by the system
+		for _, id := range ids {
+			if err := mongo.GetMongoCol(colName).UpdateId(id, bson.M{
+				"$set": bson.M{constants.OwnerIdKey: uids[id]},
+			}); err != nil {
+				return trace.TraceError(err)
+			}
+		}
+
+		if len(docs) < ownerIdsMigrateBatchSize {
+			return nil
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package config_spider
 
-import "github.com/luke513009828/crawlab-core/entity"
+import (
+	"github.com/luke513009828/crawlab-core/entity"
+	"gopkg.in/yaml.v2"
+)
 
 func GetAllFields(data entity.ConfigSpiderData) []entity.Field {
 	var fields []entity.Field
@@ -24,3 +27,16 @@ func GetStartStageName(data entity.ConfigSpiderData) string {
 	}
 	return ""
 }
+
+// ParseSpiderfile 解析Spiderfile
+func ParseSpiderfile(data []byte) (configData entity.ConfigSpiderData, err error) {
+	if err := yaml.Unmarshal(data, &configData); err != nil {
+		return configData, newSpiderfileError("%s", err.Error())
+	}
+	return configData, nil
+}
+
+// GetDefaultSpiderfile 新建可配置爬虫的Spiderfile
+func GetDefaultSpiderfile() []byte {
+	return []byte(defaultSpiderfile)
+}
@@ -1,263 +1,258 @@
 package config_spider
 
-//import (
-//	"errors"
-//	"fmt"
-//	"github.com/luke513009828/crawlab-core/constants"
-//	"github.com/luke513009828/crawlab-core/entity"
-//	"github.com/luke513009828/crawlab-core/models"
-//	"github.com/luke513009828/crawlab-core/utils"
-//	"path/filepath"
-//)
-//
-//type ScrapyGenerator struct {
-//	Spider     model.Spider
-//	ConfigData entity.ConfigSpiderData
-//}
-//
-//// 生成爬虫文件
-//func (g ScrapyGenerator) Generate() error {
-//	// 生成 items.py
-//	if err := g.ProcessItems(); err != nil {
-//		return err
-//	}
-//
-//	// 生成 spider.py
-//	if err := g.ProcessSpider(); err != nil {
-//		return err
-//	}
-//	return nil
-//}
-//
-//// 生成 items.py
-//func (g ScrapyGenerator) ProcessItems() error {
-//	// 待处理文件名
-//	src := g.Spider.Src
-//	filePath := filepath.Join(src, "config_spider", "items.py")
-//
-//	// 获取所有字段
-//	fields := g.GetAllFields()
-//
-//	// 字段名列表（包含默认字段名）
-//	fieldNames := []string{
-//		"_id",
-//		"task_id",
-//		"ts",
-//	}
-//
-//	// 加入字段
-//	for _, field := range fields {
-//		fieldNames = append(fieldNames, field.Name)
-//	}
-//
-//	// 将字段名转化为python代码
-//	str := ""
-//	for _, fieldName := range fieldNames {
-//		line := g.PadCode(fmt.Sprintf("%s = scrapy.Field()", fieldName), 1)
-//		str += line
-//	}
-//
-//	// 将占位符替换为代码
-//	if err := utils.SetFileVariable(filePath, constants.AnchorItems, str); err != nil {
-//		return err
-//	}
-//
-//	return nil
-//}
-//
-//// 生成 spider.py
-//func (g ScrapyGenerator) ProcessSpider() error {
-//	// 待处理文件名
-//	src := g.Spider.Src
-//	filePath := filepath.Join(src, "config_spider", "spiders", "spider.py")
-//
-//	// 替换 start_stage
-//	if err := utils.SetFileVariable(filePath, constants.AnchorStartStage, "parse_"+GetStartStageName(g.ConfigData)); err != nil {
-//		return err
-//	}
-//
-//	// 替换 start_url
-//	if err := utils.SetFileVariable(filePath, constants.AnchorStartUrl, g.ConfigData.StartUrl); err != nil {
-//		return err
-//	}
-//
-//	// 替换 parsers
-//	strParser := ""
-//	for _, stage := range g.ConfigData.Stages {
-//		stageName := stage.Name
-//		stageStr := g.GetParserString(stageName, stage)
-//		strParser += stageStr
-//	}
-//	if err := utils.SetFileVariable(filePath, constants.AnchorParsers, strParser); err != nil {
-//		return err
-//	}
-//
-//	return nil
-//}
-//
-//func (g ScrapyGenerator) GetParserString(stageName string, stage entity.Stage) string {
-//	// 构造函数定义行
-//	strDef := g.PadCode(fmt.Sprintf("def parse_%s(self, response):", stageName), 1)
-//
-//	strParse := ""
-//	if stage.IsList {
-//		// 列表逻辑
-//		strParse = g.GetListParserString(stageName, stage)
-//	} else {
-//		// 非列表逻辑
-//		strParse = g.GetNonListParserString(stageName, stage)
-//	}
-//
-//	// 构造
-//	str := fmt.Sprintf(`%s%s`, strDef, strParse)
-//
-//	return str
-//}
-//
-//func (g ScrapyGenerator) PadCode(str string, num int) string {
-//	res := ""
-//	for i := 0; i < num; i++ {
-//		res += "    "
-//	}
-//	res += str
-//	res += "\n"
-//	return res
-//}
-//
-//func (g ScrapyGenerator) GetNonListParserString(stageName string, stage entity.Stage) string {
-//	str := ""
-//
-//	// 获取或构造item
-//	str += g.PadCode("item = Item() if response.meta.get('item') is None else response.meta.get('item')", 2)
-//
-//	// 遍历字段列表
-//	for _, f := range stage.Fields {
-//		line := fmt.Sprintf(`item['%s'] = response.%s.extract_first()`, f.Name, g.GetExtractStringFromField(f))
-//		line = g.PadCode(line, 2)
-//		str += line
-//	}
-//
-//	// next stage 字段
-//	if f, err := g.GetNextStageField(stage); err == nil {
-//		// 如果找到 next stage 字段，进行下一个回调
-//		str += g.PadCode(fmt.Sprintf(`yield scrapy.Request(url="get_real_url(response, item['%s'])", callback=self.parse_%s, meta={'item': item})`, f.Name, f.NextStage), 2)
-//	} else {
-//		// 如果没找到 next stage 字段，返回 item
-//		str += g.PadCode(fmt.Sprintf(`yield item`), 2)
-//	}
-//
-//	// 加入末尾换行
-//	str += g.PadCode("", 0)
-//
-//	return str
-//}
-//
-//func (g ScrapyGenerator) GetListParserString(stageName string, stage entity.Stage) string {
-//	str := ""
-//
-//	// 获取前一个 stage 的 item
-//	str += g.PadCode(`prev_item = response.meta.get('item')`, 2)
-//
-//	// for 循环遍历列表
-//	str += g.PadCode(fmt.Sprintf(`for elem in response.%s:`, g.GetListString(stage)), 2)
-//
-//	// 构造item
-//	str += g.PadCode(`item = Item()`, 3)
-//
-//	// 遍历字段列表
-//	for _, f := range stage.Fields {
-//		line := fmt.Sprintf(`item['%s'] = elem.%s.extract_first()`, f.Name, g.GetExtractStringFromField(f))
-//		line = g.PadCode(line, 3)
-//		str += line
-//	}
-//
-//	// 把前一个 stage 的 item 值赋给当前 item
-//	str += g.PadCode(`if prev_item is not None:`, 3)
-//	str += g.PadCode(`for key, value in prev_item.items():`, 4)
-//	str += g.PadCode(`item[key] = value`, 5)
-//
-//	// next stage 字段
-//	if f, err := g.GetNextStageField(stage); err == nil {
-//		// 如果 url 为空，则不进入下一个 stage
-//		str += g.PadCode(fmt.Sprintf(`if not item['%s']:`, f.Name), 3)
-//		str += g.PadCode(`continue`, 4)
-//
-//		// 如果找到 next stage 字段，进行下一个回调
-//		str += g.PadCode(fmt.Sprintf(`yield scrapy.Request(url=get_real_url(response, item['%s']), callback=self.parse_%s, meta={'item': item})`, f.Name, f.NextStage), 3)
-//	} else {
-//		// 如果没找到 next stage 字段，返回 item
-//		str += g.PadCode(fmt.Sprintf(`yield item`), 3)
-//	}
-//
-//	// 分页
-//	if stage.PageCss != "" || stage.PageXpath != "" {
-//		str += g.PadCode(fmt.Sprintf(`next_url = response.%s.extract_first()`, g.GetExtractStringFromStage(stage)), 2)
-//		str += g.PadCode(fmt.Sprintf(`yield scrapy.Request(url=get_real_url(response, next_url), callback=self.parse_%s, meta={'item': prev_item})`, stageName), 2)
-//	}
-//
-//	// 加入末尾换行
-//	str += g.PadCode("", 0)
-//
-//	return str
-//}
-//
-//// 获取所有字段
-//func (g ScrapyGenerator) GetAllFields() []entity.Field {
-//	return GetAllFields(g.ConfigData)
-//}
-//
-//// 获取包含 next stage 的字段
-//func (g ScrapyGenerator) GetNextStageField(stage entity.Stage) (entity.Field, error) {
-//	for _, field := range stage.Fields {
-//		if field.NextStage != "" {
-//			return field, nil
-//		}
-//	}
-//	return entity.Field{}, errors.New("cannot find next stage field")
-//}
-//
-//func (g ScrapyGenerator) GetExtractStringFromField(f entity.Field) string {
-//	if f.Css != "" {
-//		// 如果为CSS
-//		if f.Attr == "" {
-//			// 文本
-//			return fmt.Sprintf(`css('%s::text')`, f.Css)
-//		} else {
-//			// 属性
-//			return fmt.Sprintf(`css('%s::attr("%s")')`, f.Css, f.Attr)
-//		}
-//	} else {
-//		// 如果为XPath
-//		if f.Attr == "" {
-//			// 文本
-//			return fmt.Sprintf(`xpath('string(%s)')`, f.Xpath)
-//		} else {
-//			// 属性
-//			return fmt.Sprintf(`xpath('%s/@%s')`, f.Xpath, f.Attr)
-//		}
-//	}
-//}
-//
-//func (g ScrapyGenerator) GetExtractStringFromStage(stage entity.Stage) string {
-//	// 分页元素属性，默认为 href
-//	pageAttr := "href"
-//	if stage.PageAttr != "" {
-//		pageAttr = stage.PageAttr
-//	}
-//
-//	if stage.PageCss != "" {
-//		// 如果为CSS
-//		return fmt.Sprintf(`css('%s::attr("%s")')`, stage.PageCss, pageAttr)
-//	} else {
-//		// 如果为XPath
-//		return fmt.Sprintf(`xpath('%s/@%s')`, stage.PageXpath, pageAttr)
-//	}
-//}
-//
-//func (g ScrapyGenerator) GetListString(stage entity.Stage) string {
-//	if stage.ListCss != "" {
-//		return fmt.Sprintf(`css('%s')`, stage.ListCss)
-//	} else {
-//		return fmt.Sprintf(`xpath('%s')`, stage.ListXpath)
-//	}
-//}
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/entity"
+	"sort"
+	"strings"
+)
+
+type ScrapyGenerator struct {
+	ConfigData entity.ConfigSpiderData
+}
+
+// Generate 生成爬虫文件，key 为相对于爬虫根目录的路径
+func (g ScrapyGenerator) Generate() (files map[string][]byte, err error) {
+	files = map[string][]byte{}
+	for path, content := range scrapyTemplate {
+		switch path {
+		case "config_spider/items.py":
+			content = g.ProcessItems(content)
+		case "config_spider/settings.py":
+			content = g.ProcessSettings(content)
+		case "config_spider/spiders/spider.py":
+			content = g.ProcessSpider(content)
+		}
+		files[path] = []byte(content)
+	}
+	return files, nil
+}
+
+// ProcessItems 生成 items.py
+func (g ScrapyGenerator) ProcessItems(content string) string {
+	// 字段名列表（包含默认字段名）
+	fieldNames := []string{
+		"_id",
+		"task_id",
+		"ts",
+	}
+
+	// 加入字段
+	for _, field := range g.GetAllFields() {
+		fieldNames = append(fieldNames, field.Name)
+	}
+
+	// 将字段名转化为python代码
+	str := ""
+	for _, fieldName := range fieldNames {
+		str += g.PadCode(fmt.Sprintf("%s = scrapy.Field()", fieldName), 1)
+	}
+
+	// 将占位符替换为代码
+	return g.SetVariable(content, constants.AnchorItems, str)
+}
+
+// ProcessSettings 生成 settings.py
+func (g ScrapyGenerator) ProcessSettings(content string) string {
+	// 按名称排序，保证生成结果一致
+	var keys []string
+	for key := range g.ConfigData.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// 设置值为 python 字面量时按字面量解析，否则为字符串
+	str := ""
+	for _, key := range keys {
+		str += g.PadCode(fmt.Sprintf("%s = _setting_value(%s)", key, g.GetPythonString(g.ConfigData.Settings[key])), 0)
+	}
+
+	return g.SetVariable(content, constants.AnchorSettings, str)
+}
+
+// ProcessSpider 生成 spider.py
+func (g ScrapyGenerator) ProcessSpider(content string) string {
+	// 替换 start_stage
+	content = g.SetVariable(content, constants.AnchorStartStage, "parse_"+GetStartStageName(g.ConfigData))
+
+	// 替换 start_url
+	content = g.SetVariable(content, constants.AnchorStartUrl, g.GetPythonString(g.ConfigData.StartUrl))
+
+	// 替换 parsers
+	strParser := ""
+	for _, stage := range g.ConfigData.Stages {
+		strParser += g.GetParserString(stage.Name, stage)
+	}
+	return g.SetVariable(content, constants.AnchorParsers, strParser)
+}
+
+// SetVariable 将占位符替换为想要设置的值
+func (g ScrapyGenerator) SetVariable(content string, key string, value string) string {
+	return strings.Replace(content, fmt.Sprintf("###%s###", key), value, -1)
+}
+
+func (g ScrapyGenerator) GetParserString(stageName string, stage entity.Stage) string {
+	// 构造函数定义行
+	strDef := g.PadCode(fmt.Sprintf("def parse_%s(self, response):", stageName), 1)
+
+	strParse := ""
+	if stage.IsList {
+		// 列表逻辑
+		strParse = g.GetListParserString(stageName, stage)
+	} else {
+		// 非列表逻辑
+		strParse = g.GetNonListParserString(stageName, stage)
+	}
+
+	return strDef + strParse
+}
+
+func (g ScrapyGenerator) PadCode(str string, num int) string {
+	return strings.Repeat("    ", num) + str + "\n"
+}
+
+func (g ScrapyGenerator) GetNonListParserString(stageName string, stage entity.Stage) string {
+	str := ""
+
+	// 获取或构造item
+	str += g.PadCode("item = Item() if response.meta.get('item') is None else response.meta.get('item')", 2)
+
+	// 遍历字段列表
+	for _, f := range stage.Fields {
+		line := fmt.Sprintf(`item['%s'] = response.%s.extract_first()`, f.Name, g.GetExtractStringFromField(f))
+		str += g.PadCode(line, 2)
+	}
+
+	// next stage 字段
+	if f, ok := g.GetNextStageField(stage); ok {
+		// 如果 url 为空，则返回 item
+		str += g.PadCode(fmt.Sprintf(`if not item['%s']:`, f.Name), 2)
+		str += g.PadCode(`yield item`, 3)
+		str += g.PadCode(`return`, 3)
+
+		// 如果找到 next stage 字段，进行下一个回调
+		str += g.PadCode(fmt.Sprintf(`yield scrapy.Request(url=get_real_url(response, item['%s']), callback=self.parse_%s, meta={'item': item})`, f.Name, f.NextStage), 2)
+	} else {
+		// 如果没找到 next stage 字段，返回 item
+		str += g.PadCode(`yield item`, 2)
+	}
+
+	// 加入末尾换行
+	str += g.PadCode("", 0)
+
+	return str
+}
+
+func (g ScrapyGenerator) GetListParserString(stageName string, stage entity.Stage) string {
+	str := ""
+
+	// 获取前一个 stage 的 item
+	str += g.PadCode(`prev_item = response.meta.get('item')`, 2)
+
+	// for 循环遍历列表
+	str += g.PadCode(fmt.Sprintf(`for elem in response.%s:`, g.GetListString(stage)), 2)
+
+	// 构造item
+	str += g.PadCode(`item = Item()`, 3)
+
+	// 遍历字段列表
+	for _, f := range stage.Fields {
+		line := fmt.Sprintf(`item['%s'] = elem.%s.extract_first()`, f.Name, g.GetExtractStringFromField(f))
+		str += g.PadCode(line, 3)
+	}
+
+	// 把前一个 stage 的 item 值赋给当前 item
+	str += g.PadCode(`if prev_item is not None:`, 3)
+	str += g.PadCode(`for key, value in prev_item.items():`, 4)
+	str += g.PadCode(`item[key] = value`, 5)
+
+	// next stage 字段
+	if f, ok := g.GetNextStageField(stage); ok {
+		// 如果 url 为空，则不进入下一个 stage
+		str += g.PadCode(fmt.Sprintf(`if not item['%s']:`, f.Name), 3)
+		str += g.PadCode(`continue`, 4)
+
+		// 如果找到 next stage 字段，进行下一个回调
+		str += g.PadCode(fmt.Sprintf(`yield scrapy.Request(url=get_real_url(response, item['%s']), callback=self.parse_%s, meta={'item': item})`, f.Name, f.NextStage), 3)
+	} else {
+		// 如果没找到 next stage 字段，返回 item
+		str += g.PadCode(`yield item`, 3)
+	}
+
+	// 分页
+	if stage.PageCss != "" || stage.PageXpath != "" {
+		str += g.PadCode(fmt.Sprintf(`next_url = response.%s.extract_first()`, g.GetExtractStringFromStage(stage)), 2)
+		str += g.PadCode(`if next_url:`, 2)
+		str += g.PadCode(fmt.Sprintf(`yield scrapy.Request(url=get_real_url(response, next_url), callback=self.parse_%s, meta={'item': prev_item})`, stageName), 3)
+	}
+
+	// 加入末尾换行
+	str += g.PadCode("", 0)
+
+	return str
+}
+
+// GetAllFields 获取所有字段
+func (g ScrapyGenerator) GetAllFields() []entity.Field {
+	return GetAllFields(g.ConfigData)
+}
+
+// GetNextStageField 获取包含 next stage 的字段
+func (g ScrapyGenerator) GetNextStageField(stage entity.Stage) (entity.Field, bool) {
+	for _, field := range stage.Fields {
+		if field.NextStage != "" {
+			return field, true
+		}
+	}
+	return entity.Field{}, false
+}
+
+func (g ScrapyGenerator) GetExtractStringFromField(f entity.Field) string {
+	if f.Css != "" {
+		// 如果为CSS
+		if f.Attr == "" {
+			// 文本
+			return fmt.Sprintf(`css(%s)`, g.GetPythonString(f.Css+"::text"))
+		}
+		// 属性
+		return fmt.Sprintf(`css(%s)`, g.GetPythonString(fmt.Sprintf("%s::attr(%s)", f.Css, f.Attr)))
+	}
+
+	// 如果为XPath
+	if f.Attr == "" {
+		// 文本
+		return fmt.Sprintf(`xpath(%s)`, g.GetPythonString(fmt.Sprintf("string(%s)", f.Xpath)))
+	}
+	// 属性
+	return fmt.Sprintf(`xpath(%s)`, g.GetPythonString(fmt.Sprintf("%s/@%s", f.Xpath, f.Attr)))
+}
+
+func (g ScrapyGenerator) GetExtractStringFromStage(stage entity.Stage) string {
+	// 分页元素属性，默认为 href
+	pageAttr := "href"
+	if stage.PageAttr != "" {
+		pageAttr = stage.PageAttr
+	}
+
+	if stage.PageCss != "" {
+		// 如果为CSS
+		return fmt.Sprintf(`css(%s)`, g.GetPythonString(fmt.Sprintf("%s::attr(%s)", stage.PageCss, pageAttr)))
+	}
+	// 如果为XPath
+	return fmt.Sprintf(`xpath(%s)`, g.GetPythonString(fmt.Sprintf("%s/@%s", stage.PageXpath, pageAttr)))
+}
+
+func (g ScrapyGenerator) GetListString(stage entity.Stage) string {
+	if stage.ListCss != "" {
+		return fmt.Sprintf(`css(%s)`, g.GetPythonString(stage.ListCss))
+	}
+	return fmt.Sprintf(`xpath(%s)`, g.GetPythonString(stage.ListXpath))
+}
+
+// GetPythonString python 字符串字面量，JSON 字符串同时也是合法的 python 字符串
+func (g ScrapyGenerator) GetPythonString(str string) string {
+	data, _ := json.Marshal(str)
+	return string(data)
+}
@@ -0,0 +1,85 @@
+package config_spider
+
+// scrapy project template of configurable spiders, of which ###ANCHOR###
+// placeholders are replaced by the generator
+var scrapyTemplate = map[string]string{
+	"scrapy.cfg": `[settings]
+default = config_spider.settings
+
+[deploy]
+project = config_spider
+`,
+	"config_spider/__init__.py": ``,
+	"config_spider/items.py": `# -*- coding: utf-8 -*-
+
+import scrapy
+
+
+class Item(scrapy.Item):
+###ITEMS###
+`,
+	"config_spider/settings.py": `# -*- coding: utf-8 -*-
+
+import ast
+
+BOT_NAME = 'config_spider'
+
+SPIDER_MODULES = ['config_spider.spiders']
+NEWSPIDER_MODULE = 'config_spider.spiders'
+
+ROBOTSTXT_OBEY = False
+
+ITEM_PIPELINES = {
+    'crawlab.CrawlabPipeline': 888,
+}
+
+
+def _setting_value(value):
+    try:
+        return ast.literal_eval(value)
+    except (ValueError, SyntaxError):
+        return value
+
+
+###SETTINGS###
+`,
+	"config_spider/spiders/__init__.py": ``,
+	"config_spider/spiders/spider.py": `# -*- coding: utf-8 -*-
+
+import scrapy
+from urllib.parse import urljoin
+
+from config_spider.items import Item
+
+
+def get_real_url(response, url):
+    if url is None:
+        return None
+    return urljoin(response.url, url)
+
+
+class ConfigSpider(scrapy.Spider):
+    name = 'config_spider'
+
+    def start_requests(self):
+        yield scrapy.Request(url=###START_URL###, callback=self.###START_STAGE###)
+
+###PARSERS###
+`,
+}
+
+// defaultSpiderfile Spiderfile of new configurable spiders
+const defaultSpiderfile = `name: config_spider
+engine: scrapy
+start_url: http://example.com
+start_stage: detail
+stages:
+  - name: detail
+    fields:
+      - name: title
+        css: h1
+      - name: content
+        css: p
+settings:
+  ROBOTSTXT_OBEY: "False"
+`
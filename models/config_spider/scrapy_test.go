@@ -0,0 +1,115 @@
+package config_spider
+
+import (
+	errors2 "errors"
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+const testSpiderfile = `name: test
+start_url: http://example.com/list
+start_stage: list
+stages:
+  - name: list
+    is_list: true
+    list_css: .item
+    page_css: a.next
+    fields:
+      - name: title
+        css: a
+      - name: url
+        css: a
+        attr: href
+        next_stage: detail
+  - name: detail
+    fields:
+      - name: content
+        xpath: //div[@class='content']
+settings:
+  DOWNLOAD_DELAY: "1"
+`
+
+func TestScrapyGenerator_Generate(t *testing.T) {
+	configData, err := ParseSpiderfile([]byte(testSpiderfile))
+	require.Nil(t, err)
+	require.Nil(t, ValidateSpiderfile(configData))
+
+	files, err := ScrapyGenerator{ConfigData: configData}.Generate()
+	require.Nil(t, err)
+	require.Contains(t, files, "scrapy.cfg")
+	for path, content := range files {
+		require.NotContains(t, string(content), "###", path)
+	}
+
+	items := string(files["config_spider/items.py"])
+	for _, name := range []string{"_id", "task_id", "ts", "title", "url", "content"} {
+		require.Contains(t, items, "    "+name+" = scrapy.Field()")
+	}
+
+	spider := string(files["config_spider/spiders/spider.py"])
+	require.Contains(t, spider, `scrapy.Request(url="http://example.com/list", callback=self.parse_list)`)
+	require.Contains(t, spider, `for elem in response.css(".item"):`)
+	require.Contains(t, spider, `item['url'] = elem.css("a::attr(href)").extract_first()`)
+	require.Contains(t, spider, `callback=self.parse_detail`)
+	require.Contains(t, spider, `next_url = response.css("a.next::attr(href)").extract_first()`)
+	require.Contains(t, spider, `item['content'] = response.xpath("string(//div[@class='content'])").extract_first()`)
+
+	settings := string(files["config_spider/settings.py"])
+	require.Contains(t, settings, `DOWNLOAD_DELAY = _setting_value("1")`)
+}
+
+func TestValidateSpiderfile(t *testing.T) {
+	newConfigData := func() entity.ConfigSpiderData {
+		configData, err := ParseSpiderfile([]byte(testSpiderfile))
+		require.Nil(t, err)
+		return configData
+	}
+
+	// default Spiderfile
+	configData, err := ParseSpiderfile(GetDefaultSpiderfile())
+	require.Nil(t, err)
+	require.Nil(t, ValidateSpiderfile(configData))
+
+	// invalid Spiderfiles
+	cases := map[string]func(d *entity.ConfigSpiderData){
+		"start_url is empty":         func(d *entity.ConfigSpiderData) { d.StartUrl = "" },
+		"not implemented":            func(d *entity.ConfigSpiderData) { d.Engine = "unknown" },
+		"start_stage 'unknown'":      func(d *entity.ConfigSpiderData) { d.StartStage = "unknown" },
+		"stage name 'detail' is dup": func(d *entity.ConfigSpiderData) { d.Stages[0].Name = "detail" },
+		"stage name 'a-b' is invalid": func(d *entity.ConfigSpiderData) {
+			d.Stages[1].Name = "a-b"
+		},
+		"next_stage 'unknown'": func(d *entity.ConfigSpiderData) { d.Stages[0].Fields[1].NextStage = "unknown" },
+		"field name 'task_id' is protected": func(d *entity.ConfigSpiderData) {
+			d.Stages[1].Fields[0].Name = "task_id"
+		},
+		"field name 'title' is duplicated": func(d *entity.ConfigSpiderData) {
+			d.Stages[1].Fields[0].Name = "title"
+		},
+		"both css and xpath": func(d *entity.ConfigSpiderData) { d.Stages[1].Fields[0].Css = "div" },
+		"either list_css":    func(d *entity.ConfigSpiderData) { d.Stages[0].ListCss = "" },
+		"setting name 'delay' is invalid": func(d *entity.ConfigSpiderData) {
+			d.Settings = map[string]string{"delay": "1"}
+		},
+	}
+	for msg, fn := range cases {
+		d := newConfigData()
+		fn(&d)
+		err := ValidateSpiderfile(d)
+		require.NotNil(t, err, msg)
+		require.True(t, strings.Contains(err.Error(), msg), err.Error())
+		require.True(t, errors2.Is(err, errors.ErrorSpiderInvalidSpiderfile), err.Error())
+	}
+
+	// invalid yaml
+	_, err = ParseSpiderfile([]byte("name: [test"))
+	require.True(t, errors2.Is(err, errors.ErrorSpiderInvalidSpiderfile), err.Error())
+
+	// protected names are matched exactly
+	d := newConfigData()
+	d.Stages[1].Fields[0].Name = "id"
+	require.Nil(t, ValidateSpiderfile(d))
+}
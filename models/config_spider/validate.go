@@ -0,0 +1,160 @@
+package config_spider
+
+import (
+	"fmt"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/luke513009828/crawlab-core/errors"
+	"regexp"
+	"strings"
+)
+
+// python 标识符，stage 名称和字段名称会用作函数名和 Item 字段名
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// scrapy 设置名称
+var settingNameRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// ValidateSpiderfile 验证Spiderfile
+func ValidateSpiderfile(configData entity.ConfigSpiderData) error {
+	// 校验 engine，如果有其他Engine，可以扩展，默认为Scrapy
+	if configData.Engine != "" && configData.Engine != constants.EngineScrapy {
+		return newSpiderfileError("engine '%s' is not implemented", configData.Engine)
+	}
+
+	// 校验是否存在 start_url
+	if configData.StartUrl == "" {
+		return newSpiderfileError("start_url is empty")
+	}
+
+	// 校验是否存在 stages
+	if len(configData.Stages) == 0 {
+		return newSpiderfileError("stages is empty")
+	}
+
+	// 校验stages
+	dict := map[string]bool{}
+	for _, stage := range configData.Stages {
+		stageName := stage.Name
+
+		// stage 名称不能为空
+		if stageName == "" {
+			return newSpiderfileError("stage name is empty")
+		}
+
+		// stage 名称须为合法标识符
+		if !identifierRegexp.MatchString(stageName) {
+			return newSpiderfileError("stage name '%s' is invalid", stageName)
+		}
+
+		// stage 名称不能为保留字符串
+		if isProtectedName(constants.ScrapyProtectedStageNames, stageName) {
+			return newSpiderfileError("stage name '%s' is protected", stageName)
+		}
+
+		// stage 名称不能重复
+		if dict[stageName] {
+			return newSpiderfileError("stage name '%s' is duplicated", stageName)
+		}
+		dict[stageName] = true
+
+		// stage 字段不能为空
+		if len(stage.Fields) == 0 {
+			return newSpiderfileError("stage '%s' has no fields", stageName)
+		}
+
+		// 是否包含 next_stage
+		hasNextStage := false
+
+		// 遍历字段列表
+		for _, field := range stage.Fields {
+			// stage 的 next stage 只能有一个
+			if field.NextStage != "" {
+				if hasNextStage {
+					return newSpiderfileError("stage '%s' has more than 1 next_stage", stageName)
+				}
+				hasNextStage = true
+			}
+
+			// 字段里 css 和 xpath 只能包含一个
+			if field.Css != "" && field.Xpath != "" {
+				return newSpiderfileError("field '%s' in stage '%s' has both css and xpath set which is prohibited", field.Name, stageName)
+			}
+
+			// 字段里 css 和 xpath 必须包含一个
+			if field.Css == "" && field.Xpath == "" {
+				return newSpiderfileError("field '%s' in stage '%s' should have either css or xpath being set", field.Name, stageName)
+			}
+		}
+
+		// stage 里 page_css 和 page_xpath 只能包含一个
+		if stage.PageCss != "" && stage.PageXpath != "" {
+			return newSpiderfileError("stage '%s' has both page_css and page_xpath set which is prohibited", stageName)
+		}
+
+		// stage 里 list_css 和 list_xpath 只能包含一个
+		if stage.ListCss != "" && stage.ListXpath != "" {
+			return newSpiderfileError("stage '%s' has both list_css and list_xpath set which is prohibited", stageName)
+		}
+
+		// 如果 stage 的 is_list 为 true 但 list_css 为空，报错
+		if stage.IsList && (stage.ListCss == "" && stage.ListXpath == "") {
+			return newSpiderfileError("stage '%s' with is_list = true should have either list_css or list_xpath being set", stageName)
+		}
+	}
+
+	// 校验 start_stage 和 next_stage 是否存在
+	if configData.StartStage != "" && !dict[configData.StartStage] {
+		return newSpiderfileError("start_stage '%s' does not exist", configData.StartStage)
+	}
+	for _, field := range GetAllFields(configData) {
+		if field.NextStage != "" && !dict[field.NextStage] {
+			return newSpiderfileError("next_stage '%s' of field '%s' does not exist", field.NextStage, field.Name)
+		}
+	}
+
+	// 校验字段
+	fieldDict := map[string]bool{}
+	for _, field := range GetAllFields(configData) {
+		// 字段名称须为合法标识符
+		if !identifierRegexp.MatchString(field.Name) {
+			return newSpiderfileError("field name '%s' is invalid", field.Name)
+		}
+
+		// 字段名称不能为保留字符串
+		if isProtectedName(constants.ScrapyProtectedFieldNames, field.Name) {
+			return newSpiderfileError("field name '%s' is protected", field.Name)
+		}
+
+		// 字段名称不能重复
+		if fieldDict[field.Name] {
+			return newSpiderfileError("field name '%s' is duplicated", field.Name)
+		}
+		fieldDict[field.Name] = true
+	}
+
+	// 校验设置名称
+	for key := range configData.Settings {
+		if !settingNameRegexp.MatchString(key) {
+			return newSpiderfileError("setting name '%s' is invalid", key)
+		}
+	}
+
+	return nil
+}
+
+// newSpiderfileError error of an invalid Spiderfile, which wraps
+// errors.ErrorSpiderInvalidSpiderfile
+func newSpiderfileError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errors.ErrorSpiderInvalidSpiderfile, fmt.Sprintf(format, args...))
+}
+
+// isProtectedName 是否为逗号分隔的保留字符串之一
+func isProtectedName(protectedNames string, name string) bool {
+	for _, n := range strings.Split(protectedNames, ",") {
+		if n != "" && n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package delegate
 
 import (
 	"encoding/json"
+	"github.com/luke513009828/crawlab-core/constants"
 	errors2 "github.com/luke513009828/crawlab-core/errors"
 	"github.com/luke513009828/crawlab-core/event"
 	"github.com/luke513009828/crawlab-core/interfaces"
@@ -65,6 +66,24 @@ func NewModelDelegate(doc interfaces.Model, args ...interface{}) interfaces.Mode
 		return newModelDelegate(interfaces.ModelIdPluginStatus, doc, args...)
 	case *models.Git:
 		return newModelDelegate(interfaces.ModelIdGit, doc, args...)
+	case *models.Workflow:
+		return newModelDelegate(interfaces.ModelIdWorkflow, doc, args...)
+	case *models.WorkflowRun:
+		return newModelDelegate(interfaces.ModelIdWorkflowRun, doc, args...)
+	case *models.NotificationSetting:
+		return newModelDelegate(interfaces.ModelIdNotificationSetting, doc, args...)
+	case *models.NotificationDelivery:
+		return newModelDelegate(interfaces.ModelIdNotificationDelivery, doc, args...)
+	case *models.AuditLog:
+		return newModelDelegate(interfaces.ModelIdAuditLog, doc, args...)
+	case *models.ResultExport:
+		return newModelDelegate(interfaces.ModelIdResultExport, doc, args...)
+	case *models.DataField:
+		return newModelDelegate(interfaces.ModelIdDataField, doc, args...)
+	case *models.GitSyncLog:
+		return newModelDelegate(interfaces.ModelIdGitSyncLog, doc, args...)
+	case *models.GitSshKey:
+		return newModelDelegate(interfaces.ModelIdGitSshKey, doc, args...)
 	default:
 		_ = trace.TraceError(errors2.ErrorModelInvalidType)
 		return nil
@@ -161,6 +180,11 @@ func (d *ModelDelegate) do(method interfaces.ModelDelegateMethod) (err error) {
 		return err
 	}
 
+	// audit log
+	if err := d.audit(method); err != nil {
+		trace.PrintError(err)
+	}
+
 	// trigger event
 	eventName := GetEventName(d, method)
 	go event.SendEvent(eventName, d.doc)
@@ -176,8 +200,12 @@ func (d *ModelDelegate) add() (err error) {
 	if d.doc.GetId().IsZero() {
 		d.doc.SetId(primitive.NewObjectID())
 	}
+	doc, err := d._withOwnerId()
+	if err != nil {
+		return trace.TraceError(err)
+	}
 	col := mongo.GetMongoCol(d.colName)
-	if _, err = col.Insert(d.doc); err != nil {
+	if _, err = col.Insert(doc); err != nil {
 		return trace.TraceError(err)
 	}
 	if err := d.upsertArtifact(); err != nil {
@@ -215,8 +243,13 @@ func (d *ModelDelegate) save() (err error) {
 		trace.PrintError(err)
 	}
 
-	// replace
-	if err := col.ReplaceId(d.doc.GetId(), d.doc); err != nil {
+	// replace, keeping the owner id
+	var doc interface{} = d.doc
+	if uid, ok := d.od[constants.OwnerIdKey]; ok && d.cd != nil {
+		d.cd[constants.OwnerIdKey] = uid
+		doc = d.cd
+	}
+	if err := col.ReplaceId(d.doc.GetId(), doc); err != nil {
 		return trace.TraceError(err)
 	}
 
@@ -387,6 +420,74 @@ func (d *ModelDelegate) updateTags() (err error) {
 	return col.ReplaceId(d.a.GetId(), d.a)
 }
 
+// audit record field-level changes of the model in an audit log
+func (d *ModelDelegate) audit(method interfaces.ModelDelegateMethod) (err error) {
+	// skip
+	if d._skipAudit(method) {
+		return nil
+	}
+
+	// docs before and after the change
+	var before, after bson.M
+	switch method {
+	case interfaces.ModelDelegateMethodAdd:
+		after, err = d._toBsonM()
+	case interfaces.ModelDelegateMethodSave:
+		before, after = d.od, d.cd
+	case interfaces.ModelDelegateMethodDelete:
+		before, err = d._toBsonM()
+	default:
+		return nil
+	}
+	if err != nil {
+		return trace.TraceError(err)
+	}
+
+	// diffs
+	var diffs []models.AuditLogDiff
+	for _, key := range utils.GetBsonMDiffKeys(before, after) {
+		if key == "_id" {
+			continue
+		}
+		diff := models.AuditLogDiff{
+			Key:    key,
+			Before: before[key],
+			After:  after[key],
+		}
+		if d._isAuditMasked(key, before, after) {
+			diff.Before = maskAuditValue(diff.Before)
+			diff.After = maskAuditValue(diff.After)
+		} else if key == "envs" {
+			diff.Before = maskAuditEnvs(diff.Before)
+			diff.After = maskAuditEnvs(diff.After)
+		}
+		diffs = append(diffs, diff)
+	}
+
+	// skip saving without changes
+	if method == interfaces.ModelDelegateMethodSave && len(diffs) == 0 {
+		return nil
+	}
+
+	// audit log
+	l := &models.AuditLog{
+		Id:      primitive.NewObjectID(),
+		Col:     d.colName,
+		ModelId: d.doc.GetId(),
+		Method:  string(method),
+		Diffs:   diffs,
+		Ts:      time.Now(),
+	}
+	if d.u != nil && !reflect.ValueOf(d.u).IsZero() {
+		l.UserId = d.u.GetId()
+	}
+	if _, err := mongo.GetMongoCol(interfaces.ModelColNameAuditLog).Insert(l); err != nil {
+		return trace.TraceError(err)
+	}
+
+	return nil
+}
+
 func (d *ModelDelegate) hasChange() (ok bool) {
 	return !utils.BsonMEqual(d.cd, d.od)
 }
@@ -405,3 +506,117 @@ func (d *ModelDelegate) _skip() (ok bool) {
 		return false
 	}
 }
+
+func (d *ModelDelegate) _skipAudit(method interfaces.ModelDelegateMethod) (ok bool) {
+	if d._skip() {
+		return true
+	}
+	switch d.id {
+	case
+		interfaces.ModelIdAuditLog,
+		interfaces.ModelIdNotificationDelivery,
+		interfaces.ModelIdResultExport,
+		interfaces.ModelIdGitSyncLog:
+		return true
+	case
+		interfaces.ModelIdNode,
+		interfaces.ModelIdTask,
+		interfaces.ModelIdWorkflowRun:
+		// runtime states saved by the system (e.g. heartbeats and task status)
+		return method == interfaces.ModelDelegateMethodSave && (d.u == nil || reflect.ValueOf(d.u).IsZero())
+	default:
+		return false
+	}
+}
+
+func (d *ModelDelegate) _isAuditMasked(key string, before, after bson.M) (ok bool) {
+	for _, k := range auditMaskedKeys {
+		if key == k {
+			return true
+		}
+	}
+	if d.id == interfaces.ModelIdVariable && key == "value" {
+		return before["secret"] == true || after["secret"] == true
+	}
+	return false
+}
+
+// _withOwnerId doc to insert with the id of the user as its owner, which
+// is empty if added by the system
+func (d *ModelDelegate) _withOwnerId() (doc interface{}, err error) {
+	if d._skip() {
+		return d.doc, nil
+	}
+	m, err := d._toBsonM()
+	if err != nil {
+		return nil, err
+	}
+	m[constants.OwnerIdKey] = primitive.NilObjectID
+	if d.u != nil && !reflect.ValueOf(d.u).IsZero() {
+		m[constants.OwnerIdKey] = d.u.GetId()
+	}
+	return m, nil
+}
+
+func (d *ModelDelegate) _toBsonM() (m bson.M, err error) {
+	data, err := bson.Marshal(d.doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// auditMaskedKeys keys of sensitive fields whose values are masked in audit logs
+var auditMaskedKeys = []string{
+	"password",
+	"git_password",
+	"token",
+	"token_hash",
+	"private_key",
+}
+
+func maskAuditValue(v interface{}) (res interface{}) {
+	if v == nil || v == "" {
+		return v
+	}
+	return constants.SecretMask
+}
+
+// maskAuditEnvs copy of environment variables of spiders or tasks, of which
+// values of secret ones are masked
+func maskAuditEnvs(v interface{}) (res interface{}) {
+	envs, ok := v.(primitive.A)
+	if !ok {
+		return v
+	}
+	var resEnvs primitive.A
+	for _, env := range envs {
+		switch e := env.(type) {
+		case bson.M:
+			if e["secret"] == true {
+				m := bson.M{}
+				for k, v := range e {
+					m[k] = v
+				}
+				m["value"] = maskAuditValue(m["value"])
+				env = m
+			}
+		case bson.D:
+			if e.Map()["secret"] == true {
+				var d bson.D
+				for _, el := range e {
+					if el.Key == "value" {
+						el.Value = maskAuditValue(el.Value)
+					}
+					d = append(d, el)
+				}
+				env = d
+			}
+		}
+		resEnvs = append(resEnvs, env)
+	}
+	return resEnvs
+}
@@ -1,11 +1,13 @@
 package delegate_test
 
 import (
+	"encoding/json"
 	"github.com/luke513009828/crawlab-core/interfaces"
 	"github.com/luke513009828/crawlab-core/models/delegate"
 	models2 "github.com/luke513009828/crawlab-core/models/models"
 	"github.com/crawlab-team/crawlab-db/mongo"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"testing"
 )
 
@@ -66,3 +68,49 @@ func TestProject_Delete(t *testing.T) {
 	require.NotNil(t, a.Obj)
 	require.True(t, a.Del)
 }
+
+func TestModelDelegate_Audit(t *testing.T) {
+	SetupTest(t)
+
+	s := &models2.Schedule{Name: "test schedule", Cron: "* * * * *"}
+	err := delegate.NewModelDelegate(s).Add()
+	require.Nil(t, err)
+
+	s.Cron = "0 * * * *"
+	err = delegate.NewModelDelegate(s).Save()
+	require.Nil(t, err)
+
+	// save without changes
+	err = delegate.NewModelDelegate(s).Save()
+	require.Nil(t, err)
+
+	var logs []models2.AuditLog
+	err = mongo.GetMongoCol(interfaces.ModelColNameAuditLog).Find(bson.M{"model_id": s.Id}, nil).All(&logs)
+	require.Nil(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, string(interfaces.ModelDelegateMethodAdd), logs[0].Method)
+	require.Equal(t, string(interfaces.ModelDelegateMethodSave), logs[1].Method)
+	require.Len(t, logs[1].Diffs, 1)
+	require.Equal(t, "cron", logs[1].Diffs[0].Key)
+	require.Equal(t, "* * * * *", logs[1].Diffs[0].Before)
+	require.Equal(t, "0 * * * *", logs[1].Diffs[0].After)
+}
+
+func TestModelDelegate_AuditSecretEnvs(t *testing.T) {
+	SetupTest(t)
+
+	s := &models2.Spider{Name: "test spider", Envs: []models2.Env{
+		{Name: "TOKEN", Value: "secret-value", Secret: true},
+		{Name: "MODE", Value: "test"},
+	}}
+	err := delegate.NewModelDelegate(s).Add()
+	require.Nil(t, err)
+
+	var l models2.AuditLog
+	err = mongo.GetMongoCol(interfaces.ModelColNameAuditLog).Find(bson.M{"model_id": s.Id}, nil).One(&l)
+	require.Nil(t, err)
+	data, err := json.Marshal(l.Diffs)
+	require.Nil(t, err)
+	require.NotContains(t, string(data), "secret-value")
+	require.Contains(t, string(data), "test")
+}
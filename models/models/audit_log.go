@@ -0,0 +1,30 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type AuditLog struct {
+	Id      primitive.ObjectID `json:"_id" bson:"_id"`
+	Col     string             `json:"col" bson:"col"`           // collection name of the model
+	ModelId primitive.ObjectID `json:"model_id" bson:"model_id"` // id of the model
+	Method  string             `json:"method" bson:"method"`     // interfaces.ModelDelegateMethod
+	UserId  primitive.ObjectID `json:"user_id" bson:"user_id"`   // User.Id, empty if changed by system
+	Diffs   []AuditLogDiff     `json:"diffs" bson:"diffs"`
+	Ts      time.Time          `json:"ts" bson:"ts"`
+}
+
+func (l *AuditLog) GetId() (id primitive.ObjectID) {
+	return l.Id
+}
+
+func (l *AuditLog) SetId(id primitive.ObjectID) {
+	l.Id = id
+}
+
+type AuditLogDiff struct {
+	Key    string      `json:"key" bson:"key"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
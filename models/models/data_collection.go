@@ -3,8 +3,9 @@ package models
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
 type DataCollection struct {
-	Id   primitive.ObjectID `json:"_id" bson:"_id"`
-	Name string             `json:"name" bson:"name"`
+	Id         primitive.ObjectID `json:"_id" bson:"_id"`
+	Name       string             `json:"name" bson:"name"`
+	SchemaMode string             `json:"schema_mode" bson:"schema_mode"` // constants.DataCollectionSchemaMode*, records are not validated against declared fields if empty
 }
 
 func (dc *DataCollection) GetId() (id primitive.ObjectID) {
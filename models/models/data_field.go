@@ -1,9 +1,40 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
 type DataField struct {
-	Id   primitive.ObjectID `json:"_id" bson:"_id"`
-	Key  string             `json:"key" bson:"key"`
-	Name string             `json:"name" bson:"name"`
+	Id          primitive.ObjectID `json:"_id" bson:"_id"`
+	ColId       primitive.ObjectID `json:"col_id" bson:"col_id"` // DataCollection.Id
+	Key         string             `json:"key" bson:"key"`
+	Name        string             `json:"name" bson:"name"`
+	Type        string             `json:"type" bson:"type"`             // declared constants.DataFieldType*, values of any type conform if empty
+	Required    bool               `json:"required" bson:"required"`     // whether records without the field or with null values do not conform
+	Types       map[string]int64   `json:"types" bson:"types"`           // numbers of records of observed constants.DataFieldType*
+	Count       int64              `json:"count" bson:"count"`           // number of records with non-null values
+	NullCount   int64              `json:"null_count" bson:"null_count"` // number of records with null values or without the field since first seen
+	NullRate    float64            `json:"null_rate" bson:"-"`
+	FirstTaskId primitive.ObjectID `json:"first_task_id" bson:"first_task_id"` // Task.Id of the first record with the field
+	LastTaskId  primitive.ObjectID `json:"last_task_id" bson:"last_task_id"`   // Task.Id of the last record with the field
+	FirstSeenTs time.Time          `json:"first_seen_ts" bson:"first_seen_ts"`
+	LastSeenTs  time.Time          `json:"last_seen_ts" bson:"last_seen_ts"`
+}
+
+func (f *DataField) GetId() (id primitive.ObjectID) {
+	return f.Id
+}
+
+func (f *DataField) SetId(id primitive.ObjectID) {
+	f.Id = id
+}
+
+// GetNullRate ratio of records with null values or without the field since first seen
+func (f *DataField) GetNullRate() (rate float64) {
+	total := f.Count + f.NullCount
+	if total == 0 {
+		return 0
+	}
+	return float64(f.NullCount) / float64(total)
 }
@@ -0,0 +1,15 @@
+package models_test
+
+import (
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDataField_GetNullRate(t *testing.T) {
+	f := &models2.DataField{}
+	require.Equal(t, float64(0), f.GetNullRate())
+
+	f = &models2.DataField{Count: 3, NullCount: 1}
+	require.Equal(t, 0.25, f.GetNullRate())
+}
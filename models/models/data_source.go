@@ -3,8 +3,19 @@ package models
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
 type DataSource struct {
-	Id   primitive.ObjectID `json:"_id" bson:"_id"`
-	Name string             `json:"name" bson:"name"`
+	Id          primitive.ObjectID `json:"_id" bson:"_id"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	Type        string             `json:"type" bson:"type"`               // constants.DataSourceType*, results are saved in data collections of MongoDB if empty
+	Hosts       []string           `json:"hosts" bson:"hosts"`             // addresses of database servers or kafka brokers, or urls of elasticsearch nodes
+	Database    string             `json:"database" bson:"database"`       // database of postgresql or mysql
+	SslMode     string             `json:"ssl_mode" bson:"ssl_mode"`       // sslmode of postgresql connections, constants.DefaultDataSourceSslMode if empty
+	Username    string             `json:"username" bson:"username"`       // username of authentication
+	Password    string             `json:"password" bson:"password"`       // password of authentication
+	Path        string             `json:"path" bson:"path"`               // directory of result files of the file data source
+	Format      string             `json:"format" bson:"format"`           // constants.ResultExportFormatJsonl (default) or constants.ResultExportFormatCsv of result files
+	BatchSize   int                `json:"batch_size" bson:"batch_size"`   // max number of records written at once, constants.DefaultDataSourceBatchSize if 0
+	MaxRetries  int                `json:"max_retries" bson:"max_retries"` // max number of retries of a failed batch, constants.DefaultDataSourceMaxRetries if 0
 }
 
 func (ds *DataSource) GetId() (id primitive.ObjectID) {
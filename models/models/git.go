@@ -10,6 +10,10 @@ type Git struct {
 	AuthType string             `json:"auth_type" bson:"auth_type"`
 	Username string             `json:"username" bson:"username"`
 	Password string             `json:"password" bson:"password"`
+
+	// SSH host key verification, of which the deploy key is models.GitSshKey
+	InsecureIgnoreHostKey bool   `json:"insecure_ignore_host_key" bson:"insecure_ignore_host_key"` // whether to skip verification of host keys
+	KnownHosts            string `json:"known_hosts" bson:"known_hosts"`                           // entries in known_hosts format, the host key is pinned on first use if empty
 }
 
 func (t *Git) GetId() (id primitive.ObjectID) {
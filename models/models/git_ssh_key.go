@@ -0,0 +1,24 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// GitSshKey SSH deploy key of the git repo, of which the id is the same as Git.Id
+type GitSshKey struct {
+	Id          primitive.ObjectID `json:"_id" bson:"_id"`
+	PrivateKey  string             `json:"-" bson:"private_key"`           // private key in PEM encrypted with the git secret key
+	PublicKey   string             `json:"public_key" bson:"public_key"`   // public key in authorized_keys format
+	Fingerprint string             `json:"fingerprint" bson:"fingerprint"` // SHA256 fingerprint of the public key
+	IsGenerated bool               `json:"is_generated" bson:"is_generated"`
+	Ts          time.Time          `json:"ts" bson:"ts"`
+}
+
+func (k *GitSshKey) GetId() (id primitive.ObjectID) {
+	return k.Id
+}
+
+func (k *GitSshKey) SetId(id primitive.ObjectID) {
+	k.Id = id
+}
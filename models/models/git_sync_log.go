@@ -0,0 +1,29 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type GitSyncLog struct {
+	Id         primitive.ObjectID   `json:"_id" bson:"_id"`
+	SpiderId   primitive.ObjectID   `json:"spider_id" bson:"spider_id"`
+	Trigger    string               `json:"trigger" bson:"trigger"` // constants.GitSyncTrigger*
+	Branch     string               `json:"branch" bson:"branch"`
+	Status     string               `json:"status" bson:"status"` // constants.GitSyncStatus*
+	Error      string               `json:"error" bson:"error"`
+	PrevCommit string               `json:"prev_commit" bson:"prev_commit"` // commit hash of HEAD before pull, empty if not committed yet
+	Commit     string               `json:"commit" bson:"commit"`           // commit hash of HEAD after pull
+	TaskIds    []primitive.ObjectID `json:"task_ids" bson:"task_ids"`       // tasks run on new commits
+	UserId     primitive.ObjectID   `json:"user_id" bson:"user_id"`         // User.Id, empty if synced automatically
+	StartTs    time.Time            `json:"start_ts" bson:"start_ts"`
+	EndTs      time.Time            `json:"end_ts" bson:"end_ts"`
+}
+
+func (l *GitSyncLog) GetId() (id primitive.ObjectID) {
+	return l.Id
+}
+
+func (l *GitSyncLog) SetId(id primitive.ObjectID) {
+	l.Id = id
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"github.com/luke513009828/crawlab-core/constants"
 	"github.com/luke513009828/crawlab-core/interfaces"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"time"
@@ -41,6 +42,24 @@ func (n *Node) SetTags(tags []interfaces.Tag) {
 	n.Tags = convertInterfacesToTags(tags)
 }
 
+// HasTags whether the node carries all (constants.NodeTagsMatchAll) or any
+// (constants.NodeTagsMatchAny) of the given tag names
+func (n *Node) HasTags(names []string, match string) (ok bool) {
+	tags := map[string]bool{}
+	for _, t := range n.Tags {
+		tags[t.Name] = true
+	}
+	for _, name := range names {
+		if match == constants.NodeTagsMatchAny && tags[name] {
+			return true
+		}
+		if match != constants.NodeTagsMatchAny && !tags[name] {
+			return false
+		}
+	}
+	return match != constants.NodeTagsMatchAny || len(names) == 0
+}
+
 func (n *Node) GetName() (name string) {
 	return n.Name
 }
@@ -0,0 +1,27 @@
+package models_test
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNode_HasTags(t *testing.T) {
+	n := &models2.Node{Tags: []models2.Tag{{Name: "gpu"}, {Name: "us-east"}}}
+
+	// all
+	require.True(t, n.HasTags([]string{"gpu"}, constants.NodeTagsMatchAll))
+	require.True(t, n.HasTags([]string{"gpu", "us-east"}, constants.NodeTagsMatchAll))
+	require.False(t, n.HasTags([]string{"gpu", "eu-west"}, constants.NodeTagsMatchAll))
+	require.True(t, n.HasTags([]string{"gpu", "us-east"}, ""))
+	require.False(t, n.HasTags([]string{"gpu", "eu-west"}, ""))
+
+	// any
+	require.True(t, n.HasTags([]string{"gpu", "eu-west"}, constants.NodeTagsMatchAny))
+	require.False(t, n.HasTags([]string{"cpu", "eu-west"}, constants.NodeTagsMatchAny))
+
+	// empty
+	require.True(t, n.HasTags(nil, constants.NodeTagsMatchAll))
+	require.True(t, n.HasTags(nil, constants.NodeTagsMatchAny))
+}
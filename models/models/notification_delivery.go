@@ -0,0 +1,28 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type NotificationDelivery struct {
+	Id         primitive.ObjectID `json:"_id" bson:"_id"`
+	SettingId  primitive.ObjectID `json:"setting_id" bson:"setting_id"` // NotificationSetting.Id, empty if sent to Spider.WebHookUrl
+	TaskId     primitive.ObjectID `json:"task_id" bson:"task_id"`       // Task.Id
+	SpiderId   primitive.ObjectID `json:"spider_id" bson:"spider_id"`   // Spider.Id
+	TaskStatus string             `json:"task_status" bson:"task_status"`
+	Type       string             `json:"type" bson:"type"` // constants.NotificationType*
+	Title      string             `json:"title" bson:"title"`
+	Content    string             `json:"content" bson:"content"`
+	Status     string             `json:"status" bson:"status"` // constants.NotificationDeliveryStatus*
+	Error      string             `json:"error" bson:"error"`
+	Ts         time.Time          `json:"ts" bson:"ts"`
+}
+
+func (d *NotificationDelivery) GetId() (id primitive.ObjectID) {
+	return d.Id
+}
+
+func (d *NotificationDelivery) SetId(id primitive.ObjectID) {
+	d.Id = id
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationSetting struct {
+	Id          primitive.ObjectID   `json:"_id" bson:"_id"`
+	Name        string               `json:"name" bson:"name"`
+	Description string               `json:"description" bson:"description"`
+	Type        string               `json:"type" bson:"type"` // constants.NotificationType*
+	Enabled     bool                 `json:"enabled" bson:"enabled"`
+	Triggers    []string             `json:"triggers" bson:"triggers"`     // constants.NotificationTrigger*, constants.NotificationTriggerOnTaskEnd if empty
+	UserId      primitive.ObjectID   `json:"user_id" bson:"user_id"`       // only notify tasks run by the User.Id if not empty
+	SpiderIds   []primitive.ObjectID `json:"spider_ids" bson:"spider_ids"` // only notify tasks of the list of Spider.Id if not empty
+	Title       string               `json:"title" bson:"title"`           // text/template of the title, default title if empty
+	Template    string               `json:"template" bson:"template"`     // text/template of the content, default content if empty
+	Webhook     string               `json:"webhook" bson:"webhook"`       // url of the web hook, DingTalk or WeChat robot
+	Mail        []string             `json:"mail" bson:"mail"`             // mail recipients, email of the user if empty
+}
+
+func (s *NotificationSetting) GetId() (id primitive.ObjectID) {
+	return s.Id
+}
+
+func (s *NotificationSetting) SetId(id primitive.ObjectID) {
+	s.Id = id
+}
+
+// IsTriggered whether the notification is triggered by the given task status
+func (s *NotificationSetting) IsTriggered(status string) (ok bool) {
+	triggers := s.Triggers
+	if len(triggers) == 0 {
+		triggers = []string{constants.NotificationTriggerOnTaskEnd}
+	}
+	for _, trigger := range triggers {
+		switch trigger {
+		case constants.NotificationTriggerOnTaskEnd:
+			switch status {
+			case constants.TaskStatusFinished,
+				constants.TaskStatusError,
+				constants.TaskStatusCancelled,
+				constants.TaskStatusTimeout:
+				return true
+			}
+		case constants.NotificationTriggerOnTaskFinish:
+			if status == constants.TaskStatusFinished {
+				return true
+			}
+		case constants.NotificationTriggerOnTaskError:
+			if status == constants.TaskStatusError || status == constants.TaskStatusTimeout {
+				return true
+			}
+		case constants.NotificationTriggerOnTaskCancel:
+			if status == constants.TaskStatusCancelled {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsMatched whether the notification applies to the task of the given spider
+// run by the given user
+func (s *NotificationSetting) IsMatched(spiderId, userId primitive.ObjectID) (ok bool) {
+	if !s.Enabled {
+		return false
+	}
+	if !s.UserId.IsZero() && s.UserId != userId {
+		return false
+	}
+	if len(s.SpiderIds) == 0 {
+		return true
+	}
+	for _, id := range s.SpiderIds {
+		if id == spiderId {
+			return true
+		}
+	}
+	return false
+}
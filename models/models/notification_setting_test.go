@@ -0,0 +1,60 @@
+package models_test
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestNotificationSetting_IsTriggered(t *testing.T) {
+	// default
+	s := &models2.NotificationSetting{}
+	require.True(t, s.IsTriggered(constants.TaskStatusFinished))
+	require.True(t, s.IsTriggered(constants.TaskStatusError))
+	require.True(t, s.IsTriggered(constants.TaskStatusCancelled))
+	require.True(t, s.IsTriggered(constants.TaskStatusTimeout))
+	require.False(t, s.IsTriggered(constants.TaskStatusRunning))
+
+	// error
+	s.Triggers = []string{constants.NotificationTriggerOnTaskError}
+	require.False(t, s.IsTriggered(constants.TaskStatusFinished))
+	require.True(t, s.IsTriggered(constants.TaskStatusError))
+	require.True(t, s.IsTriggered(constants.TaskStatusTimeout))
+	require.False(t, s.IsTriggered(constants.TaskStatusCancelled))
+
+	// finish and cancel
+	s.Triggers = []string{constants.NotificationTriggerOnTaskFinish, constants.NotificationTriggerOnTaskCancel}
+	require.True(t, s.IsTriggered(constants.TaskStatusFinished))
+	require.False(t, s.IsTriggered(constants.TaskStatusError))
+	require.True(t, s.IsTriggered(constants.TaskStatusCancelled))
+
+	// never
+	s.Triggers = []string{constants.NotificationTriggerNever}
+	require.False(t, s.IsTriggered(constants.TaskStatusFinished))
+	require.False(t, s.IsTriggered(constants.TaskStatusError))
+}
+
+func TestNotificationSetting_IsMatched(t *testing.T) {
+	spiderId := primitive.NewObjectID()
+	userId := primitive.NewObjectID()
+
+	// disabled
+	s := &models2.NotificationSetting{}
+	require.False(t, s.IsMatched(spiderId, userId))
+
+	// global
+	s.Enabled = true
+	require.True(t, s.IsMatched(spiderId, userId))
+
+	// user
+	s.UserId = userId
+	require.True(t, s.IsMatched(spiderId, userId))
+	require.False(t, s.IsMatched(spiderId, primitive.NewObjectID()))
+
+	// spiders
+	s.SpiderIds = []primitive.ObjectID{spiderId}
+	require.True(t, s.IsMatched(spiderId, userId))
+	require.False(t, s.IsMatched(primitive.NewObjectID(), userId))
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type ResultExport struct {
+	Id         primitive.ObjectID `json:"_id" bson:"_id"`
+	ColId      primitive.ObjectID `json:"col_id" bson:"col_id"`         // DataCollection.Id
+	TaskId     primitive.ObjectID `json:"task_id" bson:"task_id"`       // Task.Id if only results of the task are exported
+	Format     string             `json:"format" bson:"format"`         // constants.ResultExportFormat*
+	Conditions string             `json:"conditions" bson:"conditions"` // filter conditions in json
+	Status     string             `json:"status" bson:"status"`         // constants.ResultExportStatus*
+	Error      string             `json:"error" bson:"error"`
+	Count      int                `json:"count" bson:"count"` // exported records
+	FileName   string             `json:"file_name" bson:"file_name"`
+	CreateTs   time.Time          `json:"create_ts" bson:"create_ts"`
+	EndTs      time.Time          `json:"end_ts" bson:"end_ts"`
+}
+
+func (e *ResultExport) GetId() (id primitive.ObjectID) {
+	return e.Id
+}
+
+func (e *ResultExport) SetId(id primitive.ObjectID) {
+	e.Id = id
+}
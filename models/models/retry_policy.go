@@ -0,0 +1,47 @@
+package models
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int      `json:"max_attempts" bson:"max_attempts"` // max number of retries, 0 means no retry
+	Backoff     string   `json:"backoff" bson:"backoff"`           // constants.TaskRetryBackoffFixed or constants.TaskRetryBackoffExponential
+	Interval    int      `json:"interval" bson:"interval"`         // (base) interval between retries in seconds
+	Statuses    []string `json:"statuses" bson:"statuses"`         // retryable conditions, constants.TaskRetryOnError and constants.TaskRetryOnLost by default
+}
+
+func (p *RetryPolicy) IsEnabled() (ok bool) {
+	return p != nil && p.MaxAttempts > 0
+}
+
+// IsRetryable whether the task ended with a retryable status and has attempts left
+func (p *RetryPolicy) IsRetryable(t *Task) (ok bool) {
+	if !p.IsEnabled() || t.Attempt >= p.MaxAttempts {
+		return false
+	}
+	statuses := p.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{constants.TaskRetryOnError, constants.TaskRetryOnLost}
+	}
+	status := t.Status
+	if status == constants.TaskStatusError && t.Error == constants.ErrTaskLost.Error() {
+		status = constants.TaskRetryOnLost
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDelay get delay before the given attempt (starting from 1)
+func (p *RetryPolicy) GetDelay(attempt int) (d time.Duration) {
+	d = time.Duration(p.Interval) * time.Second
+	if p.Backoff == constants.TaskRetryBackoffExponential && attempt > 1 {
+		d = d * time.Duration(1<<uint(attempt-1))
+	}
+	return d
+}
@@ -0,0 +1,42 @@
+package models_test
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_IsRetryable(t *testing.T) {
+	p := &models2.RetryPolicy{MaxAttempts: 2}
+
+	// default retryable statuses
+	require.True(t, p.IsRetryable(&models2.Task{Status: constants.TaskStatusError}))
+	require.True(t, p.IsRetryable(&models2.Task{Status: constants.TaskStatusError, Error: constants.ErrTaskLost.Error()}))
+	require.False(t, p.IsRetryable(&models2.Task{Status: constants.TaskStatusCancelled}))
+	require.False(t, p.IsRetryable(&models2.Task{Status: constants.TaskStatusFinished}))
+
+	// max attempts reached
+	require.False(t, p.IsRetryable(&models2.Task{Status: constants.TaskStatusError, Attempt: 2}))
+
+	// lost only
+	p.Statuses = []string{constants.TaskRetryOnLost}
+	require.False(t, p.IsRetryable(&models2.Task{Status: constants.TaskStatusError}))
+	require.True(t, p.IsRetryable(&models2.Task{Status: constants.TaskStatusError, Error: constants.ErrTaskLost.Error()}))
+
+	// disabled
+	p = &models2.RetryPolicy{}
+	require.False(t, p.IsRetryable(&models2.Task{Status: constants.TaskStatusError}))
+}
+
+func TestRetryPolicy_GetDelay(t *testing.T) {
+	p := &models2.RetryPolicy{MaxAttempts: 3, Interval: 10, Backoff: constants.TaskRetryBackoffFixed}
+	require.Equal(t, 10*time.Second, p.GetDelay(1))
+	require.Equal(t, 10*time.Second, p.GetDelay(3))
+
+	p.Backoff = constants.TaskRetryBackoffExponential
+	require.Equal(t, 10*time.Second, p.GetDelay(1))
+	require.Equal(t, 20*time.Second, p.GetDelay(2))
+	require.Equal(t, 40*time.Second, p.GetDelay(3))
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"github.com/robfig/cron/v3"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
 )
 
 type Schedule struct {
@@ -17,12 +18,19 @@ type Schedule struct {
 	Mode           string               `json:"mode" bson:"mode"`
 	NodeIds        []primitive.ObjectID `json:"node_ids" bson:"node_ids"`
 	NodeTags       []string             `json:"node_tags" bson:"node_tags"`
+	NodeTagsMatch  string               `json:"node_tags_match" bson:"node_tags_match"`
 	Priority       int                  `json:"priority" bson:"priority"`
+	Timeout        int                  `json:"timeout" bson:"timeout"` // Task.Timeout in seconds, default to Spider.Timeout
 	Enabled        bool                 `json:"enabled" bson:"enabled"`
 	UserId         primitive.ObjectID   `json:"user_id" bson:"user_id"`
 	ScrapySpider   string               `json:"scrapy_spider" bson:"scrapy_spider"`
 	ScrapyLogLevel string               `json:"scrapy_log_level" bson:"scrapy_log_level"`
 	Tags           []string             `json:"tags" bson:"-"`
+	RetryPolicy    RetryPolicy          `json:"retry_policy" bson:"retry_policy"`     // overrides Spider.RetryPolicy if enabled
+	OverlapPolicy  string               `json:"overlap_policy" bson:"overlap_policy"` // what to do if previous run is still running, constants.ScheduleOverlapPolicyAllow by default
+	LastSkipTs     time.Time            `json:"last_skip_ts" bson:"last_skip_ts"`     // last time a cron tick is skipped
+	LastSkipReason string               `json:"last_skip_reason" bson:"last_skip_reason"`
+	SkipCount      int                  `json:"skip_count" bson:"skip_count"`
 }
 
 func (s *Schedule) GetId() (id primitive.ObjectID) {
@@ -6,32 +6,37 @@ import (
 )
 
 type Env struct {
-	Name  string `json:"name" bson:"name"`
-	Value string `json:"value" bson:"value"`
+	Name   string `json:"name" bson:"name"`
+	Value  string `json:"value" bson:"value"`
+	Secret bool   `json:"secret" bson:"secret"` // whether value should be masked in task logs and spider archives
 }
 
 type Spider struct {
-	Id          primitive.ObjectID   `json:"_id" bson:"_id"`                 // spider id
-	Name        string               `json:"name" bson:"name"`               // spider name
-	Type        string               `json:"type" bson:"type"`               // spider type
-	ColId       primitive.ObjectID   `json:"col_id" bson:"col_id"`           // data collection id
-	ColName     string               `json:"col_name,omitempty" bson:"-"`    // data collection name
-	Description string               `json:"description" bson:"description"` // description
-	ProjectId   primitive.ObjectID   `json:"project_id" bson:"project_id"`   // Project.Id
-	Mode        string               `json:"mode" bson:"mode"`               // default Task.Mode
-	NodeIds     []primitive.ObjectID `json:"node_ids" bson:"node_ids"`       // default Task.NodeIds
-	NodeTags    []string             `json:"node_tags" bson:"node_tags"`     // default Task.NodeTags
-	Tags        []Tag                `json:"tags" bson:"-"`                  // tags
-	Stat        *SpiderStat          `json:"stat,omitempty" bson:"-"`
-	GitId       primitive.ObjectID   `json:"git_id" bson:"git_id"`
+	Id            primitive.ObjectID   `json:"_id" bson:"_id"`                         // spider id
+	Name          string               `json:"name" bson:"name"`                       // spider name
+	Type          string               `json:"type" bson:"type"`                       // spider type
+	ColId         primitive.ObjectID   `json:"col_id" bson:"col_id"`                   // data collection id
+	ColName       string               `json:"col_name,omitempty" bson:"-"`            // data collection name
+	DataSourceId  primitive.ObjectID   `json:"data_source_id" bson:"data_source_id"`   // data source of results, saved in the data collection of MongoDB if empty
+	Description   string               `json:"description" bson:"description"`         // description
+	ProjectId     primitive.ObjectID   `json:"project_id" bson:"project_id"`           // Project.Id
+	Mode          string               `json:"mode" bson:"mode"`                       // default Task.Mode
+	NodeIds       []primitive.ObjectID `json:"node_ids" bson:"node_ids"`               // default Task.NodeIds
+	NodeTags      []string             `json:"node_tags" bson:"node_tags"`             // default Task.NodeTags
+	NodeTagsMatch string               `json:"node_tags_match" bson:"node_tags_match"` // default Task.NodeTagsMatch
+	Tags          []Tag                `json:"tags" bson:"-"`                          // tags
+	Stat          *SpiderStat          `json:"stat,omitempty" bson:"-"`
+	GitId         primitive.ObjectID   `json:"git_id" bson:"git_id"`
 
 	IsPublic bool  `json:"is_public" bson:"is_public"` // 是否公开
 	Envs     []Env `json:"envs" bson:"envs"`           // 环境变量
 
 	// 自定义爬虫
-	Cmd      string `json:"cmd" bson:"cmd"`     // 执行命令
-	Param    string `json:"param" bson:"param"` // default task param
-	Priority int    `json:"priority" bson:"priority"`
+	Cmd            string `json:"cmd" bson:"cmd"`     // 执行命令
+	Param          string `json:"param" bson:"param"` // default task param
+	Priority       int    `json:"priority" bson:"priority"`
+	Timeout        int    `json:"timeout" bson:"timeout"`                 // default Task.Timeout in seconds
+	MaxConcurrency int    `json:"max_concurrency" bson:"max_concurrency"` // max number of running tasks, 0 means no limit
 
 	// Scrapy 爬虫（属于自定义爬虫）
 	IsScrapy    bool     `json:"is_scrapy" bson:"is_scrapy"`       // 是否为 Scrapy 爬虫
@@ -50,6 +55,10 @@ type Spider struct {
 	GitAutoSync      bool   `json:"git_auto_sync" bson:"git_auto_sync"`           // Git 是否自动同步
 	GitSyncFrequency string `json:"git_sync_frequency" bson:"git_sync_frequency"` // Git 同步频率
 	GitSyncError     string `json:"git_sync_error" bson:"git_sync_error"`         // Git 同步错误
+	GitAutoRun       bool   `json:"git_auto_run" bson:"git_auto_run"`             // Git 自动同步到新提交后是否运行
+
+	// 重试策略
+	RetryPolicy RetryPolicy `json:"retry_policy" bson:"retry_policy"` // 失败任务重试策略
 
 	// 长任务
 	IsLongTask bool `json:"is_long_task" bson:"is_long_task"` // 是否为长任务
@@ -135,3 +144,7 @@ func (s *Spider) GetPriority() (p int) {
 func (s *Spider) SetPriority(p int) {
 	s.Priority = p
 }
+
+func (s *Spider) GetIsLongTask() (ok bool) {
+	return s.IsLongTask
+}
@@ -5,25 +5,34 @@ import (
 )
 
 type Task struct {
-	Id         primitive.ObjectID   `json:"_id" bson:"_id"`
-	SpiderId   primitive.ObjectID   `json:"spider_id" bson:"spider_id"`
-	Status     string               `json:"status" bson:"status"`
-	NodeId     primitive.ObjectID   `json:"node_id" bson:"node_id"`
-	Cmd        string               `json:"cmd" bson:"cmd"`
-	Param      string               `json:"param" bson:"param"`
-	Error      string               `json:"error" bson:"error"`
-	Pid        int                  `json:"pid" bson:"pid"`
-	ScheduleId primitive.ObjectID   `json:"schedule_id" bson:"schedule_id"` // Schedule.Id
-	Type       string               `json:"type" bson:"type"`
-	Mode       string               `json:"mode" bson:"mode"`           // running mode of Task
-	NodeIds    []primitive.ObjectID `json:"node_ids" bson:"node_ids"`   // list of Node.Id
-	NodeTags   []string             `json:"node_tags" bson:"node_tags"` // list of Node.Tag
-	ParentId   primitive.ObjectID   `json:"parent_id" bson:"parent_id"` // parent Task.Id if it'Spider a sub-task
-	Priority   int                  `json:"priority" bson:"priority"`
-	Stat       *TaskStat            `json:"stat,omitempty" bson:"-"`
-	HasSub     bool                 `json:"has_sub" json:"has_sub"` // whether to have sub-tasks
-	SubTasks   []Task               `json:"sub_tasks,omitempty" bson:"-"`
-	UserId     primitive.ObjectID   `json:"-" bson:"-"`
+	Id              primitive.ObjectID   `json:"_id" bson:"_id"`
+	SpiderId        primitive.ObjectID   `json:"spider_id" bson:"spider_id"`
+	Status          string               `json:"status" bson:"status"`
+	NodeId          primitive.ObjectID   `json:"node_id" bson:"node_id"`
+	Cmd             string               `json:"cmd" bson:"cmd"`
+	Param           string               `json:"param" bson:"param"`
+	Error           string               `json:"error" bson:"error"`
+	Pid             int                  `json:"pid" bson:"pid"`
+	ScheduleId      primitive.ObjectID   `json:"schedule_id" bson:"schedule_id"` // Schedule.Id
+	Type            string               `json:"type" bson:"type"`
+	Mode            string               `json:"mode" bson:"mode"`                       // running mode of Task
+	NodeIds         []primitive.ObjectID `json:"node_ids" bson:"node_ids"`               // list of Node.Id
+	NodeTags        []string             `json:"node_tags" bson:"node_tags"`             // list of Node.Tag
+	NodeTagsMatch   string               `json:"node_tags_match" bson:"node_tags_match"` // constants.NodeTagsMatchAll (default) or constants.NodeTagsMatchAny
+	PendingReason   string               `json:"pending_reason" bson:"pending_reason"`   // reason why the task is still pending in the task queue
+	ParentId        primitive.ObjectID   `json:"parent_id" bson:"parent_id"`             // parent Task.Id if it's a sub-task or a retry
+	Attempt         int                  `json:"attempt" bson:"attempt"`                 // retry attempt, 0 if it's the original task
+	Priority        int                  `json:"priority" bson:"priority"`
+	Envs            []Env                `json:"envs" bson:"envs"`                           // task-level environment variables overriding Spider.Envs
+	Timeout         int                  `json:"timeout" bson:"timeout"`                     // execution timeout in seconds, 0 means no timeout
+	WorkflowRunId   primitive.ObjectID   `json:"workflow_run_id" bson:"workflow_run_id"`     // WorkflowRun.Id if it's triggered by a workflow
+	RestartCount    int                  `json:"restart_count" bson:"restart_count"`         // restarts of the process if it's a long task (Spider.IsLongTask)
+	Version         string               `json:"version" bson:"version"`                     // commit hash of the version of spider files, the latest committed version if not pinned
+	IsVersionPinned bool                 `json:"is_version_pinned" bson:"is_version_pinned"` // whether to run files of Version instead of the latest files
+	Stat            *TaskStat            `json:"stat,omitempty" bson:"-"`
+	HasSub          bool                 `json:"has_sub" json:"has_sub"` // whether to have sub-tasks
+	SubTasks        []Task               `json:"sub_tasks,omitempty" bson:"-"`
+	UserId          primitive.ObjectID   `json:"-" bson:"-"`
 }
 
 func (t *Task) GetId() (id primitive.ObjectID) {
@@ -86,6 +95,10 @@ func (t *Task) GetPriority() (p int) {
 	return t.Priority
 }
 
+func (t *Task) GetTimeout() (timeout int) {
+	return t.Timeout
+}
+
 func (t *Task) GetUserId() (id primitive.ObjectID) {
 	return t.UserId
 }
@@ -94,6 +107,22 @@ func (t *Task) SetUserId(id primitive.ObjectID) {
 	t.UserId = id
 }
 
+func (t *Task) GetVersion() (version string) {
+	return t.Version
+}
+
+func (t *Task) GetIsVersionPinned() (ok bool) {
+	return t.IsVersionPinned
+}
+
+func (t *Task) GetRestartCount() (c int) {
+	return t.RestartCount
+}
+
+func (t *Task) SetRestartCount(c int) {
+	t.RestartCount = c
+}
+
 type TaskDailyItem struct {
 	Date               string  `json:"date" bson:"_id"`
 	TaskCount          int     `json:"task_count" bson:"task_count"`
@@ -1,10 +1,17 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
 type TaskQueueItem struct {
-	Id       primitive.ObjectID `json:"_id" bson:"_id"`
-	Priority int                `json:"p" bson:"p"`
+	Id        primitive.ObjectID `json:"_id" bson:"_id"`
+	Priority  int                `json:"p" bson:"p"`
+	SpiderId  primitive.ObjectID `json:"sid" bson:"sid"`                   // Task.SpiderId
+	UserId    primitive.ObjectID `json:"uid" bson:"uid"`                   // Task.UserId
+	Deferred  bool               `json:"d" bson:"d"`                       // whether no nodes match node tags of the task, which is deferred after other tasks
+	NotBefore time.Time          `json:"nb,omitempty" bson:"nb,omitempty"` // time before which the task is not dequeued, e.g. backoff delay of retries
 }
 
 func (t *TaskQueueItem) GetId() (id primitive.ObjectID) {
@@ -6,15 +6,19 @@ import (
 )
 
 type TaskStat struct {
-	Id              primitive.ObjectID `json:"_id" bson:"_id"`
-	CreateTs        time.Time          `json:"create_ts" bson:"create_ts,omitempty"`
-	StartTs         time.Time          `json:"start_ts" bson:"start_ts,omitempty"`
-	EndTs           time.Time          `json:"end_ts" bson:"end_ts,omitempty"`
-	WaitDuration    int64              `json:"wait_duration" bson:"wait_duration,omitempty"`       // in millisecond
-	RuntimeDuration int64              `json:"runtime_duration" bson:"runtime_duration,omitempty"` // in millisecond
-	TotalDuration   int64              `json:"total_duration" bson:"total_duration,omitempty"`     // in millisecond
-	ResultCount     int64              `json:"result_count" bson:"result_count"`
-	ErrorLogCount   int64              `json:"error_log_count" bson:"error_log_count"`
+	Id                primitive.ObjectID `json:"_id" bson:"_id"`
+	CreateTs          time.Time          `json:"create_ts" bson:"create_ts,omitempty"`
+	StartTs           time.Time          `json:"start_ts" bson:"start_ts,omitempty"`
+	EndTs             time.Time          `json:"end_ts" bson:"end_ts,omitempty"`
+	WaitDuration      int64              `json:"wait_duration" bson:"wait_duration,omitempty"`       // in millisecond
+	RuntimeDuration   int64              `json:"runtime_duration" bson:"runtime_duration,omitempty"` // in millisecond
+	TotalDuration     int64              `json:"total_duration" bson:"total_duration,omitempty"`     // in millisecond
+	ResultCount       int64              `json:"result_count" bson:"result_count"`                   // new records inserted
+	ResultSkipCount   int64              `json:"result_skip_count" bson:"result_skip_count"`         // duplicated records skipped
+	ResultUpdateCount int64              `json:"result_update_count" bson:"result_update_count"`     // existing records overwritten or versioned
+	ResultErrorCount  int64              `json:"result_error_count" bson:"result_error_count"`       // records not conforming to the schema of the data collection
+	ErrorLogCount     int64              `json:"error_log_count" bson:"error_log_count"`
+	TimedOut          bool               `json:"timed_out" bson:"timed_out"` // whether the task was killed due to timeout
 }
 
 func (s *TaskStat) GetId() (id primitive.ObjectID) {
@@ -81,6 +85,38 @@ func (s *TaskStat) SetResultCount(c int64) {
 	s.ResultCount = c
 }
 
+func (s *TaskStat) GetResultSkipCount() (c int64) {
+	return s.ResultSkipCount
+}
+
+func (s *TaskStat) SetResultSkipCount(c int64) {
+	s.ResultSkipCount = c
+}
+
+func (s *TaskStat) GetResultUpdateCount() (c int64) {
+	return s.ResultUpdateCount
+}
+
+func (s *TaskStat) SetResultUpdateCount(c int64) {
+	s.ResultUpdateCount = c
+}
+
+func (s *TaskStat) GetResultErrorCount() (c int64) {
+	return s.ResultErrorCount
+}
+
+func (s *TaskStat) SetResultErrorCount(c int64) {
+	s.ResultErrorCount = c
+}
+
+func (s *TaskStat) GetTimedOut() (ok bool) {
+	return s.TimedOut
+}
+
+func (s *TaskStat) SetTimedOut(ok bool) {
+	s.TimedOut = ok
+}
+
 func (s *TaskStat) GetErrorLogCount() (c int64) {
 	return s.ErrorLogCount
 }
@@ -2,12 +2,18 @@ package models
 
 import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
 )
 
 type Token struct {
-	Id    primitive.ObjectID `json:"_id" bson:"_id"`
-	Name  string             `json:"name" bson:"name"`
-	Token string             `json:"token" bson:"token"`
+	Id         primitive.ObjectID `json:"_id" bson:"_id"`
+	Name       string             `json:"name" bson:"name"`
+	Token      string             `json:"token,omitempty" bson:"-"`         // plain token, only returned on creation
+	TokenHash  string             `json:"-" bson:"token_hash"`              // hash of the token, checked on authentication
+	UserId     primitive.ObjectID `json:"user_id" bson:"user_id"`           // User.Id of the owner
+	Scopes     []string           `json:"scopes" bson:"scopes"`             // constants.TokenScope*
+	ExpireTs   time.Time          `json:"expire_ts" bson:"expire_ts"`       // never expires if empty
+	LastUsedTs time.Time          `json:"last_used_ts" bson:"last_used_ts"` // last time the token was used
 }
 
 func (t *Token) GetId() (id primitive.ObjectID) {
@@ -17,3 +23,8 @@ func (t *Token) GetId() (id primitive.ObjectID) {
 func (t *Token) SetId(id primitive.ObjectID) {
 	t.Id = id
 }
+
+// IsExpired whether the token has expired at the given time
+func (t *Token) IsExpired(now time.Time) bool {
+	return !t.ExpireTs.IsZero() && !now.Before(t.ExpireTs)
+}
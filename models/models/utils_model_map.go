@@ -1,55 +1,73 @@
 package models
 
 type ModelMap struct {
-	Artifact       Artifact
-	Tag            Tag
-	Node           Node
-	Project        Project
-	Spider         Spider
-	Task           Task
-	Job            Job
-	Schedule       Schedule
-	User           User
-	Setting        Setting
-	Token          Token
-	Variable       Variable
-	TaskQueueItem  TaskQueueItem
-	TaskStat       TaskStat
-	Plugin         Plugin
-	SpiderStat     SpiderStat
-	DataSource     DataSource
-	DataCollection DataCollection
-	Result         Result
-	Password       Password
-	ExtraValue     ExtraValue
-	PluginStatus   PluginStatus
-	Git            Git
+	Artifact             Artifact
+	Tag                  Tag
+	Node                 Node
+	Project              Project
+	Spider               Spider
+	Task                 Task
+	Job                  Job
+	Schedule             Schedule
+	User                 User
+	Setting              Setting
+	Token                Token
+	Variable             Variable
+	TaskQueueItem        TaskQueueItem
+	TaskStat             TaskStat
+	Plugin               Plugin
+	SpiderStat           SpiderStat
+	DataSource           DataSource
+	DataCollection       DataCollection
+	Result               Result
+	Password             Password
+	ExtraValue           ExtraValue
+	PluginStatus         PluginStatus
+	Git                  Git
+	Workflow             Workflow
+	WorkflowRun          WorkflowRun
+	NotificationSetting  NotificationSetting
+	NotificationDelivery NotificationDelivery
+	AuditLog             AuditLog
+	ResultExport         ResultExport
+	DataField            DataField
+	GitSyncLog           GitSyncLog
+	GitSshKey            GitSshKey
 }
 
 type ModelListMap struct {
-	Artifacts       []Artifact
-	Tags            []Tag
-	Nodes           []Node
-	Projects        []Project
-	Spiders         []Spider
-	Tasks           []Task
-	Jobs            []Job
-	Schedules       []Schedule
-	Users           []User
-	Settings        []Setting
-	Tokens          []Token
-	Variables       []Variable
-	TaskQueueItems  []TaskQueueItem
-	TaskStats       []TaskStat
-	Plugins         []Plugin
-	SpiderStats     []SpiderStat
-	DataSources     []DataSource
-	DataCollections []DataCollection
-	Results         []Result
-	Passwords       []Password
-	ExtraValues     []ExtraValue
-	PluginStatus    []PluginStatus
-	Gits            []Git
+	Artifacts              []Artifact
+	Tags                   []Tag
+	Nodes                  []Node
+	Projects               []Project
+	Spiders                []Spider
+	Tasks                  []Task
+	Jobs                   []Job
+	Schedules              []Schedule
+	Users                  []User
+	Settings               []Setting
+	Tokens                 []Token
+	Variables              []Variable
+	TaskQueueItems         []TaskQueueItem
+	TaskStats              []TaskStat
+	Plugins                []Plugin
+	SpiderStats            []SpiderStat
+	DataSources            []DataSource
+	DataCollections        []DataCollection
+	Results                []Result
+	Passwords              []Password
+	ExtraValues            []ExtraValue
+	PluginStatus           []PluginStatus
+	Gits                   []Git
+	Workflows              []Workflow
+	WorkflowRuns           []WorkflowRun
+	NotificationSettings   []NotificationSetting
+	NotificationDeliveries []NotificationDelivery
+	AuditLogs              []AuditLog
+	ResultExports          []ResultExport
+	DataFields             []DataField
+	GitSyncLogs            []GitSyncLog
+	GitSshKeys             []GitSshKey
 }
 
 func NewModelMap() (m *ModelMap) {
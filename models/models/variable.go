@@ -9,6 +9,7 @@ type Variable struct {
 	Key    string             `json:"key" bson:"key"`
 	Value  string             `json:"value" bson:"value"`
 	Remark string             `json:"remark" bson:"remark"`
+	Secret bool               `json:"secret" bson:"secret"` // whether value should be masked in task logs
 }
 
 func (v *Variable) GetId() (id primitive.ObjectID) {
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Workflow struct {
+	Id          primitive.ObjectID `json:"_id" bson:"_id"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	Nodes       []WorkflowNode     `json:"nodes" bson:"nodes"`
+	Edges       []WorkflowEdge     `json:"edges" bson:"edges"`
+}
+
+func (w *Workflow) GetId() (id primitive.ObjectID) {
+	return w.Id
+}
+
+func (w *Workflow) SetId(id primitive.ObjectID) {
+	w.Id = id
+}
+
+// Validate check if nodes and edges of the workflow form a valid DAG
+func (w *Workflow) Validate() (err error) {
+	if len(w.Nodes) == 0 {
+		return errors.ErrorWorkflowEmptyNodes
+	}
+
+	// nodes
+	keys := map[string]bool{}
+	for _, n := range w.Nodes {
+		if n.Key == "" {
+			return errors.ErrorWorkflowEmptyNodeKey
+		}
+		if keys[n.Key] {
+			return errors.ErrorWorkflowDuplicateNodeKey
+		}
+		if n.Type != "" && n.Type != constants.TaskTypeSpider && n.Type != constants.TaskTypeSystem {
+			return errors.ErrorWorkflowInvalidNodeType
+		}
+		if n.SpiderId.IsZero() {
+			return errors.ErrorWorkflowEmptySpiderId
+		}
+		keys[n.Key] = true
+	}
+
+	// edges
+	for _, e := range w.Edges {
+		if !keys[e.Source] || !keys[e.Target] || e.Source == e.Target {
+			return errors.ErrorWorkflowInvalidEdge
+		}
+		switch e.Condition {
+		case "", constants.WorkflowEdgeConditionSuccess, constants.WorkflowEdgeConditionFailure, constants.WorkflowEdgeConditionAlways:
+		default:
+			return errors.ErrorWorkflowInvalidCondition
+		}
+	}
+
+	// cycles (Kahn's algorithm)
+	inDegrees := map[string]int{}
+	for _, e := range w.Edges {
+		inDegrees[e.Target]++
+	}
+	var queue []string
+	for _, n := range w.Nodes {
+		if inDegrees[n.Key] == 0 {
+			queue = append(queue, n.Key)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, e := range w.Edges {
+			if e.Source != key {
+				continue
+			}
+			inDegrees[e.Target]--
+			if inDegrees[e.Target] == 0 {
+				queue = append(queue, e.Target)
+			}
+		}
+	}
+	if visited != len(w.Nodes) {
+		return errors.ErrorWorkflowCyclicDependencies
+	}
+
+	return nil
+}
+
+func (w *Workflow) GetNode(key string) (n *WorkflowNode) {
+	for i := range w.Nodes {
+		if w.Nodes[i].Key == key {
+			return &w.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// GetUpstreamEdges get edges pointing to the node of the given key
+func (w *Workflow) GetUpstreamEdges(key string) (edges []WorkflowEdge) {
+	for _, e := range w.Edges {
+		if e.Target == key {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+type WorkflowNode struct {
+	Key      string               `json:"key" bson:"key"` // unique key of the node within the workflow
+	Name     string               `json:"name" bson:"name"`
+	Type     string               `json:"type" bson:"type"`           // constants.TaskTypeSpider (default) or constants.TaskTypeSystem
+	SpiderId primitive.ObjectID   `json:"spider_id" bson:"spider_id"` // Spider.Id, whose directory system tasks run in as well
+	Cmd      string               `json:"cmd" bson:"cmd"`             // command of system task
+	Param    string               `json:"param" bson:"param"`
+	Mode     string               `json:"mode" bson:"mode"`
+	NodeIds  []primitive.ObjectID `json:"node_ids" bson:"node_ids"`
+	Priority int                  `json:"priority" bson:"priority"`
+	Envs     []Env                `json:"envs" bson:"envs"`
+	Timeout  int                  `json:"timeout" bson:"timeout"`
+}
+
+type WorkflowEdge struct {
+	Source    string `json:"source" bson:"source"`       // WorkflowNode.Key of upstream node
+	Target    string `json:"target" bson:"target"`       // WorkflowNode.Key of downstream node
+	Condition string `json:"condition" bson:"condition"` // upstream status required to run downstream, constants.WorkflowEdgeConditionSuccess by default
+}
+
+// IsSatisfied whether the condition is satisfied by the given status of upstream node
+func (e *WorkflowEdge) IsSatisfied(status string) (ok bool) {
+	switch e.Condition {
+	case constants.WorkflowEdgeConditionAlways:
+		return IsWorkflowNodeDone(status)
+	case constants.WorkflowEdgeConditionFailure:
+		return status == constants.TaskStatusError ||
+			status == constants.TaskStatusCancelled ||
+			status == constants.TaskStatusTimeout
+	default:
+		return status == constants.TaskStatusFinished
+	}
+}
+
+// IsWorkflowNodeDone whether the status of a workflow node is final
+func IsWorkflowNodeDone(status string) (ok bool) {
+	switch status {
+	case constants.TaskStatusFinished,
+		constants.TaskStatusError,
+		constants.TaskStatusCancelled,
+		constants.TaskStatusTimeout,
+		constants.WorkflowNodeStatusSkipped:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type WorkflowRun struct {
+	Id         primitive.ObjectID `json:"_id" bson:"_id"`
+	WorkflowId primitive.ObjectID `json:"workflow_id" bson:"workflow_id"` // Workflow.Id
+	Status     string             `json:"status" bson:"status"`
+	Nodes      []WorkflowRunNode  `json:"nodes" bson:"nodes"`
+	StartTs    time.Time          `json:"start_ts" bson:"start_ts"`
+	EndTs      time.Time          `json:"end_ts" bson:"end_ts"`
+	UserId     primitive.ObjectID `json:"user_id" bson:"user_id"`
+}
+
+func (r *WorkflowRun) GetId() (id primitive.ObjectID) {
+	return r.Id
+}
+
+func (r *WorkflowRun) SetId(id primitive.ObjectID) {
+	r.Id = id
+}
+
+func (r *WorkflowRun) GetNode(key string) (n *WorkflowRunNode) {
+	for i := range r.Nodes {
+		if r.Nodes[i].Key == key {
+			return &r.Nodes[i]
+		}
+	}
+	return nil
+}
+
+type WorkflowRunNode struct {
+	Key     string               `json:"key" bson:"key"` // WorkflowNode.Key
+	Status  string               `json:"status" bson:"status"`
+	TaskIds []primitive.ObjectID `json:"task_ids" bson:"task_ids"` // list of Task.Id
+}
@@ -0,0 +1,60 @@
+package models_test
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestWorkflow_Validate(t *testing.T) {
+	spiderId := primitive.NewObjectID()
+	w := &models2.Workflow{
+		Nodes: []models2.WorkflowNode{
+			{Key: "list", SpiderId: spiderId},
+			{Key: "detail", SpiderId: spiderId},
+			{Key: "cleanup", Type: constants.TaskTypeSystem, SpiderId: spiderId, Cmd: "sh cleanup.sh"},
+		},
+		Edges: []models2.WorkflowEdge{
+			{Source: "list", Target: "detail"},
+			{Source: "detail", Target: "cleanup", Condition: constants.WorkflowEdgeConditionAlways},
+		},
+	}
+	require.Nil(t, w.Validate())
+
+	// cycle
+	w.Edges = append(w.Edges, models2.WorkflowEdge{Source: "cleanup", Target: "list"})
+	require.Equal(t, errors.ErrorWorkflowCyclicDependencies, w.Validate())
+
+	// unknown node
+	w.Edges = []models2.WorkflowEdge{{Source: "list", Target: "unknown"}}
+	require.Equal(t, errors.ErrorWorkflowInvalidEdge, w.Validate())
+
+	// invalid condition
+	w.Edges = []models2.WorkflowEdge{{Source: "list", Target: "detail", Condition: "unknown"}}
+	require.Equal(t, errors.ErrorWorkflowInvalidCondition, w.Validate())
+
+	// duplicate key
+	w.Edges = nil
+	w.Nodes = append(w.Nodes, models2.WorkflowNode{Key: "list", SpiderId: spiderId})
+	require.Equal(t, errors.ErrorWorkflowDuplicateNodeKey, w.Validate())
+}
+
+func TestWorkflowEdge_IsSatisfied(t *testing.T) {
+	e := &models2.WorkflowEdge{}
+	require.True(t, e.IsSatisfied(constants.TaskStatusFinished))
+	require.False(t, e.IsSatisfied(constants.TaskStatusError))
+	require.False(t, e.IsSatisfied(constants.WorkflowNodeStatusSkipped))
+
+	e.Condition = constants.WorkflowEdgeConditionFailure
+	require.False(t, e.IsSatisfied(constants.TaskStatusFinished))
+	require.True(t, e.IsSatisfied(constants.TaskStatusError))
+	require.True(t, e.IsSatisfied(constants.TaskStatusTimeout))
+
+	e.Condition = constants.WorkflowEdgeConditionAlways
+	require.True(t, e.IsSatisfied(constants.TaskStatusCancelled))
+	require.True(t, e.IsSatisfied(constants.WorkflowNodeStatusSkipped))
+	require.False(t, e.IsSatisfied(constants.TaskStatusRunning))
+}
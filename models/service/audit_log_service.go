@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func convertTypeAuditLog(d interface{}, err error) (res *models2.AuditLog, err2 error) {
+	if err != nil {
+		return nil, err
+	}
+	res, ok := d.(*models2.AuditLog)
+	if !ok {
+		return nil, errors.ErrorModelInvalidType
+	}
+	return res, nil
+}
+
+func (svc *Service) GetAuditLogById(id primitive.ObjectID) (res *models2.AuditLog, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdAuditLog).GetById(id)
+	return convertTypeAuditLog(d, err)
+}
+
+func (svc *Service) GetAuditLog(query bson.M, opts *mongo.FindOptions) (res *models2.AuditLog, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdAuditLog).Get(query, opts)
+	return convertTypeAuditLog(d, err)
+}
+
+func (svc *Service) GetAuditLogList(query bson.M, opts *mongo.FindOptions) (res []models2.AuditLog, err error) {
+	err = svc.getListSerializeTarget(interfaces.ModelIdAuditLog, query, opts, &res)
+	return res, err
+}
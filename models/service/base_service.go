@@ -230,12 +230,21 @@ func (svc *BaseService) insert(u interfaces.User, docs ...interface{}) (err erro
 		return err
 	}
 
-	// upsert artifacts
+	// owner id, which is empty if inserted by the system
 	query := bson.M{
 		"_id": bson.M{
 			"$in": ids,
 		},
 	}
+	uid := primitive.NilObjectID
+	if u != nil && !reflect.ValueOf(u).IsZero() {
+		uid = u.GetId()
+	}
+	if err := svc.col.Update(query, bson.M{"$set": bson.M{constants.OwnerIdKey: uid}}); err != nil {
+		return trace.TraceError(err)
+	}
+
+	// upsert artifacts
 	fr := svc.col.Find(query, nil)
 	list := NewListBinder(svc.id, fr).MustBindListWithNoFields()
 	for _, item := range list.Values() {
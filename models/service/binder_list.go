@@ -78,6 +78,24 @@ func (b *ListBinder) Bind() (list arraylist.List, err error) {
 		return b.Process(m.PluginStatus)
 	case interfaces.ModelIdGit:
 		return b.Process(m.Gits)
+	case interfaces.ModelIdWorkflow:
+		return b.Process(m.Workflows)
+	case interfaces.ModelIdWorkflowRun:
+		return b.Process(m.WorkflowRuns)
+	case interfaces.ModelIdNotificationSetting:
+		return b.Process(m.NotificationSettings)
+	case interfaces.ModelIdNotificationDelivery:
+		return b.Process(m.NotificationDeliveries)
+	case interfaces.ModelIdAuditLog:
+		return b.Process(m.AuditLogs)
+	case interfaces.ModelIdResultExport:
+		return b.Process(m.ResultExports)
+	case interfaces.ModelIdDataField:
+		return b.Process(m.DataFields)
+	case interfaces.ModelIdGitSyncLog:
+		return b.Process(m.GitSyncLogs)
+	case interfaces.ModelIdGitSshKey:
+		return b.Process(m.GitSshKeys)
 	default:
 		return list, errors.ErrorModelInvalidModelId
 	}
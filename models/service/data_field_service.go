@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func convertTypeDataField(d interface{}, err error) (res *models2.DataField, err2 error) {
+	if err != nil {
+		return nil, err
+	}
+	res, ok := d.(*models2.DataField)
+	if !ok {
+		return nil, errors.ErrorModelInvalidType
+	}
+	return res, nil
+}
+
+func (svc *Service) GetDataFieldById(id primitive.ObjectID) (res *models2.DataField, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdDataField).GetById(id)
+	return convertTypeDataField(d, err)
+}
+
+func (svc *Service) GetDataField(query bson.M, opts *mongo.FindOptions) (res *models2.DataField, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdDataField).Get(query, opts)
+	return convertTypeDataField(d, err)
+}
+
+func (svc *Service) GetDataFieldList(query bson.M, opts *mongo.FindOptions) (res []models2.DataField, err error) {
+	err = svc.getListSerializeTarget(interfaces.ModelIdDataField, query, opts, &res)
+	return res, err
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func convertTypeGitSshKey(d interface{}, err error) (res *models2.GitSshKey, err2 error) {
+	if err != nil {
+		return nil, err
+	}
+	res, ok := d.(*models2.GitSshKey)
+	if !ok {
+		return nil, errors.ErrorModelInvalidType
+	}
+	return res, nil
+}
+
+func (svc *Service) GetGitSshKeyById(id primitive.ObjectID) (res *models2.GitSshKey, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdGitSshKey).GetById(id)
+	return convertTypeGitSshKey(d, err)
+}
+
+func (svc *Service) GetGitSshKey(query bson.M, opts *mongo.FindOptions) (res *models2.GitSshKey, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdGitSshKey).Get(query, opts)
+	return convertTypeGitSshKey(d, err)
+}
+
+func (svc *Service) GetGitSshKeyList(query bson.M, opts *mongo.FindOptions) (res []models2.GitSshKey, err error) {
+	err = svc.getListSerializeTarget(interfaces.ModelIdGitSshKey, query, opts, &res)
+	return res, err
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func convertTypeGitSyncLog(d interface{}, err error) (res *models2.GitSyncLog, err2 error) {
+	if err != nil {
+		return nil, err
+	}
+	res, ok := d.(*models2.GitSyncLog)
+	if !ok {
+		return nil, errors.ErrorModelInvalidType
+	}
+	return res, nil
+}
+
+func (svc *Service) GetGitSyncLogById(id primitive.ObjectID) (res *models2.GitSyncLog, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdGitSyncLog).GetById(id)
+	return convertTypeGitSyncLog(d, err)
+}
+
+func (svc *Service) GetGitSyncLog(query bson.M, opts *mongo.FindOptions) (res *models2.GitSyncLog, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdGitSyncLog).Get(query, opts)
+	return convertTypeGitSyncLog(d, err)
+}
+
+func (svc *Service) GetGitSyncLogList(query bson.M, opts *mongo.FindOptions) (res []models2.GitSyncLog, err error) {
+	err = svc.getListSerializeTarget(interfaces.ModelIdGitSyncLog, query, opts, &res)
+	return res, err
+}
@@ -87,5 +87,32 @@ type ModelService interface {
 	GetGitById(id primitive.ObjectID) (res *models.Git, err error)
 	GetGit(query bson.M, opts *mongo.FindOptions) (res *models.Git, err error)
 	GetGitList(query bson.M, opts *mongo.FindOptions) (res []models.Git, err error)
+	GetWorkflowById(id primitive.ObjectID) (res *models.Workflow, err error)
+	GetWorkflow(query bson.M, opts *mongo.FindOptions) (res *models.Workflow, err error)
+	GetWorkflowList(query bson.M, opts *mongo.FindOptions) (res []models.Workflow, err error)
+	GetWorkflowRunById(id primitive.ObjectID) (res *models.WorkflowRun, err error)
+	GetWorkflowRun(query bson.M, opts *mongo.FindOptions) (res *models.WorkflowRun, err error)
+	GetWorkflowRunList(query bson.M, opts *mongo.FindOptions) (res []models.WorkflowRun, err error)
+	GetNotificationSettingById(id primitive.ObjectID) (res *models.NotificationSetting, err error)
+	GetNotificationSetting(query bson.M, opts *mongo.FindOptions) (res *models.NotificationSetting, err error)
+	GetNotificationSettingList(query bson.M, opts *mongo.FindOptions) (res []models.NotificationSetting, err error)
+	GetNotificationDeliveryById(id primitive.ObjectID) (res *models.NotificationDelivery, err error)
+	GetNotificationDelivery(query bson.M, opts *mongo.FindOptions) (res *models.NotificationDelivery, err error)
+	GetNotificationDeliveryList(query bson.M, opts *mongo.FindOptions) (res []models.NotificationDelivery, err error)
+	GetAuditLogById(id primitive.ObjectID) (res *models.AuditLog, err error)
+	GetAuditLog(query bson.M, opts *mongo.FindOptions) (res *models.AuditLog, err error)
+	GetAuditLogList(query bson.M, opts *mongo.FindOptions) (res []models.AuditLog, err error)
+	GetResultExportById(id primitive.ObjectID) (res *models.ResultExport, err error)
+	GetResultExport(query bson.M, opts *mongo.FindOptions) (res *models.ResultExport, err error)
+	GetResultExportList(query bson.M, opts *mongo.FindOptions) (res []models.ResultExport, err error)
+	GetDataFieldById(id primitive.ObjectID) (res *models.DataField, err error)
+	GetDataField(query bson.M, opts *mongo.FindOptions) (res *models.DataField, err error)
+	GetDataFieldList(query bson.M, opts *mongo.FindOptions) (res []models.DataField, err error)
+	GetGitSyncLogById(id primitive.ObjectID) (res *models.GitSyncLog, err error)
+	GetGitSyncLog(query bson.M, opts *mongo.FindOptions) (res *models.GitSyncLog, err error)
+	GetGitSyncLogList(query bson.M, opts *mongo.FindOptions) (res []models.GitSyncLog, err error)
+	GetGitSshKeyById(id primitive.ObjectID) (res *models.GitSshKey, err error)
+	GetGitSshKey(query bson.M, opts *mongo.FindOptions) (res *models.GitSshKey, err error)
+	GetGitSshKeyList(query bson.M, opts *mongo.FindOptions) (res []models.GitSshKey, err error)
 	DropAll() (err error)
 }
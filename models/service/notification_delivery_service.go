@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func convertTypeNotificationDelivery(d interface{}, err error) (res *models2.NotificationDelivery, err2 error) {
+	if err != nil {
+		return nil, err
+	}
+	res, ok := d.(*models2.NotificationDelivery)
+	if !ok {
+		return nil, errors.ErrorModelInvalidType
+	}
+	return res, nil
+}
+
+func (svc *Service) GetNotificationDeliveryById(id primitive.ObjectID) (res *models2.NotificationDelivery, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdNotificationDelivery).GetById(id)
+	return convertTypeNotificationDelivery(d, err)
+}
+
+func (svc *Service) GetNotificationDelivery(query bson.M, opts *mongo.FindOptions) (res *models2.NotificationDelivery, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdNotificationDelivery).Get(query, opts)
+	return convertTypeNotificationDelivery(d, err)
+}
+
+func (svc *Service) GetNotificationDeliveryList(query bson.M, opts *mongo.FindOptions) (res []models2.NotificationDelivery, err error) {
+	err = svc.getListSerializeTarget(interfaces.ModelIdNotificationDelivery, query, opts, &res)
+	return res, err
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func convertTypeNotificationSetting(d interface{}, err error) (res *models2.NotificationSetting, err2 error) {
+	if err != nil {
+		return nil, err
+	}
+	res, ok := d.(*models2.NotificationSetting)
+	if !ok {
+		return nil, errors.ErrorModelInvalidType
+	}
+	return res, nil
+}
+
+func (svc *Service) GetNotificationSettingById(id primitive.ObjectID) (res *models2.NotificationSetting, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdNotificationSetting).GetById(id)
+	return convertTypeNotificationSetting(d, err)
+}
+
+func (svc *Service) GetNotificationSetting(query bson.M, opts *mongo.FindOptions) (res *models2.NotificationSetting, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdNotificationSetting).Get(query, opts)
+	return convertTypeNotificationSetting(d, err)
+}
+
+func (svc *Service) GetNotificationSettingList(query bson.M, opts *mongo.FindOptions) (res []models2.NotificationSetting, err error) {
+	err = svc.getListSerializeTarget(interfaces.ModelIdNotificationSetting, query, opts, &res)
+	return res, err
+}
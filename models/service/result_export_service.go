@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func convertTypeResultExport(d interface{}, err error) (res *models2.ResultExport, err2 error) {
+	if err != nil {
+		return nil, err
+	}
+	res, ok := d.(*models2.ResultExport)
+	if !ok {
+		return nil, errors.ErrorModelInvalidType
+	}
+	return res, nil
+}
+
+func (svc *Service) GetResultExportById(id primitive.ObjectID) (res *models2.ResultExport, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdResultExport).GetById(id)
+	return convertTypeResultExport(d, err)
+}
+
+func (svc *Service) GetResultExport(query bson.M, opts *mongo.FindOptions) (res *models2.ResultExport, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdResultExport).Get(query, opts)
+	return convertTypeResultExport(d, err)
+}
+
+func (svc *Service) GetResultExportList(query bson.M, opts *mongo.FindOptions) (res []models2.ResultExport, err error) {
+	err = svc.getListSerializeTarget(interfaces.ModelIdResultExport, query, opts, &res)
+	return res, err
+}
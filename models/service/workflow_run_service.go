@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func convertTypeWorkflowRun(d interface{}, err error) (res *models2.WorkflowRun, err2 error) {
+	if err != nil {
+		return nil, err
+	}
+	res, ok := d.(*models2.WorkflowRun)
+	if !ok {
+		return nil, errors.ErrorModelInvalidType
+	}
+	return res, nil
+}
+
+func (svc *Service) GetWorkflowRunById(id primitive.ObjectID) (res *models2.WorkflowRun, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdWorkflowRun).GetById(id)
+	return convertTypeWorkflowRun(d, err)
+}
+
+func (svc *Service) GetWorkflowRun(query bson.M, opts *mongo.FindOptions) (res *models2.WorkflowRun, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdWorkflowRun).Get(query, opts)
+	return convertTypeWorkflowRun(d, err)
+}
+
+func (svc *Service) GetWorkflowRunList(query bson.M, opts *mongo.FindOptions) (res []models2.WorkflowRun, err error) {
+	err = svc.getListSerializeTarget(interfaces.ModelIdWorkflowRun, query, opts, &res)
+	return res, err
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	models2 "github.com/luke513009828/crawlab-core/models/models"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func convertTypeWorkflow(d interface{}, err error) (res *models2.Workflow, err2 error) {
+	if err != nil {
+		return nil, err
+	}
+	res, ok := d.(*models2.Workflow)
+	if !ok {
+		return nil, errors.ErrorModelInvalidType
+	}
+	return res, nil
+}
+
+func (svc *Service) GetWorkflowById(id primitive.ObjectID) (res *models2.Workflow, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdWorkflow).GetById(id)
+	return convertTypeWorkflow(d, err)
+}
+
+func (svc *Service) GetWorkflow(query bson.M, opts *mongo.FindOptions) (res *models2.Workflow, err error) {
+	d, err := svc.GetBaseService(interfaces.ModelIdWorkflow).Get(query, opts)
+	return convertTypeWorkflow(d, err)
+}
+
+func (svc *Service) GetWorkflowList(query bson.M, opts *mongo.FindOptions) (res []models2.Workflow, err error) {
+	err = svc.getListSerializeTarget(interfaces.ModelIdWorkflow, query, opts, &res)
+	return res, err
+}
@@ -12,11 +12,16 @@ import (
 	"github.com/luke513009828/crawlab-core/models/models"
 	"github.com/luke513009828/crawlab-core/models/service"
 	"github.com/luke513009828/crawlab-core/node/config"
+	"github.com/luke513009828/crawlab-core/notification"
 	"github.com/luke513009828/crawlab-core/plugin"
+	"github.com/luke513009828/crawlab-core/result"
 	"github.com/luke513009828/crawlab-core/schedule"
+	"github.com/luke513009828/crawlab-core/spider/gitsync"
 	"github.com/luke513009828/crawlab-core/task/handler"
 	"github.com/luke513009828/crawlab-core/task/scheduler"
+	"github.com/luke513009828/crawlab-core/task/stats"
 	"github.com/luke513009828/crawlab-core/utils"
+	"github.com/luke513009828/crawlab-core/workflow"
 	grpc "github.com/crawlab-team/crawlab-grpc"
 	"github.com/crawlab-team/go-trace"
 	"github.com/spf13/viper"
@@ -35,6 +40,10 @@ type MasterService struct {
 	handlerSvc   interfaces.TaskHandlerService
 	scheduleSvc  interfaces.ScheduleService
 	pluginSvc    interfaces.PluginService
+	workflowSvc  interfaces.WorkflowService
+	notifySvc    interfaces.NotificationService
+	gitSyncSvc   interfaces.SpiderGitSyncService
+	statsSvc     interfaces.TaskStatsService
 
 	// settings
 	cfgPath         string
@@ -62,6 +71,19 @@ func (svc *MasterService) Start() {
 		panic(err)
 	}
 
+	// set owner ids of documents created by earlier versions
+	if err := common.MigrateOwnerIds(); err != nil {
+		trace.PrintError(err)
+	}
+
+	// fail result exports interrupted by restarts
+	if err := result.FailInterruptedExports(); err != nil {
+		trace.PrintError(err)
+	}
+
+	// start cleaning up expired result exports
+	go result.CleanupExports()
+
 	// start monitoring worker nodes
 	go svc.Monitor()
 
@@ -77,6 +99,18 @@ func (svc *MasterService) Start() {
 	// start plugin service
 	go svc.pluginSvc.Start()
 
+	// start workflow service
+	go svc.workflowSvc.Start()
+
+	// start notification service
+	go svc.notifySvc.Start()
+
+	// start git sync service
+	go svc.gitSyncSvc.Start()
+
+	// start task stats service
+	go svc.statsSvc.Start()
+
 	// wait for quit signal
 	svc.Wait()
 
@@ -295,6 +329,18 @@ func NewMasterService(opts ...Option) (res interfaces.NodeMasterService, err err
 	if err := c.Provide(plugin.ProvideGetPluginService(svc.cfgPath)); err != nil {
 		return nil, err
 	}
+	if err := c.Provide(workflow.ProvideGetWorkflowService(svc.cfgPath)); err != nil {
+		return nil, err
+	}
+	if err := c.Provide(notification.ProvideGetNotificationService(svc.cfgPath)); err != nil {
+		return nil, err
+	}
+	if err := c.Provide(gitsync.ProvideGetSpiderGitSyncService(svc.cfgPath)); err != nil {
+		return nil, err
+	}
+	if err := c.Provide(stats.ProvideGetTaskStatsService(svc.cfgPath)); err != nil {
+		return nil, err
+	}
 	if err := c.Invoke(func(
 		cfgSvc interfaces.NodeConfigService,
 		modelSvc service.ModelService,
@@ -303,6 +349,10 @@ func NewMasterService(opts ...Option) (res interfaces.NodeMasterService, err err
 		handlerSvc interfaces.TaskHandlerService,
 		scheduleSvc interfaces.ScheduleService,
 		pluginSvc interfaces.PluginService,
+		workflowSvc interfaces.WorkflowService,
+		notifySvc interfaces.NotificationService,
+		gitSyncSvc interfaces.SpiderGitSyncService,
+		statsSvc interfaces.TaskStatsService,
 	) {
 		svc.cfgSvc = cfgSvc
 		svc.modelSvc = modelSvc
@@ -311,6 +361,10 @@ func NewMasterService(opts ...Option) (res interfaces.NodeMasterService, err err
 		svc.handlerSvc = handlerSvc
 		svc.scheduleSvc = scheduleSvc
 		svc.pluginSvc = pluginSvc
+		svc.workflowSvc = workflowSvc
+		svc.notifySvc = notifySvc
+		svc.gitSyncSvc = gitSyncSvc
+		svc.statsSvc = statsSvc
 	}); err != nil {
 		return nil, err
 	}
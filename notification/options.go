@@ -0,0 +1,13 @@
+package notification
+
+import (
+	"github.com/luke513009828/crawlab-core/interfaces"
+)
+
+type Option func(svc interfaces.NotificationService)
+
+func WithConfigPath(path string) Option {
+	return func(svc interfaces.NotificationService) {
+		svc.SetConfigPath(path)
+	}
+}
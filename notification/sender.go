@@ -0,0 +1,153 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/spf13/viper"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// sendWebhook post a JSON payload to a generic web hook, of which task and
+// spider are limited to non-sensitive fields, e.g. without envs or git
+// credentials, as web hooks are external
+func sendWebhook(url string, title, content string, data *TemplateData) (err error) {
+	payload := map[string]interface{}{
+		"title":   title,
+		"content": content,
+		"status":  data.Task.Status,
+		"task": map[string]interface{}{
+			"_id":         data.Task.Id,
+			"spider_id":   data.Task.SpiderId,
+			"node_id":     data.Task.NodeId,
+			"schedule_id": data.Task.ScheduleId,
+			"status":      data.Task.Status,
+			"error":       data.Task.Error,
+			"type":        data.Task.Type,
+			"mode":        data.Task.Mode,
+			"priority":    data.Task.Priority,
+			"attempt":     data.Task.Attempt,
+			"version":     data.Task.Version,
+		},
+		"spider": map[string]interface{}{
+			"_id":         data.Spider.Id,
+			"name":        data.Spider.Name,
+			"type":        data.Spider.Type,
+			"description": data.Spider.Description,
+			"project_id":  data.Spider.ProjectId,
+		},
+	}
+	if data.User != nil {
+		payload["user_name"] = data.User.Username
+	}
+	return postJSON(url, payload, nil)
+}
+
+// sendDingTalk send a markdown message to a DingTalk robot
+func sendDingTalk(url string, title, content string) (err error) {
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"title": title,
+			"text":  content,
+		},
+	}
+	return postJSON(url, payload, &chatBotResponse{})
+}
+
+// sendWechat send a markdown message to a WeChat Work robot
+func sendWechat(url string, title, content string) (err error) {
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"content": fmt.Sprintf("### %s\n%s", title, content),
+		},
+	}
+	return postJSON(url, payload, &chatBotResponse{})
+}
+
+// chatBotResponse response of DingTalk and WeChat Work robots, which return
+// http 200 with a non-zero error code on failure
+type chatBotResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func postJSON(url string, payload interface{}, res *chatBotResponse) (err error) {
+	if url == "" {
+		return errors.ErrorNotificationEmptyWebhook
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Post(url, "application/json; charset=utf-8", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.NewNotificationError(fmt.Sprintf("http status %d", resp.StatusCode))
+	}
+	if res == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return err
+	}
+	if res.ErrCode != 0 {
+		return errors.NewNotificationError(res.ErrMsg)
+	}
+	return nil
+}
+
+// sendMail send a plain text mail through the SMTP server configured by
+// notification.mail.* settings
+func sendMail(to []string, title, content string) (err error) {
+	if len(to) == 0 {
+		return errors.ErrorNotificationEmptyMail
+	}
+
+	// smtp settings
+	server := viper.GetString("notification.mail.server")
+	port := viper.GetString("notification.mail.port")
+	username := viper.GetString("notification.mail.user")
+	password := viper.GetString("notification.mail.password")
+	senderEmail := viper.GetString("notification.mail.senderEmail")
+	senderIdentity := viper.GetString("notification.mail.senderIdentity")
+	if server == "" || senderEmail == "" {
+		return errors.ErrorNotificationMailNotEnabled
+	}
+	if port == "" {
+		port = "25"
+	}
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, server)
+	}
+
+	// message
+	from := senderEmail
+	if senderIdentity != "" {
+		from = fmt.Sprintf("%s <%s>", mime.QEncoding.Encode("utf-8", senderIdentity), senderEmail)
+	}
+	msg := strings.Join([]string{
+		"From: " + from,
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", title),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		content,
+	}, "\r\n")
+
+	return smtp.SendMail(net.JoinHostPort(server, port), auth, senderEmail, to, []byte(msg))
+}
@@ -0,0 +1,321 @@
+package notification
+
+import (
+	"github.com/apex/log"
+	"github.com/luke513009828/crawlab-core/config"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/event"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/luke513009828/crawlab-core/utils"
+	"github.com/crawlab-team/go-trace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/dig"
+	"sync"
+	"time"
+)
+
+const notificationEventKey = "notification:service"
+
+type Service struct {
+	// dependencies
+	interfaces.WithConfigPath
+	modelSvc service.ModelService
+
+	// internals
+	stopped bool
+	mu      sync.Mutex // serialize notifications of the same task
+}
+
+func (svc *Service) Init() (err error) {
+	return nil
+}
+
+func (svc *Service) Start() {
+	go svc.handleTaskEvents()
+}
+
+func (svc *Service) Wait() {
+	utils.DefaultWait()
+	svc.Stop()
+}
+
+func (svc *Service) Stop() {
+	svc.stopped = true
+}
+
+func (svc *Service) Notify(taskId primitive.ObjectID) (err error) {
+	// task
+	t, err := svc.modelSvc.GetTaskById(taskId)
+	if err != nil {
+		return err
+	}
+	if !utils.IsTaskEnded(t.Status) {
+		return nil
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	// template data
+	data, err := svc.getTemplateData(t)
+	if err != nil {
+		return err
+	}
+
+	// notification settings
+	settings, err := svc.modelSvc.GetNotificationSettingList(bson.M{"enabled": true}, nil)
+	if err != nil {
+		return err
+	}
+	for _, s := range settings {
+		if !s.IsMatched(t.SpiderId, data.getUserId()) || !s.IsTriggered(t.Status) {
+			continue
+		}
+		if err := svc.send(&s, data); err != nil {
+			trace.PrintError(err)
+		}
+	}
+
+	// web hook of the spider
+	if data.Spider.IsWebHook && data.Spider.WebHookUrl != "" {
+		s := &models.NotificationSetting{
+			Type:    constants.NotificationTypeWebhook,
+			Webhook: data.Spider.WebHookUrl,
+		}
+		if err := svc.send(s, data); err != nil {
+			trace.PrintError(err)
+		}
+	}
+
+	return nil
+}
+
+// handleTaskEvents subscribe to task change events and send notifications of
+// ended tasks
+func (svc *Service) handleTaskEvents() {
+	ch := make(chan interfaces.EventData)
+	eventSvc := event.NewEventService()
+	eventSvc.Register(notificationEventKey, "^model:"+interfaces.ModelColNameTask+":change$", "^$", &ch)
+	defer eventSvc.Unregister(notificationEventKey)
+
+	for {
+		if svc.stopped {
+			return
+		}
+
+		ed := <-ch
+		doc, ok := ed.GetData().(*models.Task)
+		if !ok || !utils.IsTaskEnded(doc.Status) {
+			continue
+		}
+		go func(id primitive.ObjectID) {
+			if err := svc.Notify(id); err != nil {
+				trace.PrintError(err)
+			}
+		}(doc.GetId())
+	}
+}
+
+// send render and send the notification of the setting, and record the
+// delivery unless it has been delivered for the task
+func (svc *Service) send(s *models.NotificationSetting, data *TemplateData) (err error) {
+	// skip if delivered
+	query := bson.M{
+		"setting_id": s.Id,
+		"task_id":    data.Task.Id,
+	}
+	if s.Id.IsZero() {
+		query["type"] = s.Type
+	}
+	count, err := svc.modelSvc.GetBaseService(interfaces.ModelIdNotificationDelivery).Count(query)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	// delivery
+	d := &models.NotificationDelivery{
+		SettingId:  s.Id,
+		TaskId:     data.Task.Id,
+		SpiderId:   data.Task.SpiderId,
+		TaskStatus: data.Task.Status,
+		Type:       s.Type,
+		Status:     constants.NotificationDeliveryStatusSuccess,
+		Ts:         time.Now(),
+	}
+
+	// render and send
+	if err := svc._send(s, data, d); err != nil {
+		d.Status = constants.NotificationDeliveryStatusError
+		d.Error = err.Error()
+		log.Warnf("notification of task[%s] to %s failed: %v", data.Task.Id.Hex(), s.Type, err)
+	}
+
+	// owned by the owner of the setting
+	var args []interface{}
+	if u := svc.getOwner(s, data); u != nil {
+		args = append(args, u)
+	}
+	return delegate.NewModelDelegate(d, args...).Add()
+}
+
+func (svc *Service) _send(s *models.NotificationSetting, data *TemplateData, d *models.NotificationDelivery) (err error) {
+	d.Title, err = render(s.Title, defaultTitleTemplate, data)
+	if err != nil {
+		return err
+	}
+	d.Content, err = render(s.Template, defaultContentTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	switch s.Type {
+	case constants.NotificationTypeWebhook:
+		return sendWebhook(s.Webhook, d.Title, d.Content, data)
+	case constants.NotificationTypeDingTalk:
+		return sendDingTalk(s.Webhook, d.Title, d.Content)
+	case constants.NotificationTypeWechat:
+		return sendWechat(s.Webhook, d.Title, d.Content)
+	case constants.NotificationTypeMail:
+		to := s.Mail
+		if len(to) == 0 && data.User != nil && data.User.Email != "" {
+			to = []string{data.User.Email}
+		}
+		return sendMail(to, d.Title, d.Content)
+	default:
+		return errors.ErrorNotificationInvalidType
+	}
+}
+
+// getTemplateData get the spider, node, stat and user of the task, among which
+// node, stat and user are optional
+func (svc *Service) getTemplateData(t *models.Task) (data *TemplateData, err error) {
+	// envs may carry secrets, which are hidden from templates
+	task := *t
+	task.Envs = nil
+	data = &TemplateData{Task: &task}
+
+	// spider
+	data.Spider, err = svc.modelSvc.GetSpiderById(t.SpiderId)
+	if err != nil {
+		return nil, err
+	}
+	data.Spider.Envs = nil
+	data.Spider.GitUsername = ""
+	data.Spider.GitPassword = ""
+
+	// node
+	if n, err := svc.modelSvc.GetNodeById(t.NodeId); err == nil {
+		data.Node = n
+	}
+
+	// stat
+	if s, err := svc.modelSvc.GetTaskStatById(t.Id); err == nil {
+		data.Stat = s
+	}
+
+	// user who ran the task
+	if a, err := svc.modelSvc.GetArtifactById(t.Id); err == nil && a.Sys != nil && !a.Sys.CreateUid.IsZero() {
+		if u, err := svc.modelSvc.GetUserById(a.Sys.CreateUid); err == nil {
+			data.User = u
+		}
+	}
+
+	return data, nil
+}
+
+// getOwner owner of deliveries of the setting, which is the user who created
+// the setting, or the user who ran the task for web hooks of the spider
+func (svc *Service) getOwner(s *models.NotificationSetting, data *TemplateData) (u *models.User) {
+	if s.Id.IsZero() {
+		return data.User
+	}
+	a, err := svc.modelSvc.GetArtifactById(s.Id)
+	if err != nil || a.Sys == nil || a.Sys.CreateUid.IsZero() {
+		return nil
+	}
+	u, err = svc.modelSvc.GetUserById(a.Sys.CreateUid)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+func (data *TemplateData) getUserId() (id primitive.ObjectID) {
+	if data.User == nil {
+		return primitive.NilObjectID
+	}
+	return data.User.Id
+}
+
+func NewNotificationService(opts ...Option) (svc2 interfaces.NotificationService, err error) {
+	// service
+	svc := &Service{
+		WithConfigPath: config.NewConfigPathService(),
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	// dependency injection
+	c := dig.New()
+	if err := c.Provide(service.GetService); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Invoke(func(modelSvc service.ModelService) {
+		svc.modelSvc = modelSvc
+	}); err != nil {
+		return nil, trace.TraceError(err)
+	}
+
+	// initialize
+	if err := svc.Init(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+func ProvideNotificationService(path string, opts ...Option) func() (svc interfaces.NotificationService, err error) {
+	opts = append(opts, WithConfigPath(path))
+	return func() (svc interfaces.NotificationService, err error) {
+		return NewNotificationService(opts...)
+	}
+}
+
+var store = sync.Map{}
+
+func GetNotificationService(path string, opts ...Option) (svc interfaces.NotificationService, err error) {
+	if path == "" {
+		path = config.DefaultConfigPath
+	}
+	opts = append(opts, WithConfigPath(path))
+	res, ok := store.Load(path)
+	if ok {
+		svc, ok = res.(interfaces.NotificationService)
+		if ok {
+			return svc, nil
+		}
+	}
+	svc, err = NewNotificationService(opts...)
+	if err != nil {
+		return nil, err
+	}
+	store.Store(path, svc)
+	return svc, nil
+}
+
+func ProvideGetNotificationService(path string, opts ...Option) func() (svc interfaces.NotificationService, err error) {
+	return func() (svc interfaces.NotificationService, err error) {
+		return GetNotificationService(path, opts...)
+	}
+}
@@ -0,0 +1,51 @@
+package notification
+
+import (
+	"bytes"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"text/template"
+)
+
+const defaultTitleTemplate = `[Crawlab] Task {{.Task.Status}}: {{.Spider.Name}}`
+
+const defaultContentTemplate = `Task **{{.Task.Id.Hex}}** of spider **{{.Spider.Name}}** {{.Task.Status}}.
+
+- Status: {{.Task.Status}}
+{{- if .Node}}
+- Node: {{.Node.Name}}
+{{- end}}
+- Command: {{.Task.Cmd}} {{.Task.Param}}
+{{- if .Stat}}
+- Results: {{.Stat.ResultCount}}
+- Runtime: {{.Stat.RuntimeDuration}} ms
+{{- end}}
+{{- if .Task.Error}}
+- Error: {{.Task.Error}}
+{{- end}}
+`
+
+// TemplateData data available to title and content templates of notifications
+type TemplateData struct {
+	Task   *models.Task
+	Spider *models.Spider
+	Node   *models.Node
+	Stat   *models.TaskStat
+	User   *models.User
+}
+
+// render render the text template with the given data, or the default
+// template if it's empty
+func render(tmpl, defaultTmpl string, data *TemplateData) (res string, err error) {
+	if tmpl == "" {
+		tmpl = defaultTmpl
+	}
+	t, err := template.New("notification").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	buf := bytes.Buffer{}
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
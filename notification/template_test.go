@@ -0,0 +1,45 @@
+package notification
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	data := &TemplateData{
+		Task: &models.Task{
+			Id:     primitive.NewObjectID(),
+			Status: constants.TaskStatusError,
+			Cmd:    "python main.py",
+			Error:  "exit status 1",
+		},
+		Spider: &models.Spider{Name: "test_spider"},
+		Stat:   &models.TaskStat{ResultCount: 10},
+	}
+
+	// default title
+	title, err := render("", defaultTitleTemplate, data)
+	require.Nil(t, err)
+	require.Equal(t, "[Crawlab] Task error: test_spider", title)
+
+	// default content
+	content, err := render("", defaultContentTemplate, data)
+	require.Nil(t, err)
+	require.True(t, strings.Contains(content, data.Task.Id.Hex()))
+	require.True(t, strings.Contains(content, "- Results: 10"))
+	require.True(t, strings.Contains(content, "- Error: exit status 1"))
+	require.False(t, strings.Contains(content, "- Node:"))
+
+	// custom template
+	content, err = render("{{.Spider.Name}} {{.Task.Status}}", defaultContentTemplate, data)
+	require.Nil(t, err)
+	require.Equal(t, "test_spider error", content)
+
+	// invalid template
+	_, err = render("{{.Spider.Name", defaultContentTemplate, data)
+	require.NotNil(t, err)
+}
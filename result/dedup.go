@@ -0,0 +1,284 @@
+package result
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/crawlab-team/go-trace"
+	"go.mongodb.org/mongo-driver/bson"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"strings"
+)
+
+const (
+	dedupIndexName       = "_dk_1__v_1"
+	legacyDedupIndexName = "_dk_1"
+)
+
+func (svc *Service) InsertWithDedup(opts *interfaces.ResultDedupOptions, docs ...interface{}) (stats interfaces.ResultInsertStats, err error) {
+	// insert all if not dedup
+	if opts == nil {
+		if err := svc.Insert(docs...); err != nil {
+			return stats, err
+		}
+		stats.Inserted = len(docs)
+		return stats, nil
+	}
+
+	// unique index
+	if err := svc.ensureDedupIndex(); err != nil {
+		return stats, err
+	}
+
+	for _, doc := range docs {
+		// record with dedup key and content hash
+		r, err := toResultBsonM(doc)
+		if err != nil {
+			return stats, trace.TraceError(err)
+		}
+		h, err := getResultContentHash(r)
+		if err != nil {
+			return stats, trace.TraceError(err)
+		}
+		dk, err := getResultDedupKey(r, opts.Fields)
+		if err != nil {
+			return stats, trace.TraceError(err)
+		}
+		r[constants.ResultFieldDedupKey] = dk
+		r[constants.ResultFieldContentHash] = h
+
+		// insert, which is re-attempted if a record of the same key (and
+		// version) is inserted concurrently by others
+		err = svc.insertWithDedup(opts.Method, r, &stats)
+		if mongo2.IsDuplicateKeyError(err) {
+			err = svc.insertWithDedup(opts.Method, r, &stats)
+		}
+		if err != nil {
+			return stats, trace.TraceError(err)
+		}
+	}
+
+	return stats, nil
+}
+
+func (svc *Service) insertWithDedup(method string, r bson.M, stats *interfaces.ResultInsertStats) (err error) {
+	switch method {
+	case constants.DedupMethodOverwrite:
+		return svc.insertOverwrite(r, stats)
+	case constants.DedupMethodVersion:
+		return svc.insertVersion(r, stats)
+	default:
+		return svc.insertIgnore(r, stats)
+	}
+}
+
+// insertIgnore insert the record if no record of the same dedup key exists
+func (svc *Service) insertIgnore(r bson.M, stats *interfaces.ResultInsertStats) (err error) {
+	col := mongo.GetMongoCol(svc.dc.Name)
+	query := bson.M{constants.ResultFieldDedupKey: r[constants.ResultFieldDedupKey]}
+	total, err := col.Count(query)
+	if err != nil {
+		return trace.TraceError(err)
+	}
+	if total > 0 {
+		stats.Skipped++
+		return nil
+	}
+	r[constants.ResultFieldVersion] = 0
+	if _, err := col.Insert(r); err != nil {
+		return err
+	}
+	stats.Inserted++
+	return nil
+}
+
+// insertOverwrite insert the record, or replace the existing (latest) record
+// of the same dedup key if the content changes
+func (svc *Service) insertOverwrite(r bson.M, stats *interfaces.ResultInsertStats) (err error) {
+	col := mongo.GetMongoCol(svc.dc.Name)
+	var existing bson.M
+	if err := col.Find(bson.M{constants.ResultFieldDedupKey: r[constants.ResultFieldDedupKey]}, &mongo.FindOptions{
+		Sort:  bson.D{{constants.ResultFieldVersion, -1}},
+		Limit: 1,
+	}).One(&existing); err != nil {
+		if err != mongo2.ErrNoDocuments {
+			return trace.TraceError(err)
+		}
+		r[constants.ResultFieldVersion] = 0
+		if _, err := col.Insert(r); err != nil {
+			return err
+		}
+		stats.Inserted++
+		return nil
+	}
+	if existing[constants.ResultFieldContentHash] == r[constants.ResultFieldContentHash] {
+		stats.Skipped++
+		return nil
+	}
+	r["_id"] = existing["_id"]
+	r[constants.ResultFieldVersion] = toInt(existing[constants.ResultFieldVersion])
+	if err := col.Replace(bson.M{"_id": existing["_id"]}, r); err != nil {
+		return err
+	}
+	stats.Updated++
+	return nil
+}
+
+// insertVersion insert the record as the next version of records of the same
+// dedup key if the content differs from the latest version
+func (svc *Service) insertVersion(r bson.M, stats *interfaces.ResultInsertStats) (err error) {
+	col := mongo.GetMongoCol(svc.dc.Name)
+	var latest bson.M
+	if err := col.Find(bson.M{constants.ResultFieldDedupKey: r[constants.ResultFieldDedupKey]}, &mongo.FindOptions{
+		Sort:  bson.D{{constants.ResultFieldVersion, -1}},
+		Limit: 1,
+	}).One(&latest); err != nil {
+		if err != mongo2.ErrNoDocuments {
+			return trace.TraceError(err)
+		}
+		r[constants.ResultFieldVersion] = 1
+		if _, err := col.Insert(r); err != nil {
+			return err
+		}
+		stats.Inserted++
+		return nil
+	}
+	if latest[constants.ResultFieldContentHash] == r[constants.ResultFieldContentHash] {
+		stats.Skipped++
+		return nil
+	}
+	r[constants.ResultFieldVersion] = toInt(latest[constants.ResultFieldVersion]) + 1
+	if _, err := col.Insert(r); err != nil {
+		return err
+	}
+	stats.Updated++
+	return nil
+}
+
+// ensureDedupIndex create the unique index of dedup keys and versions on the
+// data collection, limited to records with dedup keys. Versions are 0 in
+// DedupMethodIgnore and DedupMethodOverwrite so that the same index applies
+// to all dedup methods, which may be switched on the same data collection.
+func (svc *Service) ensureDedupIndex() (err error) {
+	if _, ok := svc.dedupIndexes.Load(dedupIndexName); ok {
+		return nil
+	}
+	col := mongo.GetMongoCol(svc.dc.Name)
+
+	// unique index of dedup keys only, which conflicts with versions
+	indexes, err := col.ListIndexes()
+	if err != nil {
+		return trace.TraceError(err)
+	}
+	for _, idx := range indexes {
+		if idx["name"] == legacyDedupIndexName {
+			if err := col.DeleteIndex(legacyDedupIndexName); err != nil {
+				return trace.TraceError(err)
+			}
+		}
+	}
+
+	if err := col.CreateIndex(mongo2.IndexModel{
+		Keys: bson.D{
+			{constants.ResultFieldDedupKey, 1},
+			{constants.ResultFieldVersion, 1},
+		},
+		Options: options.Index().
+			SetName(dedupIndexName).
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{constants.ResultFieldDedupKey: bson.M{"$exists": true}}),
+	}); err != nil {
+		return trace.TraceError(err)
+	}
+	svc.dedupIndexes.Store(dedupIndexName, true)
+	return nil
+}
+
+// NewResultDedupOptions dedup options from the dedup method and comma-separated
+// dedup fields of the spider, nil if dedup is disabled
+func NewResultDedupOptions(isDedup bool, method string, fields string) (opts *interfaces.ResultDedupOptions) {
+	if !isDedup {
+		return nil
+	}
+	switch method {
+	case constants.DedupMethodOverwrite, constants.DedupMethodVersion:
+	default:
+		method = constants.DedupMethodIgnore
+	}
+	opts = &interfaces.ResultDedupOptions{
+		Method: method,
+	}
+	for _, f := range strings.Split(fields, ",") {
+		f = strings.TrimSpace(f)
+		if f != "" {
+			opts.Fields = append(opts.Fields, f)
+		}
+	}
+	return opts
+}
+
+func toResultBsonM(doc interface{}) (r bson.M, err error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// getResultContentHash hash of the content of the record, excluding internal
+// fields prefixed with "_" such as _id and _tid
+func getResultContentHash(r bson.M) (h string, err error) {
+	content := bson.M{}
+	for k, v := range r {
+		if strings.HasPrefix(k, "_") {
+			continue
+		}
+		content[k] = v
+	}
+	return getHash(content)
+}
+
+// getResultDedupKey hash of values of dedup fields of the record, or content
+// hash if no fields are given
+func getResultDedupKey(r bson.M, fields []string) (dk string, err error) {
+	if len(fields) == 0 {
+		return getResultContentHash(r)
+	}
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		values[i] = r[f]
+	}
+	return getHash(values)
+}
+
+func getHash(v interface{}) (h string, err error) {
+	// keys of maps are sorted in json
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func toInt(v interface{}) (i int) {
+	switch v.(type) {
+	case int32:
+		return int(v.(int32))
+	case int64:
+		return int(v.(int64))
+	case int:
+		return v.(int)
+	case float64:
+		return int(v.(float64))
+	default:
+		return 0
+	}
+}
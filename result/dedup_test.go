@@ -0,0 +1,52 @@
+package result
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestNewResultDedupOptions(t *testing.T) {
+	require.Nil(t, NewResultDedupOptions(false, constants.DedupMethodOverwrite, "url"))
+
+	opts := NewResultDedupOptions(true, "", " url, title ,")
+	require.Equal(t, constants.DedupMethodIgnore, opts.Method)
+	require.Equal(t, []string{"url", "title"}, opts.Fields)
+
+	opts = NewResultDedupOptions(true, constants.DedupMethodVersion, "")
+	require.Equal(t, constants.DedupMethodVersion, opts.Method)
+	require.Empty(t, opts.Fields)
+}
+
+func TestGetResultDedupKey(t *testing.T) {
+	r1 := bson.M{"_id": primitive.NewObjectID(), "_tid": primitive.NewObjectID(), "url": "a", "title": "x"}
+	r2 := bson.M{"_id": primitive.NewObjectID(), "_tid": primitive.NewObjectID(), "title": "x", "url": "a"}
+	r3 := bson.M{"url": "a", "title": "y"}
+
+	// content hash excludes internal fields
+	h1, err := getResultContentHash(r1)
+	require.Nil(t, err)
+	h2, err := getResultContentHash(r2)
+	require.Nil(t, err)
+	h3, err := getResultContentHash(r3)
+	require.Nil(t, err)
+	require.Equal(t, h1, h2)
+	require.NotEqual(t, h1, h3)
+
+	// dedup key of fields
+	dk1, err := getResultDedupKey(r1, []string{"url"})
+	require.Nil(t, err)
+	dk3, err := getResultDedupKey(r3, []string{"url"})
+	require.Nil(t, err)
+	require.Equal(t, dk1, dk3)
+	dk3, err = getResultDedupKey(r3, []string{"url", "title"})
+	require.Nil(t, err)
+	require.NotEqual(t, dk1, dk3)
+
+	// dedup key of content hash
+	dk1, err = getResultDedupKey(r1, nil)
+	require.Nil(t, err)
+	require.Equal(t, h1, dk1)
+}
@@ -0,0 +1,372 @@
+package result
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/crawlab-team/go-trace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// exportBatchSize number of records fetched in each batch of exporting
+const exportBatchSize = 1000
+
+// xlsxMaxRows max number of rows of a sheet in xlsx, including the header
+const xlsxMaxRows = 1048576
+
+// Export write records of the collection matching the query to w in the
+// format (constants.ResultExportFormat*). Nested fields are flattened into
+// dot-separated columns in csv and xlsx, of which the header is collected
+// from all records in advance. It returns the number of exported records.
+func Export(w io.Writer, colName string, query bson.M, format string) (count int, err error) {
+	ew, err := newExportWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	// header
+	if format != constants.ResultExportFormatJsonl {
+		var keys []string
+		keySet := map[string]bool{}
+		rows := 1
+		if err := iterateResults(colName, query, func(r bson.D) error {
+			rows++
+			if format == constants.ResultExportFormatXlsx && rows > xlsxMaxRows {
+				return errors.ErrorResultExportTooManyRows
+			}
+			for _, key := range flattenResult(r).keys {
+				if !keySet[key] {
+					keySet[key] = true
+					keys = append(keys, key)
+				}
+			}
+			return nil
+		}); err != nil {
+			return 0, err
+		}
+		if err := ew.WriteHeader(keys); err != nil {
+			return 0, trace.TraceError(err)
+		}
+	}
+
+	// records
+	if err := iterateResults(colName, query, func(r bson.D) error {
+		count++
+		return ew.WriteRecord(r)
+	}); err != nil {
+		return count, err
+	}
+
+	if err := ew.Close(); err != nil {
+		return count, trace.TraceError(err)
+	}
+
+	return count, nil
+}
+
+// iterateResults iterate records of the collection matching the query in
+// order of _id, which are fetched in batches
+func iterateResults(colName string, query bson.M, fn func(r bson.D) error) (err error) {
+	col := mongo.GetMongoCol(colName)
+	var lastId interface{}
+	for {
+		q := query
+		if lastId != nil {
+			idQuery := bson.M{"_id": bson.M{"$gt": lastId}}
+			if len(query) == 0 {
+				q = idQuery
+			} else {
+				q = bson.M{"$and": []bson.M{query, idQuery}}
+			}
+		}
+		var batch []bson.D
+		if err := col.Find(q, &mongo.FindOptions{
+			Sort:  bson.D{{"_id", 1}},
+			Limit: exportBatchSize,
+		}).All(&batch); err != nil {
+			return trace.TraceError(err)
+		}
+		for _, r := range batch {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		lastId = batch[len(batch)-1].Map()["_id"]
+	}
+}
+
+type exportWriter interface {
+	WriteHeader(keys []string) (err error)
+	WriteRecord(r bson.D) (err error)
+	Close() (err error)
+}
+
+func newExportWriter(w io.Writer, format string) (ew exportWriter, err error) {
+	switch format {
+	case constants.ResultExportFormatCsv:
+		return &csvExportWriter{w: csv.NewWriter(w)}, nil
+	case constants.ResultExportFormatJsonl:
+		bw := bufio.NewWriter(w)
+		enc := json.NewEncoder(bw)
+		enc.SetEscapeHTML(false)
+		return &jsonlExportWriter{w: bw, enc: enc}, nil
+	case constants.ResultExportFormatXlsx:
+		return &xlsxExportWriter{zw: zip.NewWriter(w)}, nil
+	default:
+		return nil, errors.ErrorResultInvalidExportFormat
+	}
+}
+
+type csvExportWriter struct {
+	w    *csv.Writer
+	keys []string
+}
+
+func (ew *csvExportWriter) WriteHeader(keys []string) (err error) {
+	ew.keys = keys
+	return ew.w.Write(keys)
+}
+
+func (ew *csvExportWriter) WriteRecord(r bson.D) (err error) {
+	return ew.w.Write(flattenResult(r).row(ew.keys))
+}
+
+func (ew *csvExportWriter) Close() (err error) {
+	ew.w.Flush()
+	return ew.w.Error()
+}
+
+type jsonlExportWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (ew *jsonlExportWriter) WriteHeader(keys []string) (err error) {
+	return nil
+}
+
+func (ew *jsonlExportWriter) WriteRecord(r bson.D) (err error) {
+	// each record is followed by a newline
+	return ew.enc.Encode(toJsonValue(r))
+}
+
+func (ew *jsonlExportWriter) Close() (err error) {
+	return ew.w.Flush()
+}
+
+// xlsxExportWriter write a minimal xlsx workbook with a single sheet of
+// inline strings, which is streamed into the zip archive
+type xlsxExportWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	keys  []string
+	rows  int
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="results" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+
+const xlsxSheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+const xlsxSheetFooter = `</sheetData></worksheet>`
+
+func (ew *xlsxExportWriter) WriteHeader(keys []string) (err error) {
+	ew.keys = keys
+	for _, f := range []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		fw, err := ew.zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	ew.sheet, err = ew.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(ew.sheet, xlsxSheetHeader); err != nil {
+		return err
+	}
+	return ew.writeRow(keys)
+}
+
+func (ew *xlsxExportWriter) WriteRecord(r bson.D) (err error) {
+	return ew.writeRow(flattenResult(r).row(ew.keys))
+}
+
+func (ew *xlsxExportWriter) Close() (err error) {
+	if _, err := io.WriteString(ew.sheet, xlsxSheetFooter); err != nil {
+		return err
+	}
+	return ew.zw.Close()
+}
+
+func (ew *xlsxExportWriter) writeRow(values []string) (err error) {
+	if ew.rows >= xlsxMaxRows {
+		// results inserted since the header was collected
+		return errors.ErrorResultExportTooManyRows
+	}
+	ew.rows++
+	rowNum := strconv.Itoa(ew.rows)
+	if _, err := io.WriteString(ew.sheet, `<row r="`+rowNum+`">`); err != nil {
+		return err
+	}
+	for i, v := range values {
+		if _, err := io.WriteString(ew.sheet, `<c r="`+getXlsxColumnName(i)+rowNum+`" t="inlineStr"><is><t xml:space="preserve">`); err != nil {
+			return err
+		}
+		if err := xml.EscapeText(ew.sheet, []byte(v)); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(ew.sheet, `</t></is></c>`); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(ew.sheet, `</row>`)
+	return err
+}
+
+// getXlsxColumnName column name of the zero-based index, e.g. A, Z, AA
+func getXlsxColumnName(i int) (name string) {
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+type flatResult struct {
+	keys   []string
+	values map[string]string
+}
+
+func (f flatResult) row(keys []string) (values []string) {
+	values = make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = f.values[key]
+	}
+	return values
+}
+
+// flattenResult flatten nested fields of the record into dot-separated keys
+// in order of fields, where arrays are kept as json
+func flattenResult(r bson.D) (f flatResult) {
+	f.values = map[string]string{}
+	flattenValue("", r, &f)
+	return f
+}
+
+func flattenValue(prefix string, v interface{}, f *flatResult) {
+	switch v.(type) {
+	case bson.D:
+		for _, e := range v.(bson.D) {
+			flattenValue(joinFlatKey(prefix, e.Key), e.Value, f)
+		}
+		return
+	case bson.M:
+		m := v.(bson.M)
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			flattenValue(joinFlatKey(prefix, key), m[key], f)
+		}
+		return
+	}
+	f.keys = append(f.keys, prefix)
+	f.values[prefix] = formatFlatValue(v)
+}
+
+func joinFlatKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func formatFlatValue(v interface{}) (s string) {
+	switch v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v.(string)
+	case primitive.ObjectID:
+		return v.(primitive.ObjectID).Hex()
+	case primitive.DateTime:
+		return v.(primitive.DateTime).Time().UTC().Format(time.RFC3339)
+	case time.Time:
+		return v.(time.Time).UTC().Format(time.RFC3339)
+	case bson.A, []interface{}:
+		data, err := json.Marshal(toJsonValue(v))
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toJsonValue convert documents in v to maps to be marshalled as json objects
+func toJsonValue(v interface{}) (res interface{}) {
+	switch v.(type) {
+	case bson.D:
+		m := map[string]interface{}{}
+		for _, e := range v.(bson.D) {
+			m[e.Key] = toJsonValue(e.Value)
+		}
+		return m
+	case bson.M:
+		m := map[string]interface{}{}
+		for key, value := range v.(bson.M) {
+			m[key] = toJsonValue(value)
+		}
+		return m
+	case bson.A:
+		return toJsonValue([]interface{}(v.(bson.A)))
+	case []interface{}:
+		items := make([]interface{}, len(v.([]interface{})))
+		for i, item := range v.([]interface{}) {
+			items[i] = toJsonValue(item)
+		}
+		return items
+	case primitive.DateTime:
+		return v.(primitive.DateTime).Time().UTC().Format(time.RFC3339)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,145 @@
+package result
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/crawlab-team/go-trace"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// exportCleanupInterval interval of removing expired background result exports
+const exportCleanupInterval = time.Hour
+
+// startTs time when the process started, before which background result
+// exports were created by previous processes
+var startTs = time.Now()
+
+// GetExportDir directory of files of background result exports
+func GetExportDir() (dir string) {
+	dir = viper.GetString("result.export.path")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "crawlab", "exports")
+	}
+	return dir
+}
+
+// GetExportFilePath path of the file of the background result export
+func GetExportFilePath(e *models.ResultExport) (path string) {
+	return filepath.Join(GetExportDir(), e.Id.Hex()+"."+e.Format)
+}
+
+// RunExport export results matching the query to the file of the background
+// result export, of which the status is updated as it runs
+func RunExport(e *models.ResultExport, query bson.M) (err error) {
+	// running
+	e.Status = constants.ResultExportStatusRunning
+	if err := delegate.NewModelDelegate(e).Save(); err != nil {
+		return err
+	}
+
+	// export
+	e.Count, err = runExport(e, query)
+	e.EndTs = time.Now()
+	if err != nil {
+		e.Status = constants.ResultExportStatusError
+		e.Error = err.Error()
+	} else {
+		e.Status = constants.ResultExportStatusFinished
+	}
+	if err := delegate.NewModelDelegate(e).Save(); err != nil {
+		trace.PrintError(err)
+	}
+
+	return err
+}
+
+func runExport(e *models.ResultExport, query bson.M) (count int, err error) {
+	// data collection
+	modelSvc, err := service.GetService()
+	if err != nil {
+		return 0, err
+	}
+	dc, err := modelSvc.GetDataCollectionById(e.ColId)
+	if err != nil {
+		return 0, err
+	}
+
+	// file
+	if err := os.MkdirAll(GetExportDir(), os.ModePerm); err != nil {
+		return 0, trace.TraceError(err)
+	}
+	f, err := os.Create(GetExportFilePath(e))
+	if err != nil {
+		return 0, trace.TraceError(err)
+	}
+	defer f.Close()
+
+	return Export(f, dc.Name, query, e.Format)
+}
+
+// FailInterruptedExports mark background result exports left pending or
+// running by previous processes as failed
+func FailInterruptedExports() (err error) {
+	return mongo.GetMongoCol(interfaces.ModelColNameResultExport).Update(bson.M{
+		"create_ts": bson.M{"$lt": startTs},
+		"status": bson.M{"$in": []string{
+			constants.ResultExportStatusPending,
+			constants.ResultExportStatusRunning,
+		}},
+	}, bson.M{
+		"$set": bson.M{
+			"status": constants.ResultExportStatusError,
+			"error":  errors.ErrorResultExportInterrupted.Error(),
+			"end_ts": time.Now(),
+		},
+	})
+}
+
+// CleanupExports periodically remove background result exports created
+// before the retention, along with their files
+func CleanupExports() {
+	for {
+		if err := cleanupExports(); err != nil {
+			trace.PrintError(err)
+		}
+
+		time.Sleep(exportCleanupInterval)
+	}
+}
+
+func cleanupExports() (err error) {
+	modelSvc, err := service.GetService()
+	if err != nil {
+		return err
+	}
+	exports, err := modelSvc.GetResultExportList(bson.M{
+		"create_ts": bson.M{"$lt": time.Now().Add(-constants.ResultExportExpireHours * time.Hour)},
+		"status":    bson.M{"$ne": constants.ResultExportStatusRunning},
+	}, nil)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			return nil
+		}
+		return err
+	}
+	for _, e := range exports {
+		if err := os.Remove(GetExportFilePath(&e)); err != nil && !os.IsNotExist(err) {
+			trace.PrintError(err)
+			continue
+		}
+		if err := mongo.GetMongoCol(interfaces.ModelColNameResultExport).DeleteId(e.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package result
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestGetXlsxColumnName(t *testing.T) {
+	require.Equal(t, "A", getXlsxColumnName(0))
+	require.Equal(t, "Z", getXlsxColumnName(25))
+	require.Equal(t, "AA", getXlsxColumnName(26))
+	require.Equal(t, "AZ", getXlsxColumnName(51))
+	require.Equal(t, "BA", getXlsxColumnName(52))
+}
+
+func TestFlattenResult(t *testing.T) {
+	id := primitive.NewObjectID()
+	r := bson.D{
+		{"_id", id},
+		{"title", "x"},
+		{"author", bson.D{{"name", "a"}, {"meta", bson.D{{"age", int32(1)}}}}},
+		{"tags", bson.A{"t1", "t2"}},
+		{"empty", nil},
+	}
+	f := flattenResult(r)
+	require.Equal(t, []string{"_id", "title", "author.name", "author.meta.age", "tags", "empty"}, f.keys)
+	require.Equal(t, id.Hex(), f.values["_id"])
+	require.Equal(t, "a", f.values["author.name"])
+	require.Equal(t, "1", f.values["author.meta.age"])
+	require.Equal(t, `["t1","t2"]`, f.values["tags"])
+	require.Equal(t, "", f.values["empty"])
+	require.Equal(t, []string{"x", "", "a"}, f.row([]string{"title", "missing", "author.name"}))
+}
+
+func TestExportWriter(t *testing.T) {
+	r := bson.D{{"title", "x,y"}, {"author", bson.D{{"name", "<a>"}}}}
+	keys := []string{"title", "author.name"}
+
+	// csv
+	var buf bytes.Buffer
+	ew, err := newExportWriter(&buf, constants.ResultExportFormatCsv)
+	require.Nil(t, err)
+	require.Nil(t, ew.WriteHeader(keys))
+	require.Nil(t, ew.WriteRecord(r))
+	require.Nil(t, ew.Close())
+	require.Equal(t, "title,author.name\n\"x,y\",<a>\n", buf.String())
+
+	// jsonl
+	buf.Reset()
+	ew, err = newExportWriter(&buf, constants.ResultExportFormatJsonl)
+	require.Nil(t, err)
+	require.Nil(t, ew.WriteRecord(r))
+	require.Nil(t, ew.WriteRecord(r))
+	require.Nil(t, ew.Close())
+	require.Equal(t, strings.Repeat(`{"author":{"name":"<a>"},"title":"x,y"}`+"\n", 2), buf.String())
+
+	// xlsx
+	buf.Reset()
+	ew, err = newExportWriter(&buf, constants.ResultExportFormatXlsx)
+	require.Nil(t, err)
+	require.Nil(t, ew.WriteHeader(keys))
+	require.Nil(t, ew.WriteRecord(r))
+	require.Nil(t, ew.Close())
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.Nil(t, err)
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			require.Nil(t, err)
+			data, err := ioutil.ReadAll(rc)
+			require.Nil(t, err)
+			sheet = string(data)
+		}
+	}
+	require.Contains(t, sheet, `<c r="B1" t="inlineStr"><is><t xml:space="preserve">author.name</t></is></c>`)
+	require.Contains(t, sheet, `<c r="B2" t="inlineStr"><is><t xml:space="preserve">&lt;a&gt;</t></is></c>`)
+
+	// xlsx with max rows
+	buf.Reset()
+	ew, err = newExportWriter(&buf, constants.ResultExportFormatXlsx)
+	require.Nil(t, err)
+	require.Nil(t, ew.WriteHeader(keys))
+	ew.(*xlsxExportWriter).rows = xlsxMaxRows - 1
+	require.Nil(t, ew.WriteRecord(r))
+	require.Equal(t, errors.ErrorResultExportTooManyRows, ew.WriteRecord(r))
+
+	// invalid format
+	_, err = newExportWriter(&buf, "unknown")
+	require.NotNil(t, err)
+}
@@ -0,0 +1,281 @@
+package result
+
+import (
+	"fmt"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	"github.com/crawlab-team/go-trace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"strings"
+	"time"
+)
+
+// ToResultRecords convert docs to records, of which fields can be read and added
+func ToResultRecords(docs ...interface{}) (records []bson.M, err error) {
+	records = make([]bson.M, len(docs))
+	for i, doc := range docs {
+		records[i], err = toResultBsonM(doc)
+		if err != nil {
+			return nil, trace.TraceError(err)
+		}
+	}
+	return records, nil
+}
+
+// GetDataFieldType type of the value of a field (constants.DataFieldType*)
+func GetDataFieldType(v interface{}) (t string) {
+	switch v.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return constants.DataFieldTypeNull
+	case string, primitive.ObjectID:
+		return constants.DataFieldTypeString
+	case int, int32, int64, float32, float64, primitive.Decimal128:
+		return constants.DataFieldTypeNumber
+	case bool:
+		return constants.DataFieldTypeBoolean
+	case time.Time, primitive.DateTime, primitive.Timestamp:
+		return constants.DataFieldTypeDate
+	case bson.A, []interface{}:
+		return constants.DataFieldTypeArray
+	default:
+		return constants.DataFieldTypeObject
+	}
+}
+
+// DataFieldStats observed types and null counts of fields of records, which
+// can be aggregated across batches with Add
+type DataFieldStats struct {
+	total  int
+	keys   []string
+	fields map[string]*dataFieldStat
+}
+
+type dataFieldStat struct {
+	count int64
+	types map[string]int64
+}
+
+// NewDataFieldStats stats of fields of records, excluding internal fields
+// prefixed with "_" such as _id and _tid
+func NewDataFieldStats(records []bson.M) (s *DataFieldStats) {
+	s = &DataFieldStats{
+		fields: map[string]*dataFieldStat{},
+	}
+	s.Add(records)
+	return s
+}
+
+// Add aggregate stats of fields of records
+func (s *DataFieldStats) Add(records []bson.M) {
+	s.total += len(records)
+	for _, r := range records {
+		for key, value := range r {
+			if strings.HasPrefix(key, "_") {
+				continue
+			}
+			fs, ok := s.fields[key]
+			if !ok {
+				fs = &dataFieldStat{types: map[string]int64{}}
+				s.fields[key] = fs
+				s.keys = append(s.keys, key)
+			}
+			t := GetDataFieldType(value)
+			fs.types[t]++
+			if t != constants.DataFieldTypeNull {
+				fs.count++
+			}
+		}
+	}
+}
+
+// Save update fields of the data collection with the stats of records inserted
+// by the task, of which fields first seen are added
+func (s *DataFieldStats) Save(colId, taskId primitive.ObjectID) (err error) {
+	if s.total == 0 {
+		return nil
+	}
+	col := mongo.GetMongoCol(interfaces.ModelColNameDataField)
+	now := time.Now()
+
+	for _, key := range s.keys {
+		fs := s.fields[key]
+		inc := bson.M{
+			"count":      fs.count,
+			"null_count": int64(s.total) - fs.count,
+		}
+		for t, n := range fs.types {
+			inc["types."+t] = n
+		}
+		query := bson.M{"col_id": colId, "key": key}
+		update := bson.M{
+			"$inc": inc,
+			"$set": bson.M{
+				"last_task_id": taskId,
+				"last_seen_ts": now,
+			},
+			"$setOnInsert": bson.M{
+				"_id":           primitive.NewObjectID(),
+				"name":          key,
+				"type":          "",
+				"required":      false,
+				"first_task_id": taskId,
+				"first_seen_ts": now,
+			},
+		}
+		opts := options.Update().SetUpsert(true)
+		if err := col.UpdateWithOptions(query, update, opts); err != nil {
+			// field added concurrently by others
+			if err := col.UpdateWithOptions(query, update, opts); err != nil {
+				return err
+			}
+		}
+	}
+
+	// declared fields first seen
+	if err := col.Update(bson.M{
+		"col_id":        colId,
+		"key":           bson.M{"$in": s.keys},
+		"first_task_id": primitive.NilObjectID,
+	}, bson.M{
+		"$set": bson.M{
+			"first_task_id": taskId,
+			"first_seen_ts": now,
+		},
+	}); err != nil {
+		return err
+	}
+
+	// fields seen before but absent in records
+	keys := s.keys
+	if keys == nil {
+		keys = []string{}
+	}
+	if err := col.Update(bson.M{
+		"col_id":        colId,
+		"key":           bson.M{"$nin": keys},
+		"first_task_id": bson.M{"$ne": primitive.NilObjectID},
+	}, bson.M{
+		"$inc": bson.M{"null_count": s.total},
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Schema fields declared by users for the data collection, against which
+// records are validated according to the schema mode
+type Schema struct {
+	Mode   string             // constants.DataCollectionSchemaMode*
+	Fields []models.DataField // fields with declared types or required
+}
+
+// GetSchema get the schema of the data collection, nil if records are not
+// validated
+func GetSchema(colId primitive.ObjectID) (s *Schema, err error) {
+	modelSvc, err := service.GetService()
+	if err != nil {
+		return nil, err
+	}
+	dc, err := modelSvc.GetDataCollectionById(colId)
+	if err != nil {
+		return nil, err
+	}
+	switch dc.SchemaMode {
+	case constants.DataCollectionSchemaModeFlag, constants.DataCollectionSchemaModeReject:
+	default:
+		return nil, nil
+	}
+	fields, err := modelSvc.GetDataFieldList(bson.M{
+		"col_id": colId,
+		"$or": []bson.M{
+			{"required": true},
+			{"type": bson.M{"$ne": ""}},
+		},
+	}, nil)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return &Schema{
+		Mode:   dc.SchemaMode,
+		Fields: fields,
+	}, nil
+}
+
+// Validate errors of the record not conforming to declared fields
+func (s *Schema) Validate(r bson.M) (errs []string) {
+	for _, f := range s.Fields {
+		value := r[f.Key]
+		t := GetDataFieldType(value)
+		if t == constants.DataFieldTypeNull {
+			if f.Required {
+				errs = append(errs, fmt.Sprintf("%s: required", f.Key))
+			}
+			continue
+		}
+		if f.Type != "" && !isDataFieldTypeMatched(f.Type, t, value) {
+			errs = append(errs, fmt.Sprintf("%s: expected %s, got %s", f.Key, f.Type, t))
+		}
+	}
+	return errs
+}
+
+// ValidateRecords validate records against the schema, of which those not
+// conforming are flagged with errors or excluded according to the schema
+// mode. It returns the records to insert and errors of those not conforming.
+func (s *Schema) ValidateRecords(records []bson.M) (res []bson.M, errs [][]string) {
+	for _, r := range records {
+		recordErrs := s.Validate(r)
+		if len(recordErrs) == 0 {
+			res = append(res, r)
+			continue
+		}
+		errs = append(errs, recordErrs)
+		if s.Mode == constants.DataCollectionSchemaModeFlag {
+			r[constants.ResultFieldErrors] = recordErrs
+			res = append(res, r)
+		}
+	}
+	return res, errs
+}
+
+// IsValidDataFieldType whether the type can be declared for fields
+func IsValidDataFieldType(t string) (ok bool) {
+	switch t {
+	case "",
+		constants.DataFieldTypeString,
+		constants.DataFieldTypeNumber,
+		constants.DataFieldTypeBoolean,
+		constants.DataFieldTypeDate,
+		constants.DataFieldTypeObject,
+		constants.DataFieldTypeArray:
+		return true
+	default:
+		return false
+	}
+}
+
+func isDataFieldTypeMatched(declared, t string, value interface{}) (ok bool) {
+	if declared == t {
+		return true
+	}
+	// dates in json are strings
+	if declared == constants.DataFieldTypeDate && t == constants.DataFieldTypeString {
+		str, _ := value.(string)
+		_, err := time.Parse(time.RFC3339, str)
+		return err == nil
+	}
+	return false
+}
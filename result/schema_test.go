@@ -0,0 +1,87 @@
+package result
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func TestGetDataFieldType(t *testing.T) {
+	require.Equal(t, constants.DataFieldTypeNull, GetDataFieldType(nil))
+	require.Equal(t, constants.DataFieldTypeString, GetDataFieldType("a"))
+	require.Equal(t, constants.DataFieldTypeNumber, GetDataFieldType(float64(1)))
+	require.Equal(t, constants.DataFieldTypeNumber, GetDataFieldType(int32(1)))
+	require.Equal(t, constants.DataFieldTypeBoolean, GetDataFieldType(true))
+	require.Equal(t, constants.DataFieldTypeDate, GetDataFieldType(primitive.NewDateTimeFromTime(time.Now())))
+	require.Equal(t, constants.DataFieldTypeArray, GetDataFieldType(bson.A{1}))
+	require.Equal(t, constants.DataFieldTypeObject, GetDataFieldType(bson.M{"a": 1}))
+}
+
+func TestNewDataFieldStats(t *testing.T) {
+	records, err := ToResultRecords(
+		map[string]interface{}{"_tid": primitive.NewObjectID(), "title": "a", "price": 1.5},
+		map[string]interface{}{"title": nil, "price": "n/a"},
+		map[string]interface{}{"title": "c"},
+	)
+	require.Nil(t, err)
+
+	s := NewDataFieldStats(records)
+	require.Equal(t, 3, s.total)
+	require.ElementsMatch(t, []string{"title", "price"}, s.keys)
+	require.Equal(t, int64(2), s.fields["title"].count)
+	require.Equal(t, map[string]int64{constants.DataFieldTypeString: 2, constants.DataFieldTypeNull: 1}, s.fields["title"].types)
+	require.Equal(t, int64(2), s.fields["price"].count)
+	require.Equal(t, map[string]int64{constants.DataFieldTypeNumber: 1, constants.DataFieldTypeString: 1}, s.fields["price"].types)
+
+	// aggregated across batches
+	records, err = ToResultRecords(map[string]interface{}{"title": "d", "url": "e"})
+	require.Nil(t, err)
+	s.Add(records)
+	require.Equal(t, 4, s.total)
+	require.ElementsMatch(t, []string{"title", "price", "url"}, s.keys)
+	require.Equal(t, int64(3), s.fields["title"].count)
+	require.Equal(t, int64(1), s.fields["url"].count)
+}
+
+func TestSchema_ValidateRecords(t *testing.T) {
+	fields := []models.DataField{
+		{Key: "title", Required: true},
+		{Key: "price", Type: constants.DataFieldTypeNumber},
+		{Key: "date", Type: constants.DataFieldTypeDate},
+	}
+	newRecords := func() []bson.M {
+		return []bson.M{
+			{"title": "a", "price": 1.5, "date": "2021-01-01T00:00:00Z"},
+			{"title": nil, "price": "n/a"},
+			{"title": "c", "date": "yesterday"},
+		}
+	}
+
+	// flag
+	s := &Schema{Mode: constants.DataCollectionSchemaModeFlag, Fields: fields}
+	res, errs := s.ValidateRecords(newRecords())
+	require.Len(t, res, 3)
+	require.Len(t, errs, 2)
+	require.Equal(t, []string{"title: required", "price: expected number, got string"}, errs[0])
+	require.Equal(t, []string{"date: expected date, got string"}, errs[1])
+	require.Nil(t, res[0][constants.ResultFieldErrors])
+	require.Equal(t, errs[0], res[1][constants.ResultFieldErrors])
+
+	// reject
+	s = &Schema{Mode: constants.DataCollectionSchemaModeReject, Fields: fields}
+	res, errs = s.ValidateRecords(newRecords())
+	require.Len(t, res, 1)
+	require.Len(t, errs, 2)
+	require.Equal(t, "a", res[0]["title"])
+}
+
+func TestIsValidDataFieldType(t *testing.T) {
+	require.True(t, IsValidDataFieldType(""))
+	require.True(t, IsValidDataFieldType(constants.DataFieldTypeDate))
+	require.False(t, IsValidDataFieldType(constants.DataFieldTypeNull))
+	require.False(t, IsValidDataFieldType("unknown"))
+}
@@ -17,8 +17,9 @@ type Service struct {
 	modelColSvc interfaces.ModelBaseService
 
 	// internals
-	id primitive.ObjectID     // id of models.DataCollection
-	dc *models.DataCollection // models.DataCollection
+	id           primitive.ObjectID     // id of models.DataCollection
+	dc           *models.DataCollection // models.DataCollection
+	dedupIndexes sync.Map               // names of dedup indexes which are ensured
 }
 
 func (svc *Service) GetId() (id primitive.ObjectID) {
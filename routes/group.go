@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"github.com/luke513009828/crawlab-core/controllers"
 	"github.com/luke513009828/crawlab-core/middlewares"
 	"github.com/gin-gonic/gin"
 )
@@ -18,3 +19,8 @@ func NewRouterGroups(app *gin.Engine) (groups *RouterGroups) {
 		FilerGroup:     app.Group("/filer", middlewares.FilerAuthorizationMiddleware()),
 	}
 }
+
+// RbacGroup authorized group with permissions of the current user on the controller checked
+func (g *RouterGroups) RbacGroup(id controllers.ControllerId) *gin.RouterGroup {
+	return g.AuthGroup.Group("", middlewares.RbacMiddleware(id))
+}
@@ -86,52 +86,73 @@ func InitRoutes(app *gin.Engine) (err error) {
 	svc := NewRouterService(app)
 
 	// node
-	svc.RegisterListControllerToGroup(groups.AuthGroup, "/nodes", controllers.NodeController)
+	svc.RegisterListControllerToGroup(groups.RbacGroup(controllers.ControllerIdNode), "/nodes", controllers.NodeController)
 
 	// project
-	svc.RegisterListControllerToGroup(groups.AuthGroup, "/projects", controllers.ProjectController)
+	svc.RegisterListControllerToGroup(groups.RbacGroup(controllers.ControllerIdProject), "/projects", controllers.ProjectController)
 
 	// user
-	svc.RegisterListActionControllerToGroup(groups.AuthGroup, "/users", controllers.UserController)
+	svc.RegisterListActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdUser), "/users", controllers.UserController)
+
+	// current user
+	svc.RegisterActionControllerToGroup(groups.AuthGroup, "/me", controllers.MeController)
 
 	// spider
-	svc.RegisterListActionControllerToGroup(groups.AuthGroup, "/spiders", controllers.SpiderController)
+	svc.RegisterListActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdSpider), "/spiders", controllers.SpiderController)
 
 	// task
-	svc.RegisterListActionControllerToGroup(groups.AuthGroup, "/tasks", controllers.TaskController)
+	svc.RegisterListActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdTask), "/tasks", controllers.TaskController)
 
 	// tag
-	svc.RegisterListControllerToGroup(groups.AuthGroup, "/tags", controllers.TagController)
+	svc.RegisterListControllerToGroup(groups.RbacGroup(controllers.ControllerIdTag), "/tags", controllers.TagController)
 
 	// setting
-	svc.RegisterListControllerToGroup(groups.AuthGroup, "/settings", controllers.SettingController)
+	svc.RegisterListControllerToGroup(groups.RbacGroup(controllers.ControllerIdSetting), "/settings", controllers.SettingController)
 
 	// color
-	svc.RegisterActionControllerToGroup(groups.AuthGroup, "/colors", controllers.ColorController)
+	svc.RegisterActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdColor), "/colors", controllers.ColorController)
 
 	// plugin
-	svc.RegisterListActionControllerToGroup(groups.AuthGroup, "/plugins", controllers.PluginController)
+	svc.RegisterListActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdPlugin), "/plugins", controllers.PluginController)
 
 	// data collection
-	svc.RegisterListControllerToGroup(groups.AuthGroup, "/data/collections", controllers.DataCollectionController)
+	svc.RegisterListActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdDataCollection), "/data/collections", controllers.DataCollectionController)
+
+	// data source
+	svc.RegisterListActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdDataSource), "/data/sources", controllers.DataSourceController)
 
 	// result
-	svc.RegisterActionControllerToGroup(groups.AuthGroup, "/results", controllers.ResultController)
+	svc.RegisterActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdResult), "/results", controllers.ResultController)
 
 	// schedule
-	svc.RegisterListActionControllerToGroup(groups.AuthGroup, "/schedules", controllers.ScheduleController)
+	svc.RegisterListActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdSchedule), "/schedules", controllers.ScheduleController)
 
 	// stats
-	svc.RegisterActionControllerToGroup(groups.AuthGroup, "/stats", controllers.StatsController)
+	svc.RegisterActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdStats), "/stats", controllers.StatsController)
 
 	// token
-	svc.RegisterListControllerToGroup(groups.AuthGroup, "/tokens", controllers.TokenController)
+	svc.RegisterListControllerToGroup(groups.RbacGroup(controllers.ControllerIdToken), "/tokens", controllers.TokenController)
 
 	// plugin do
-	svc.RegisterActionControllerToGroup(groups.AuthGroup, "/plugin-proxy", controllers.PluginProxyController)
+	svc.RegisterActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdPluginDo), "/plugin-proxy", controllers.PluginProxyController)
 
 	// git
-	svc.RegisterListControllerToGroup(groups.AuthGroup, "/gits", controllers.GitController)
+	svc.RegisterListControllerToGroup(groups.RbacGroup(controllers.ControllerIdGit), "/gits", controllers.GitController)
+
+	// variable
+	svc.RegisterListControllerToGroup(groups.RbacGroup(controllers.ControllerIdVariable), "/variables", controllers.VariableController)
+
+	// workflow
+	svc.RegisterListActionControllerToGroup(groups.RbacGroup(controllers.ControllerIdWorkflow), "/workflows", controllers.WorkflowController)
+
+	// notification
+	svc.RegisterListControllerToGroup(groups.RbacGroup(controllers.ControllerIdNotificationSetting), "/notification-settings", controllers.NotificationSettingController)
+	svc.RegisterListControllerToGroup(groups.RbacGroup(controllers.ControllerIdNotificationDelivery), "/notification-deliveries", controllers.NotificationDeliveryController)
+
+	// audit log (read-only)
+	auditLogGroup := groups.RbacGroup(controllers.ControllerIdAuditLog)
+	svc.RegisterHandlerToGroup(auditLogGroup, "/audit-logs", http.MethodGet, controllers.AuditLogController.GetList)
+	svc.RegisterHandlerToGroup(auditLogGroup, "/audit-logs/:id", http.MethodGet, controllers.AuditLogController.Get)
 
 	// login
 	svc.RegisterActionControllerToGroup(groups.AnonymousGroup, "/", controllers.LoginController)
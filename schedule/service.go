@@ -1,17 +1,22 @@
 package schedule
 
 import (
+	"github.com/apex/log"
 	"github.com/luke513009828/crawlab-core/config"
+	"github.com/luke513009828/crawlab-core/constants"
 	"github.com/luke513009828/crawlab-core/interfaces"
 	"github.com/luke513009828/crawlab-core/models/delegate"
 	"github.com/luke513009828/crawlab-core/models/models"
 	"github.com/luke513009828/crawlab-core/models/service"
 	"github.com/luke513009828/crawlab-core/spider/admin"
+	"github.com/luke513009828/crawlab-core/task/scheduler"
 	"github.com/luke513009828/crawlab-core/utils"
+	"github.com/crawlab-team/crawlab-db/mongo"
 	"github.com/crawlab-team/go-trace"
 	"github.com/robfig/cron/v3"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/dig"
 	"sync"
 	"time"
@@ -20,8 +25,9 @@ import (
 type Service struct {
 	// dependencies
 	interfaces.WithConfigPath
-	modelSvc service.ModelService
-	adminSvc interfaces.SpiderAdminService
+	modelSvc     service.ModelService
+	adminSvc     interfaces.SpiderAdminService
+	schedulerSvc interfaces.TaskSchedulerService
 
 	// settings variables
 	loc            *time.Location
@@ -174,6 +180,11 @@ func (svc *Service) fetch() (err error) {
 	}
 	svc.schedules, err = svc.modelSvc.GetScheduleList(query, nil)
 	if err != nil {
+		// no enabled schedules, of which existing entries should be removed
+		if err == mongo2.ErrNoDocuments {
+			svc.schedules = nil
+			return nil
+		}
 		return err
 	}
 	return nil
@@ -195,16 +206,28 @@ func (svc *Service) schedule(id primitive.ObjectID) (fn func()) {
 			return
 		}
 
+		// overlap policy
+		ok, err := svc.handleOverlap(s, spider)
+		if err != nil {
+			trace.PrintError(err)
+			return
+		}
+		if !ok {
+			return
+		}
+
 		// options
 		opts := &interfaces.SpiderRunOptions{
-			Mode:       s.GetMode(),
-			NodeIds:    s.GetNodeIds(),
-			NodeTags:   s.GetNodeTags(),
-			Cmd:        s.GetCmd(),
-			Param:      s.GetParam(),
-			Priority:   s.GetPriority(),
-			ScheduleId: s.GetId(),
-			UserId:     s.UserId,
+			Mode:          s.GetMode(),
+			NodeIds:       s.GetNodeIds(),
+			NodeTags:      s.GetNodeTags(),
+			NodeTagsMatch: s.NodeTagsMatch,
+			Cmd:           s.GetCmd(),
+			Param:         s.GetParam(),
+			Priority:      s.GetPriority(),
+			Timeout:       s.Timeout,
+			ScheduleId:    s.GetId(),
+			UserId:        s.UserId,
 		}
 
 		// normalize options
@@ -238,6 +261,68 @@ func (svc *Service) schedule(id primitive.ObjectID) (fn func()) {
 	}
 }
 
+// handleOverlap apply overlap policy of the schedule if previous run is still
+// running, and return whether to proceed with the new run
+func (svc *Service) handleOverlap(s *models.Schedule, spider *models.Spider) (ok bool, err error) {
+	switch s.OverlapPolicy {
+	case constants.ScheduleOverlapPolicySkip:
+		// skip if previous run is still running
+		total, err := svc.modelSvc.GetBaseService(interfaces.ModelIdTask).Count(bson.M{
+			"schedule_id": s.Id,
+			"status":      bson.M{"$in": []string{constants.TaskStatusPending, constants.TaskStatusRunning}},
+		})
+		if err != nil {
+			return false, err
+		}
+		if total > 0 {
+			return false, svc.recordSkip(s, constants.ScheduleSkipReasonStillRunning)
+		}
+
+		// skip if spider reaches max concurrency
+		if spider.MaxConcurrency > 0 {
+			total, err := svc.modelSvc.GetBaseService(interfaces.ModelIdTask).Count(bson.M{
+				"spider_id": spider.Id,
+				"status":    constants.TaskStatusRunning,
+			})
+			if err != nil {
+				return false, err
+			}
+			if total >= spider.MaxConcurrency {
+				return false, svc.recordSkip(s, constants.ScheduleSkipReasonMaxConcurrency)
+			}
+		}
+	case constants.ScheduleOverlapPolicyCancel:
+		// cancel previous run
+		tasks, err := svc.modelSvc.GetTaskList(bson.M{
+			"schedule_id": s.Id,
+			"status":      bson.M{"$in": []string{constants.TaskStatusPending, constants.TaskStatusRunning}},
+		}, nil)
+		if err != nil && err != mongo2.ErrNoDocuments {
+			return false, err
+		}
+		for _, t := range tasks {
+			if err := svc.schedulerSvc.Cancel(t.Id); err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+// recordSkip record the skipped cron tick of the schedule
+func (svc *Service) recordSkip(s *models.Schedule, reason string) (err error) {
+	log.Infof("schedule[%s] skipped: %s", s.Id.Hex(), reason)
+	return mongo.GetMongoCol(interfaces.ModelColNameSchedule).UpdateId(s.Id, bson.M{
+		"$set": bson.M{
+			"last_skip_ts":     time.Now(),
+			"last_skip_reason": reason,
+		},
+		"$inc": bson.M{
+			"skip_count": 1,
+		},
+	})
+}
+
 func NewScheduleService(opts ...Option) (svc2 interfaces.ScheduleService, err error) {
 	// service
 	svc := &Service{
@@ -262,12 +347,17 @@ func NewScheduleService(opts ...Option) (svc2 interfaces.ScheduleService, err er
 	if err := c.Provide(admin.ProvideSpiderAdminService(svc.GetConfigPath())); err != nil {
 		return nil, trace.TraceError(err)
 	}
+	if err := c.Provide(scheduler.ProvideGetTaskSchedulerService(svc.GetConfigPath())); err != nil {
+		return nil, trace.TraceError(err)
+	}
 	if err := c.Invoke(func(
 		modelSvc service.ModelService,
 		adminSvc interfaces.SpiderAdminService,
+		schedulerSvc interfaces.TaskSchedulerService,
 	) {
 		svc.modelSvc = modelSvc
 		svc.adminSvc = adminSvc
+		svc.schedulerSvc = schedulerSvc
 	}); err != nil {
 		return nil, trace.TraceError(err)
 	}
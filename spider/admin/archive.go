@@ -0,0 +1,231 @@
+package admin
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/utils"
+	"github.com/crawlab-team/go-trace"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// files in spider archives, of which spider files are in the files directory
+const (
+	archiveSpiderFileName    = "spider.json"
+	archiveSchedulesFileName = "schedules.json"
+	archiveFilesDirName      = "files"
+)
+
+func (svc *Service) Export(id primitive.ObjectID) (filePath string, err error) {
+	// spider
+	s, err := svc.modelSvc.GetSpiderById(id)
+	if err != nil {
+		return "", err
+	}
+	if !s.ColId.IsZero() {
+		dc, err := svc.modelSvc.GetDataCollectionById(s.ColId)
+		if err == nil {
+			s.ColName = dc.Name
+		}
+	}
+	s.GitPassword = ""
+	s.Envs = utils.MaskSecretEnvs(s.Envs)
+
+	// schedules
+	schedules, err := svc.getSchedules(s.Id)
+	if err != nil {
+		return "", err
+	}
+
+	// temporary directory
+	dirPath, err := ioutil.TempDir("", "crawlab-spider-")
+	if err != nil {
+		return "", trace.TraceError(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	// metadata
+	if err := writeArchiveJsonFile(filepath.Join(dirPath, archiveSpiderFileName), s); err != nil {
+		return "", err
+	}
+	if err := writeArchiveJsonFile(filepath.Join(dirPath, archiveSchedulesFileName), schedules); err != nil {
+		return "", err
+	}
+
+	// spider files
+	fsSvc, err := svc.syncSvc.GetFsService(s.Id)
+	if err != nil {
+		return "", err
+	}
+	filesDirPath := filepath.Join(dirPath, archiveFilesDirName)
+	if err := fsSvc.GetFsService().GetFs().SyncRemoteToLocal(fsSvc.GetFsPath(), filesDirPath); err != nil {
+		return "", err
+	}
+
+	// compress
+	var files []*os.File
+	for _, name := range []string{archiveSpiderFileName, archiveSchedulesFileName, archiveFilesDirName} {
+		f, err := os.Open(filepath.Join(dirPath, name))
+		if err != nil {
+			return "", trace.TraceError(err)
+		}
+		defer f.Close()
+		files = append(files, f)
+	}
+	zipFile, err := ioutil.TempFile("", "crawlab-spider-*.zip")
+	if err != nil {
+		return "", trace.TraceError(err)
+	}
+	_ = zipFile.Close()
+	if err := utils.Compress(files, zipFile.Name()); err != nil {
+		_ = os.Remove(zipFile.Name())
+		return "", trace.TraceError(err)
+	}
+
+	return zipFile.Name(), nil
+}
+
+func (svc *Service) Import(filePath string, args ...interface{}) (id primitive.ObjectID, err error) {
+	u := utils.GetUserFromArgs(args...)
+
+	// validate archive
+	if err := validateArchive(filePath); err != nil {
+		return id, err
+	}
+
+	// decompress to temporary directory
+	dirPath, err := ioutil.TempDir("", "crawlab-spider-")
+	if err != nil {
+		return id, trace.TraceError(err)
+	}
+	defer os.RemoveAll(dirPath)
+	if err := utils.DeCompressByPath(filePath, dirPath); err != nil {
+		return id, trace.TraceError(err)
+	}
+
+	// spider
+	var s models.Spider
+	if err := readArchiveJsonFile(filepath.Join(dirPath, archiveSpiderFileName), &s); err != nil {
+		return id, err
+	}
+	s.Id = primitive.NewObjectID()
+	s.Stat = nil
+	// values of secret envs are masked in archives, which need to be re-entered
+	s.Envs = utils.RestoreSecretEnvs(s.Envs, nil)
+	// references to resources of the original instance
+	s.ProjectId = primitive.NilObjectID
+	s.GitId = primitive.NilObjectID
+	s.DataSourceId = primitive.NilObjectID
+	s.ColId = primitive.NilObjectID
+	s.NodeIds = nil
+	if s.Mode == constants.RunTypeSelectedNodes {
+		s.Mode = constants.RunTypeRandom
+	}
+	if s.ColName != "" {
+		dc, err := svc.getDataCollection(s.ColName, u)
+		if err != nil {
+			return id, err
+		}
+		s.ColId = dc.Id
+	}
+	if err := svc.addSpider(&s, u); err != nil {
+		return id, err
+	}
+
+	// schedules
+	var schedules []models.Schedule
+	if schedulesFilePath := filepath.Join(dirPath, archiveSchedulesFileName); utils.Exists(schedulesFilePath) {
+		if err := readArchiveJsonFile(schedulesFilePath, &schedules); err != nil {
+			return id, err
+		}
+	}
+	for _, sch := range schedules {
+		sch.NodeIds = nil
+		if sch.Mode == constants.RunTypeSelectedNodes {
+			sch.Mode = constants.RunTypeRandom
+		}
+		sch.UserId = primitive.NilObjectID
+		if u != nil {
+			sch.UserId = u.GetId()
+		}
+		if err := svc.addSchedule(&sch, s.Id, u); err != nil {
+			return id, err
+		}
+	}
+
+	// spider files
+	filesDirPath := filepath.Join(dirPath, archiveFilesDirName)
+	if utils.Exists(filesDirPath) {
+		fsSvc, err := svc.syncSvc.GetFsService(s.Id)
+		if err != nil {
+			return id, err
+		}
+		if err := fsSvc.GetFsService().GetFs().SyncLocalToRemote(filesDirPath, fsSvc.GetFsPath()); err != nil {
+			return id, err
+		}
+
+		// regenerate scrapy project files of configurable spider from Spiderfile
+		if err := svc.configSpiderSvc.Regenerate(s.Id); err != nil {
+			return id, err
+		}
+	}
+
+	return s.Id, nil
+}
+
+// validateArchive check whether the zip file is a spider archive, of which
+// entries are not allowed to be outside of the directory decompressed to
+func validateArchive(filePath string) (err error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return errors.ErrorSpiderInvalidArchive
+	}
+	defer r.Close()
+	hasSpider := false
+	for _, f := range r.File {
+		name := strings.TrimPrefix(f.Name, "/")
+		for _, part := range strings.Split(name, "/") {
+			if part == ".." {
+				return errors.ErrorSpiderInvalidArchive
+			}
+		}
+		if name == archiveSpiderFileName {
+			hasSpider = true
+		}
+	}
+	if !hasSpider {
+		return errors.ErrorSpiderInvalidArchive
+	}
+	return nil
+}
+
+func writeArchiveJsonFile(filePath string, v interface{}) (err error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return trace.TraceError(err)
+	}
+	if err := ioutil.WriteFile(filePath, data, 0644); err != nil {
+		return trace.TraceError(err)
+	}
+	return nil
+}
+
+func readArchiveJsonFile(filePath string, v interface{}) (err error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.ErrorSpiderInvalidArchive
+		}
+		return trace.TraceError(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.ErrorSpiderInvalidArchive
+	}
+	return nil
+}
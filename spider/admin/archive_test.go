@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"archive/zip"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/utils"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateArchive(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "crawlab-spider-archive-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dirPath)
+
+	// archive compressed as exported
+	require.Nil(t, writeArchiveJsonFile(filepath.Join(dirPath, archiveSpiderFileName), &models.Spider{Name: "test"}))
+	require.Nil(t, os.MkdirAll(filepath.Join(dirPath, archiveFilesDirName), os.ModePerm))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dirPath, archiveFilesDirName, "main.py"), []byte("print(1)"), 0644))
+	var files []*os.File
+	for _, name := range []string{archiveSpiderFileName, archiveFilesDirName} {
+		f, err := os.Open(filepath.Join(dirPath, name))
+		require.Nil(t, err)
+		files = append(files, f)
+	}
+	zipFilePath := filepath.Join(dirPath, "spider.zip")
+	require.Nil(t, utils.Compress(files, zipFilePath))
+	require.Nil(t, validateArchive(zipFilePath))
+
+	// decompressed
+	destPath := filepath.Join(dirPath, "dest")
+	require.Nil(t, utils.DeCompressByPath(zipFilePath, destPath))
+	var s models.Spider
+	require.Nil(t, readArchiveJsonFile(filepath.Join(destPath, archiveSpiderFileName), &s))
+	require.Equal(t, "test", s.Name)
+	require.FileExists(t, filepath.Join(destPath, archiveFilesDirName, "main.py"))
+
+	// invalid archives
+	writeZip := func(name string, names ...string) string {
+		p := filepath.Join(dirPath, name)
+		f, err := os.Create(p)
+		require.Nil(t, err)
+		w := zip.NewWriter(f)
+		for _, n := range names {
+			_, err := w.Create(n)
+			require.Nil(t, err)
+		}
+		require.Nil(t, w.Close())
+		require.Nil(t, f.Close())
+		return p
+	}
+	require.Equal(t, errors.ErrorSpiderInvalidArchive, validateArchive(writeZip("no-spider.zip", "files/main.py")))
+	require.Equal(t, errors.ErrorSpiderInvalidArchive, validateArchive(writeZip("slip.zip", "spider.json", "files/../../evil.py")))
+	require.Equal(t, errors.ErrorSpiderInvalidArchive, validateArchive(filepath.Join(dirPath, archiveSpiderFileName)))
+}
@@ -1,27 +1,40 @@
 package admin
 
 import (
+	"context"
+	"fmt"
 	"github.com/apex/log"
 	config2 "github.com/luke513009828/crawlab-core/config"
 	"github.com/luke513009828/crawlab-core/constants"
 	"github.com/luke513009828/crawlab-core/errors"
 	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
 	"github.com/luke513009828/crawlab-core/models/models"
 	"github.com/luke513009828/crawlab-core/models/service"
 	"github.com/luke513009828/crawlab-core/node/config"
+	"github.com/luke513009828/crawlab-core/spider/configspider"
+	"github.com/luke513009828/crawlab-core/spider/sync"
 	"github.com/luke513009828/crawlab-core/task/scheduler"
+	"github.com/luke513009828/crawlab-core/utils"
+	"github.com/crawlab-team/crawlab-db/mongo"
 	"github.com/crawlab-team/go-trace"
 	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/dig"
+	"os"
+	"path"
+	"sort"
 )
 
 type Service struct {
 	// dependencies
-	nodeCfgSvc   interfaces.NodeConfigService
-	modelSvc     service.ModelService
-	schedulerSvc interfaces.TaskSchedulerService
+	nodeCfgSvc      interfaces.NodeConfigService
+	modelSvc        service.ModelService
+	schedulerSvc    interfaces.TaskSchedulerService
+	syncSvc         interfaces.SpiderSyncService
+	configSpiderSvc interfaces.ConfigSpiderService
 
 	// settings
 	cfgPath string
@@ -59,33 +72,163 @@ func (svc *Service) Schedule(id primitive.ObjectID, opts *interfaces.SpiderRunOp
 	// assign tasks
 	_, err = svc.scheduleTasks(s, opts)
 	if err != nil {
-		return  err
+		return err
 	}
 
 	return nil
 }
 
-func (svc *Service) Clone(id primitive.ObjectID, opts *interfaces.SpiderCloneOptions) (err error) {
-	// TODO: implement
-	return nil
+func (svc *Service) Clone(id primitive.ObjectID, opts *interfaces.SpiderCloneOptions, args ...interface{}) (cloneId primitive.ObjectID, err error) {
+	u := utils.GetUserFromArgs(args...)
+
+	// spider
+	s, err := svc.modelSvc.GetSpiderById(id)
+	if err != nil {
+		return cloneId, err
+	}
+
+	// clone
+	clone := *s
+	clone.Id = primitive.NewObjectID()
+	clone.Name = opts.Name
+	if clone.Name == "" {
+		clone.Name = fmt.Sprintf("%s (copy)", s.Name)
+	}
+	clone.GitId = primitive.NilObjectID
+	if opts.ColName != "" {
+		dc, err := svc.getDataCollection(opts.ColName, u)
+		if err != nil {
+			return cloneId, err
+		}
+		clone.ColId = dc.Id
+	}
+	if err := svc.addSpider(&clone, u); err != nil {
+		return cloneId, err
+	}
+
+	// schedules (disabled)
+	schedules, err := svc.getSchedules(s.Id)
+	if err != nil {
+		return cloneId, err
+	}
+	for _, sch := range schedules {
+		sch.Enabled = false
+		if err := svc.addSchedule(&sch, clone.Id, u); err != nil {
+			return cloneId, err
+		}
+	}
+
+	// files
+	fsSvc, err := svc.syncSvc.GetFsService(s.Id)
+	if err != nil {
+		return cloneId, err
+	}
+	cloneFsSvc, err := svc.syncSvc.GetFsService(clone.Id)
+	if err != nil {
+		return cloneId, err
+	}
+	if err := fsSvc.GetFsService().SyncToWorkspace(); err != nil {
+		return cloneId, err
+	}
+	if err := cloneFsSvc.GetFsService().GetFs().SyncLocalToRemote(fsSvc.GetWorkspacePath(), cloneFsSvc.GetFsPath()); err != nil {
+		return cloneId, err
+	}
+
+	return clone.Id, nil
 }
 
-func (svc *Service) Delete(id primitive.ObjectID) (err error) {
-	panic("implement me")
+func (svc *Service) Delete(id primitive.ObjectID, opts *interfaces.SpiderDeleteOptions, args ...interface{}) (err error) {
+	u := utils.GetUserFromArgs(args...)
+
+	// spider
+	s, err := svc.modelSvc.GetSpiderById(id)
+	if err != nil {
+		return err
+	}
+
+	// schedules (removed from cron by schedule service as they are no longer fetched)
+	if err := svc.modelSvc.GetBaseService(interfaces.ModelIdSchedule).DeleteList(bson.M{"spider_id": s.Id}, u); err != nil {
+		return err
+	}
+
+	// stat
+	if err := svc.modelSvc.GetBaseService(interfaces.ModelIdSpiderStat).DeleteList(bson.M{"_id": s.Id}, u); err != nil {
+		return err
+	}
+
+	// git
+	if err := svc.modelSvc.GetBaseService(interfaces.ModelIdGit).DeleteList(bson.M{"_id": s.Id}, u); err != nil {
+		return err
+	}
+	if err := svc.modelSvc.GetBaseService(interfaces.ModelIdGitSshKey).DeleteList(bson.M{"_id": s.Id}, u); err != nil {
+		return err
+	}
+
+	// data collection
+	if opts != nil && opts.DeleteDataCollection && !s.ColId.IsZero() {
+		if err := svc.deleteDataCollection(s, u); err != nil {
+			return err
+		}
+	}
+
+	// spider
+	if err := delegate.NewModelDelegate(s, u).Delete(); err != nil {
+		return err
+	}
+
+	// files
+	fsSvc, err := svc.syncSvc.GetFsService(s.Id)
+	if err != nil {
+		return err
+	}
+	if err := fsSvc.GetFsService().GetFs().DeleteDir(fsSvc.GetFsPath()); err != nil {
+		trace.PrintError(err)
+	}
+	if err := fsSvc.GetFsService().GetFs().DeleteDir(path.Clean(fsSvc.GetVersionFsPath(""))); err != nil {
+		trace.PrintError(err)
+	}
+	for _, p := range []string{
+		fsSvc.GetWorkspacePath(),
+		fsSvc.GetRepoPath(),
+		path.Clean(fsSvc.GetVersionWorkspacePath("")),
+	} {
+		if err := os.RemoveAll(p); err != nil {
+			trace.PrintError(err)
+		}
+	}
+
+	return nil
 }
 
 func (svc *Service) scheduleTasks(s *models.Spider, opts *interfaces.SpiderRunOptions) (taskIds []primitive.ObjectID, err error) {
+	// node tags
+	nodeTags, nodeTagsMatch := svc.getNodeTags(s, opts)
+	if opts.Mode == constants.RunTypeSelectedTags && len(nodeTags) == 0 {
+		return nil, errors.ErrorSpiderEmptyNodeTags
+	}
+
+	// code version
+	version, err := svc.getVersion(s, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	// main task
 	mainTask := &models.Task{
-		SpiderId:   s.Id,
-		Mode:       opts.Mode,
-		NodeIds:    opts.NodeIds,
-		NodeTags:   opts.NodeTags,
-		Cmd:        opts.Cmd,
-		Param:      opts.Param,
-		ScheduleId: opts.ScheduleId,
-		Priority:   opts.Priority,
-		UserId:     opts.UserId,
+		SpiderId:        s.Id,
+		Mode:            opts.Mode,
+		NodeIds:         opts.NodeIds,
+		NodeTags:        nodeTags,
+		NodeTagsMatch:   nodeTagsMatch,
+		Cmd:             opts.Cmd,
+		Param:           opts.Param,
+		ScheduleId:      opts.ScheduleId,
+		Priority:        opts.Priority,
+		UserId:          opts.UserId,
+		Envs:            svc.getEnvs(opts),
+		Timeout:         svc.getTimeout(s, opts),
+		Version:         version,
+		IsVersionPinned: opts.Version != "",
 	}
 
 	log.Debugf("[scheduleTasks] opts: %v", opts)
@@ -108,12 +251,17 @@ func (svc *Service) scheduleTasks(s *models.Spider, opts *interfaces.SpiderRunOp
 				SpiderId: s.Id,
 				// TODO: implement associated tasks
 				//ParentId: mainTask.Id,
-				Mode:     opts.Mode,
-				Cmd:      s.Cmd,
-				Param:    opts.Param,
-				NodeId:   nodeId,
-				Priority: opts.Priority,
-				UserId:   opts.UserId,
+				Mode:            opts.Mode,
+				Cmd:             s.Cmd,
+				Param:           opts.Param,
+				NodeId:          nodeId,
+				ScheduleId:      opts.ScheduleId,
+				Priority:        opts.Priority,
+				UserId:          opts.UserId,
+				Envs:            svc.getEnvs(opts),
+				Timeout:         svc.getTimeout(s, opts),
+				Version:         version,
+				IsVersionPinned: opts.Version != "",
 			}
 
 			taskId, err := svc.schedulerSvc.EnqueueWithTaskId(t)
@@ -163,6 +311,180 @@ func (svc *Service) getNodeIds(opts *interfaces.SpiderRunOptions) (nodeIds []pri
 	return nodeIds, nil
 }
 
+// getNodeTags get node tags and how to match them from run options, or from spider if not set
+func (svc *Service) getNodeTags(s *models.Spider, opts *interfaces.SpiderRunOptions) (nodeTags []string, nodeTagsMatch string) {
+	nodeTags, nodeTagsMatch = opts.NodeTags, opts.NodeTagsMatch
+	if len(nodeTags) == 0 {
+		nodeTags = s.NodeTags
+	}
+	if nodeTagsMatch == "" {
+		nodeTagsMatch = s.NodeTagsMatch
+	}
+	if nodeTagsMatch == "" {
+		nodeTagsMatch = constants.NodeTagsMatchAll
+	}
+	return nodeTags, nodeTagsMatch
+}
+
+// getEnvs convert task environment variables in run options to a list sorted by name
+func (svc *Service) getEnvs(opts *interfaces.SpiderRunOptions) (envs []models.Env) {
+	for name, value := range opts.Envs {
+		envs = append(envs, models.Env{Name: name, Value: value})
+	}
+	sort.Slice(envs, func(i, j int) bool {
+		return envs[i].Name < envs[j].Name
+	})
+	return envs
+}
+
+// addSpider add the spider with a new stat
+func (svc *Service) addSpider(s *models.Spider, u interfaces.User) (err error) {
+	if err := delegate.NewModelDelegate(s, u).Add(); err != nil {
+		return err
+	}
+	return delegate.NewModelDelegate(&models.SpiderStat{Id: s.Id}, u).Add()
+}
+
+// addSchedule add the schedule to the spider, which is enabled by schedule
+// service if Enabled is true
+func (svc *Service) addSchedule(sch *models.Schedule, spiderId primitive.ObjectID, u interfaces.User) (err error) {
+	sch.Id = primitive.NewObjectID()
+	sch.SpiderId = spiderId
+	sch.EntryId = 0
+	return delegate.NewModelDelegate(sch, u).Add()
+}
+
+func (svc *Service) getSchedules(spiderId primitive.ObjectID) (schedules []models.Schedule, err error) {
+	schedules, err = svc.modelSvc.GetScheduleList(bson.M{"spider_id": spiderId}, nil)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// getDataCollection get the data collection by name, which is added if not exists
+func (svc *Service) getDataCollection(name string, u interfaces.User) (dc *models.DataCollection, err error) {
+	dc, err = svc.modelSvc.GetDataCollectionByName(name, nil)
+	if err == nil {
+		return dc, nil
+	}
+	if err.Error() != mongo2.ErrNoDocuments.Error() {
+		return nil, err
+	}
+	dc = &models.DataCollection{Name: name}
+	if err := delegate.NewModelDelegate(dc, u).Add(); err != nil {
+		return nil, err
+	}
+	_ = mongo.GetMongoCol(dc.Name).CreateIndex(mongo2.IndexModel{Keys: bson.M{"_tid": 1}})
+	return dc, nil
+}
+
+// deleteDataCollection drop the data collection of the spider with its results
+// and fields, unless it is used by other spiders
+func (svc *Service) deleteDataCollection(s *models.Spider, u interfaces.User) (err error) {
+	count, err := svc.modelSvc.GetBaseService(interfaces.ModelIdSpider).Count(bson.M{
+		"col_id": s.ColId,
+		"_id":    bson.M{"$ne": s.Id},
+	})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	dc, err := svc.modelSvc.GetDataCollectionById(s.ColId)
+	if err != nil {
+		if err.Error() == mongo2.ErrNoDocuments.Error() {
+			return nil
+		}
+		return err
+	}
+	if err := mongo.GetMongoDb("").Collection(dc.Name).Drop(context.Background()); err != nil {
+		return trace.TraceError(err)
+	}
+	if err := svc.modelSvc.GetBaseService(interfaces.ModelIdDataField).ForceDeleteList(bson.M{"col_id": dc.Id}); err != nil {
+		return err
+	}
+	return delegate.NewModelDelegate(dc, u).Delete()
+}
+
+// getVersion get the version of spider files to run from run options, of
+// which files are saved to fs, or the latest committed version if not set, which
+// is committed first if files have been edited since
+func (svc *Service) getVersion(s *models.Spider, opts *interfaces.SpiderRunOptions) (version string, err error) {
+	fsSvc, err := svc.syncSvc.GetFsService(s.Id)
+	if err != nil {
+		return "", err
+	}
+
+	// one commit or run of the spider at a time
+	mu := svc.syncSvc.GetLock(s.Id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if opts.Version == "" {
+		// files to run may have been edited since the latest version
+		dirty, err := fsSvc.IsDirty()
+		if err != nil {
+			return "", err
+		}
+		if dirty {
+			// edited files of git spiders are overwritten by git sync, which
+			// are left unversioned rather than diverged from the remote repo
+			if s.GitUrl != "" {
+				return "", nil
+			}
+			if err := fsSvc.Commit(constants.SpiderAutoCommitMessage); err != nil {
+				return "", err
+			}
+		}
+		return fsSvc.GetVersion()
+	}
+	commit, err := fsSvc.GetVersionCommit(opts.Version)
+	if err != nil {
+		return "", err
+	}
+	version = commit.Hash.String()
+	if err := fsSvc.SyncVersionToFs(version); err != nil {
+		return "", err
+	}
+	if err := svc.pruneVersions(s, fsSvc, version); err != nil {
+		trace.PrintError(err)
+	}
+	return version, nil
+}
+
+// pruneVersions remove files of versions saved to fs except those of the
+// version to run and of unfinished tasks pinned to a version
+func (svc *Service) pruneVersions(s *models.Spider, fsSvc interfaces.SpiderFsService, version string) (err error) {
+	tasks, err := svc.modelSvc.GetTaskList(bson.M{
+		"spider_id":         s.Id,
+		"is_version_pinned": true,
+		"status": bson.M{
+			"$in": []string{constants.TaskStatusPending, constants.TaskStatusRunning},
+		},
+	}, nil)
+	if err != nil && err.Error() != mongo2.ErrNoDocuments.Error() {
+		return err
+	}
+	keep := []string{version}
+	for _, t := range tasks {
+		keep = append(keep, t.Version)
+	}
+	return fsSvc.PruneVersions(keep)
+}
+
+// getTimeout get task timeout from run options, or from spider if not set
+func (svc *Service) getTimeout(s *models.Spider, opts *interfaces.SpiderRunOptions) (timeout int) {
+	if opts.Timeout > 0 {
+		return opts.Timeout
+	}
+	return s.Timeout
+}
+
 func (svc *Service) isMultiTask(opts *interfaces.SpiderRunOptions) (res bool) {
 	if opts.Mode == constants.RunTypeAllNodes {
 		query := bson.M{
@@ -178,6 +500,8 @@ func (svc *Service) isMultiTask(opts *interfaces.SpiderRunOptions) (res bool) {
 		return len(nodes) > 1
 	} else if opts.Mode == constants.RunTypeRandom {
 		return false
+	} else if opts.Mode == constants.RunTypeSelectedTags {
+		return false
 	} else if opts.Mode == constants.RunTypeSelectedNodes {
 		return len(opts.NodeIds) > 1
 	} else {
@@ -206,10 +530,18 @@ func NewSpiderAdminService(opts ...Option) (svc2 interfaces.SpiderAdminService,
 	if err := c.Provide(scheduler.ProvideGetTaskSchedulerService(svc.cfgPath)); err != nil {
 		return nil, trace.TraceError(err)
 	}
-	if err := c.Invoke(func(nodeCfgSvc interfaces.NodeConfigService, modelSvc service.ModelService, schedulerSvc interfaces.TaskSchedulerService) {
+	if err := c.Provide(sync.ProvideSpiderSyncService(svc.cfgPath)); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Provide(configspider.ProvideConfigSpiderService(svc.cfgPath)); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Invoke(func(nodeCfgSvc interfaces.NodeConfigService, modelSvc service.ModelService, schedulerSvc interfaces.TaskSchedulerService, syncSvc interfaces.SpiderSyncService, configSpiderSvc interfaces.ConfigSpiderService) {
 		svc.nodeCfgSvc = nodeCfgSvc
 		svc.modelSvc = modelSvc
 		svc.schedulerSvc = schedulerSvc
+		svc.syncSvc = syncSvc
+		svc.configSpiderSvc = configSpiderSvc
 	}); err != nil {
 		return nil, trace.TraceError(err)
 	}
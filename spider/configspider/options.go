@@ -0,0 +1,13 @@
+package configspider
+
+import (
+	"github.com/luke513009828/crawlab-core/interfaces"
+)
+
+type Option func(svc interfaces.ConfigSpiderService)
+
+func WithConfigPath(path string) Option {
+	return func(svc interfaces.ConfigSpiderService) {
+		svc.SetConfigPath(path)
+	}
+}
@@ -0,0 +1,173 @@
+package configspider
+
+import (
+	"github.com/luke513009828/crawlab-core/config"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/config_spider"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/luke513009828/crawlab-core/spider/sync"
+	"github.com/crawlab-team/go-trace"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/dig"
+)
+
+type Service struct {
+	// dependencies
+	interfaces.WithConfigPath
+	modelSvc service.ModelService
+	syncSvc  interfaces.SpiderSyncService
+}
+
+func (svc *Service) InitSpiderfile(id primitive.ObjectID) (err error) {
+	// spider fs service
+	fsSvc, err := svc.syncSvc.GetFsService(id)
+	if err != nil {
+		return err
+	}
+
+	// save default Spiderfile if not exists, e.g. not cloned or imported
+	if _, err := fsSvc.GetFileInfo(constants.Spiderfile); err != nil {
+		if err := fsSvc.Save(constants.Spiderfile, config_spider.GetDefaultSpiderfile()); err != nil {
+			return err
+		}
+	}
+
+	return svc.Generate(id)
+}
+
+func (svc *Service) Validate(data []byte) (err error) {
+	_, err = svc.parse(data)
+	return err
+}
+
+func (svc *Service) SaveSpiderfile(id primitive.ObjectID, data []byte) (err error) {
+	// validate
+	configData, err := svc.parse(data)
+	if err != nil {
+		return err
+	}
+
+	// spider fs service
+	fsSvc, err := svc.syncSvc.GetFsService(id)
+	if err != nil {
+		return err
+	}
+
+	// save
+	if err := fsSvc.Save(constants.Spiderfile, data); err != nil {
+		return err
+	}
+
+	return svc.generate(fsSvc, configData)
+}
+
+func (svc *Service) Generate(id primitive.ObjectID) (err error) {
+	// spider fs service
+	fsSvc, err := svc.syncSvc.GetFsService(id)
+	if err != nil {
+		return err
+	}
+
+	// config data
+	data, err := fsSvc.GetFile(constants.Spiderfile)
+	if err != nil {
+		return err
+	}
+	configData, err := svc.parse(data)
+	if err != nil {
+		return err
+	}
+
+	return svc.generate(fsSvc, configData)
+}
+
+func (svc *Service) Regenerate(id primitive.ObjectID) (err error) {
+	// skip spiders other than configurable ones
+	s, err := svc.modelSvc.GetSpiderById(id)
+	if err != nil {
+		return err
+	}
+	if s.Type != constants.Configurable {
+		return nil
+	}
+
+	// skip if Spiderfile not exists
+	fsSvc, err := svc.syncSvc.GetFsService(id)
+	if err != nil {
+		return err
+	}
+	if _, err := fsSvc.GetFileInfo(constants.Spiderfile); err != nil {
+		return nil
+	}
+
+	return svc.Generate(id)
+}
+
+// parse and validate the content of a Spiderfile
+func (svc *Service) parse(data []byte) (configData entity.ConfigSpiderData, err error) {
+	configData, err = config_spider.ParseSpiderfile(data)
+	if err != nil {
+		return configData, err
+	}
+	if err := config_spider.ValidateSpiderfile(configData); err != nil {
+		return configData, err
+	}
+	return configData, nil
+}
+
+// generate scrapy project files of the validated config data into the spider fs
+func (svc *Service) generate(fsSvc interfaces.SpiderFsService, configData entity.ConfigSpiderData) (err error) {
+	generator := config_spider.ScrapyGenerator{
+		ConfigData: configData,
+	}
+	files, err := generator.Generate()
+	if err != nil {
+		return err
+	}
+
+	// save to fs and sync to workspace once for all files
+	for path, content := range files {
+		if err := fsSvc.GetFsService().Save(path, content, interfaces.WithNotSyncToWorkspace()); err != nil {
+			return err
+		}
+	}
+	return fsSvc.GetFsService().SyncToWorkspace()
+}
+
+func NewConfigSpiderService(opts ...Option) (svc2 interfaces.ConfigSpiderService, err error) {
+	// service
+	svc := &Service{
+		WithConfigPath: config.NewConfigPathService(),
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	// dependency injection
+	c := dig.New()
+	if err := c.Provide(service.GetService); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Provide(sync.ProvideSpiderSyncService(svc.GetConfigPath())); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Invoke(func(modelSvc service.ModelService, syncSvc interfaces.SpiderSyncService) {
+		svc.modelSvc = modelSvc
+		svc.syncSvc = syncSvc
+	}); err != nil {
+		return nil, trace.TraceError(err)
+	}
+
+	return svc, nil
+}
+
+func ProvideConfigSpiderService(path string, opts ...Option) func() (svc interfaces.ConfigSpiderService, err error) {
+	opts = append(opts, WithConfigPath(path))
+	return func() (svc interfaces.ConfigSpiderService, err error) {
+		return NewConfigSpiderService(opts...)
+	}
+}
@@ -1,16 +1,23 @@
 package fs
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"github.com/luke513009828/crawlab-core/errors"
 	"github.com/luke513009828/crawlab-core/fs"
 	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/crawlab-team/goseaweedfs"
 	"github.com/crawlab-team/go-trace"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io/ioutil"
 	"os"
 	"path"
 	"sync"
+	"time"
 )
 
 // Service implementation of interfaces.SpiderFsService
@@ -26,7 +33,8 @@ type Service struct {
 	fsSvc interfaces.FsService
 
 	// internals
-	id primitive.ObjectID
+	id              primitive.ObjectID
+	cleanFilesState string // state of files in fs when they were last found committed
 }
 
 func (svc *Service) Init() (err error) {
@@ -80,8 +88,200 @@ func (svc *Service) Copy(path, newPath string) (err error) {
 	return svc.fsSvc.Copy(path, newPath)
 }
 
+// Commit sync files from fs to workspace and commit them as a new version
 func (svc *Service) Commit(msg string) (err error) {
-	panic("implement me")
+	state, err := svc.getFilesState()
+	if err != nil {
+		return err
+	}
+	if err := svc.fsSvc.SyncToWorkspace(); err != nil {
+		return err
+	}
+	if err := svc.fsSvc.Commit(msg); err != nil {
+		return err
+	}
+	svc.cleanFilesState = state
+	return nil
+}
+
+// GetVersion commit hash of the latest version, empty if not committed yet
+func (svc *Service) GetVersion() (version string, err error) {
+	gitClient := svc.fsSvc.GetGitClient()
+	if gitClient == nil {
+		return "", nil
+	}
+	ref, err := gitClient.GetRepository().Head()
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			return "", nil
+		}
+		return "", trace.TraceError(err)
+	}
+	return ref.Hash().String(), nil
+}
+
+// IsDirty whether files in fs have changes not committed to the latest version,
+// of which the sync to workspace is skipped if files are unchanged since they
+// were last found committed
+func (svc *Service) IsDirty() (res bool, err error) {
+	gitClient := svc.fsSvc.GetGitClient()
+	if gitClient == nil {
+		return false, nil
+	}
+	state, err := svc.getFilesState()
+	if err != nil {
+		return false, err
+	}
+	if state == svc.cleanFilesState {
+		return false, nil
+	}
+	if err := svc.fsSvc.SyncToWorkspace(); err != nil {
+		return false, err
+	}
+	statusList, err := gitClient.GetStatus()
+	if err != nil {
+		return false, err
+	}
+	if len(statusList) > 0 {
+		return true, nil
+	}
+	svc.cleanFilesState = state
+	return false, nil
+}
+
+// GetVersionCommit commit of the version, which is a full commit hash
+func (svc *Service) GetVersionCommit(version string) (commit *object.Commit, err error) {
+	if !plumbing.IsHash(version) {
+		return nil, errors.ErrorSpiderInvalidVersion
+	}
+	gitClient := svc.fsSvc.GetGitClient()
+	if gitClient == nil {
+		return nil, errors.ErrorSpiderVersionNotFound
+	}
+	commit, err = gitClient.GetRepository().CommitObject(plumbing.NewHash(version))
+	if err != nil {
+		if err == plumbing.ErrObjectNotFound {
+			return nil, errors.ErrorSpiderVersionNotFound
+		}
+		return nil, trace.TraceError(err)
+	}
+	return commit, nil
+}
+
+// SyncVersionToFs save files of the version from repo to fs, from which they
+// are synced to run tasks pinned to the version
+func (svc *Service) SyncVersionToFs(version string) (err error) {
+	commit, err := svc.GetVersionCommit(version)
+	if err != nil {
+		return err
+	}
+	version = commit.Hash.String()
+
+	// files of the version in a directory aside from workspace
+	dirPath := svc.GetVersionWorkspacePath(version)
+	if err := os.RemoveAll(dirPath); err != nil {
+		return trace.TraceError(err)
+	}
+	if err := writeCommitFiles(commit, dirPath); err != nil {
+		return err
+	}
+
+	return svc.fsSvc.GetFs().SyncLocalToRemote(dirPath, svc.GetVersionFsPath(version))
+}
+
+// SyncVersionToWorkspace sync files of the version from fs to the directory
+// returned by GetVersionWorkspacePath
+func (svc *Service) SyncVersionToWorkspace(version string) (err error) {
+	if !plumbing.IsHash(version) {
+		return errors.ErrorSpiderInvalidVersion
+	}
+	return svc.fsSvc.GetFs().SyncRemoteToLocal(svc.GetVersionFsPath(version), svc.GetVersionWorkspacePath(version))
+}
+
+// PruneVersions remove files of versions other than those to keep from fs
+// and workspace, except recently synced ones
+func (svc *Service) PruneVersions(keep []string) (err error) {
+	keepMap := map[string]bool{}
+	for _, version := range keep {
+		keepMap[version] = true
+	}
+
+	// fs
+	dirPath := fmt.Sprintf("%s/%s/%s", svc.fsPathBase, versionsDirName, svc.id.Hex())
+	items, err := svc.fsSvc.GetFs().ListDir(dirPath, false)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if !item.IsDir || keepMap[item.Name] || time.Since(item.Mtime) < versionsPruneMinAge {
+			continue
+		}
+		if err := svc.fsSvc.GetFs().DeleteDir(item.FullPath); err != nil {
+			return err
+		}
+	}
+
+	// workspace
+	dirPath = fmt.Sprintf("%s/%s/%s", svc.workspacePathBase, versionsDirName, svc.id.Hex())
+	entries, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return trace.TraceError(err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || keepMap[entry.Name()] || time.Since(entry.ModTime()) < versionsPruneMinAge {
+			continue
+		}
+		if err := os.RemoveAll(path.Join(dirPath, entry.Name())); err != nil {
+			return trace.TraceError(err)
+		}
+	}
+
+	return nil
+}
+
+// Rollback restore files of the version and commit them as a new version,
+// which are then synced to fs so that following tasks run them
+func (svc *Service) Rollback(version, msg string) (err error) {
+	commit, err := svc.GetVersionCommit(version)
+	if err != nil {
+		return err
+	}
+
+	// sync from fs to workspace
+	if err := svc.fsSvc.SyncToWorkspace(); err != nil {
+		return err
+	}
+
+	// remove files of the latest version absent in the version
+	latest, err := svc.GetVersion()
+	if err != nil {
+		return err
+	}
+	if latest != "" {
+		latestCommit, err := svc.GetVersionCommit(latest)
+		if err != nil {
+			return err
+		}
+		if err := removeCommitFiles(latestCommit, commit, svc.GetWorkspacePath()); err != nil {
+			return err
+		}
+	}
+
+	// write files of the version
+	if err := writeCommitFiles(commit, svc.GetWorkspacePath()); err != nil {
+		return err
+	}
+
+	// commit as a new version
+	if err := svc.fsSvc.Commit(msg); err != nil {
+		return err
+	}
+
+	// sync from workspace to fs
+	return svc.fsSvc.SyncToFs(interfaces.WithOnlyFromWorkspace())
 }
 
 func (svc *Service) GetFsPath() (res string) {
@@ -96,6 +296,14 @@ func (svc *Service) GetRepoPath() (res string) {
 	return fmt.Sprintf("%s/%s", svc.repoPathBase, svc.id.Hex())
 }
 
+func (svc *Service) GetVersionFsPath(version string) (res string) {
+	return fmt.Sprintf("%s/%s/%s/%s", svc.fsPathBase, versionsDirName, svc.id.Hex(), version)
+}
+
+func (svc *Service) GetVersionWorkspacePath(version string) (res string) {
+	return fmt.Sprintf("%s/%s/%s/%s", svc.workspacePathBase, versionsDirName, svc.id.Hex(), version)
+}
+
 func (svc *Service) SetFsPathBase(path string) {
 	svc.fsPathBase = path
 }
@@ -112,6 +320,25 @@ func (svc *Service) GetFsService() (fsSvc interfaces.FsService) {
 	return svc.fsSvc
 }
 
+// getFilesState digest of paths, sizes, modified times and md5 of files in fs,
+// which changes as any of the files changes
+func (svc *Service) getFilesState() (state string, err error) {
+	items, err := svc.fsSvc.GetFs().ListDir(svc.GetFsPath(), true)
+	if err != nil {
+		return "", err
+	}
+	h := sha1.New()
+	var write func(items []goseaweedfs.FilerFileInfo)
+	write = func(items []goseaweedfs.FilerFileInfo) {
+		for _, item := range items {
+			_, _ = fmt.Fprintf(h, "%s\t%t\t%d\t%d\t%s\n", item.FullPath, item.IsDir, item.FileSize, item.Mtime.UnixNano(), item.Md5)
+			write(item.Children)
+		}
+	}
+	write(items)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (svc *Service) getFsPath(p string) (res string) {
 	return path.Join(svc.GetFsPath(), p)
 }
@@ -2,10 +2,24 @@ package fs
 
 import (
 	"crypto/md5"
+	"github.com/crawlab-team/go-trace"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"io"
+	"os"
+	"path/filepath"
+	"time"
 )
 
+// versionsDirName directory under fs and workspace bases where files of
+// versions of spiders are kept
+const versionsDirName = ".versions"
+
+// versionsPruneMinAge age under which files of versions are not pruned, as
+// tasks to run them may still be being created
+const versionsPruneMinAge = time.Hour
+
 func getConfigPathFromOptions(opts ...Option) (path string) {
 	svc := &Service{}
 	for _, opt := range opts {
@@ -20,3 +34,55 @@ func getHashStringFromIdAndConfigPath(id primitive.ObjectID, cfgPath string) (st
 	_, _ = io.WriteString(h, cfgPath)
 	return string(h.Sum(nil))
 }
+
+// writeCommitFiles write files of the commit to the directory
+func writeCommitFiles(commit *object.Commit, dirPath string) (err error) {
+	iter, err := commit.Files()
+	if err != nil {
+		return trace.TraceError(err)
+	}
+	return iter.ForEach(func(f *object.File) (err error) {
+		filePath := filepath.Join(dirPath, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			return trace.TraceError(err)
+		}
+		perm := os.FileMode(0644)
+		if f.Mode == filemode.Executable {
+			perm = 0755
+		}
+		r, err := f.Reader()
+		if err != nil {
+			return trace.TraceError(err)
+		}
+		defer r.Close()
+		w, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+		if err != nil {
+			return trace.TraceError(err)
+		}
+		defer w.Close()
+		if _, err := io.Copy(w, r); err != nil {
+			return trace.TraceError(err)
+		}
+		return nil
+	})
+}
+
+// removeCommitFiles remove files of the commit absent in the other commit
+// from the directory
+func removeCommitFiles(commit, other *object.Commit, dirPath string) (err error) {
+	iter, err := commit.Files()
+	if err != nil {
+		return trace.TraceError(err)
+	}
+	return iter.ForEach(func(f *object.File) (err error) {
+		if _, err := other.File(f.Name); err == nil {
+			return nil
+		} else if err != object.ErrFileNotFound {
+			return trace.TraceError(err)
+		}
+		if err := os.Remove(filepath.Join(dirPath, filepath.FromSlash(f.Name))); err != nil && !os.IsNotExist(err) {
+			return trace.TraceError(err)
+		}
+		return nil
+	})
+}
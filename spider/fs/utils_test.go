@@ -0,0 +1,65 @@
+package fs
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteAndRemoveCommitFiles(t *testing.T) {
+	repoPath, err := ioutil.TempDir("", "crawlab-spider-repo-")
+	require.Nil(t, err)
+	defer os.RemoveAll(repoPath)
+	r, err := git.PlainInit(repoPath, false)
+	require.Nil(t, err)
+	wt, err := r.Worktree()
+	require.Nil(t, err)
+
+	commit := func(files map[string]string, msg string) *object.Commit {
+		for name, content := range files {
+			filePath := filepath.Join(repoPath, name)
+			require.Nil(t, os.MkdirAll(filepath.Dir(filePath), os.ModePerm))
+			require.Nil(t, ioutil.WriteFile(filePath, []byte(content), 0644))
+			_, err := wt.Add(name)
+			require.Nil(t, err)
+		}
+		h, err := wt.Commit(msg, &git.CommitOptions{
+			All:    true,
+			Author: &object.Signature{Name: "test", Email: "test@crawlab.cn", When: time.Now()},
+		})
+		require.Nil(t, err)
+		c, err := r.CommitObject(h)
+		require.Nil(t, err)
+		return c
+	}
+
+	// versions
+	v1 := commit(map[string]string{"main.py": "v1", "lib/util.py": "util"}, "v1")
+	require.Nil(t, os.Remove(filepath.Join(repoPath, "lib/util.py")))
+	v2 := commit(map[string]string{"main.py": "v2", "extra.py": "extra"}, "v2")
+
+	// files of v2
+	dirPath, err := ioutil.TempDir("", "crawlab-spider-version-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dirPath)
+	require.Nil(t, writeCommitFiles(v2, dirPath))
+	data, err := ioutil.ReadFile(filepath.Join(dirPath, "main.py"))
+	require.Nil(t, err)
+	require.Equal(t, "v2", string(data))
+	require.FileExists(t, filepath.Join(dirPath, "extra.py"))
+	require.NoFileExists(t, filepath.Join(dirPath, "lib/util.py"))
+
+	// rollback to v1
+	require.Nil(t, removeCommitFiles(v2, v1, dirPath))
+	require.Nil(t, writeCommitFiles(v1, dirPath))
+	data, err = ioutil.ReadFile(filepath.Join(dirPath, "main.py"))
+	require.Nil(t, err)
+	require.Equal(t, "v1", string(data))
+	require.FileExists(t, filepath.Join(dirPath, "lib/util.py"))
+	require.NoFileExists(t, filepath.Join(dirPath, "extra.py"))
+}
@@ -0,0 +1,13 @@
+package gitsync
+
+import (
+	"github.com/luke513009828/crawlab-core/interfaces"
+)
+
+type Option func(svc interfaces.SpiderGitSyncService)
+
+func WithConfigPath(path string) Option {
+	return func(svc interfaces.SpiderGitSyncService) {
+		svc.SetConfigPath(path)
+	}
+}
@@ -0,0 +1,886 @@
+package gitsync
+
+import (
+	"github.com/apex/log"
+	"github.com/luke513009828/crawlab-core/config"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/luke513009828/crawlab-core/schedule"
+	"github.com/luke513009828/crawlab-core/spider/admin"
+	"github.com/luke513009828/crawlab-core/spider/configspider"
+	sync2 "github.com/luke513009828/crawlab-core/spider/sync"
+	"github.com/luke513009828/crawlab-core/utils"
+	"github.com/crawlab-team/crawlab-db/mongo"
+	vcs "github.com/crawlab-team/crawlab-vcs"
+	"github.com/crawlab-team/go-trace"
+	"github.com/go-git/go-git/v5"
+	gitConfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/robfig/cron/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/dig"
+	"golang.org/x/crypto/ssh"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Service struct {
+	// dependencies
+	interfaces.WithConfigPath
+	modelSvc        service.ModelService
+	syncSvc         interfaces.SpiderSyncService
+	adminSvc        interfaces.SpiderAdminService
+	configSpiderSvc interfaces.ConfigSpiderService
+
+	// settings variables
+	updateInterval time.Duration
+
+	// internals
+	cron    *cron.Cron
+	entries map[primitive.ObjectID]entry // cron entries of auto-sync spiders
+	stopped bool
+}
+
+// entry cron entry of an auto-sync spider, of which id is zero if the sync
+// frequency is invalid
+type entry struct {
+	id   cron.EntryID
+	spec string
+}
+
+func (svc *Service) Init() (err error) {
+	return nil
+}
+
+func (svc *Service) Start() {
+	svc.cron.Start()
+	go svc.Update()
+}
+
+func (svc *Service) Wait() {
+	utils.DefaultWait()
+	svc.Stop()
+}
+
+func (svc *Service) Stop() {
+	svc.stopped = true
+	svc.cron.Stop()
+}
+
+func (svc *Service) Update() {
+	for {
+		if svc.stopped {
+			return
+		}
+
+		if err := svc.update(); err != nil {
+			trace.PrintError(err)
+		}
+
+		time.Sleep(svc.updateInterval)
+	}
+}
+
+func (svc *Service) GetGitClient(id primitive.ObjectID, fsSvc interfaces.SpiderFsService) (gitClient *vcs.GitClient, err error) {
+	// auth type
+	authType := ""
+
+	// git
+	g, err := svc.modelSvc.GetGitById(id)
+	if err != nil {
+		if err != mongo2.ErrNoDocuments {
+			return nil, trace.TraceError(err)
+		}
+	} else {
+		authType = g.AuthType
+	}
+
+	// git client
+	gitClient = fsSvc.GetFsService().GetGitClient()
+
+	// ssh auth is not set to the git client as its host keys are not verified,
+	// which should be passed to remote operations with GetAuth instead
+	switch authType {
+	case constants.GitAuthTypeHttp:
+		gitClient.SetAuthType(vcs.GitAuthTypeHTTP)
+		gitClient.SetUsername(g.Username)
+		gitClient.SetPassword(g.Password)
+	default:
+		return gitClient, nil
+	}
+
+	return gitClient, nil
+}
+
+func (svc *Service) GetAuth(id primitive.ObjectID) (auth transport.AuthMethod, err error) {
+	g, err := svc.modelSvc.GetGitById(id)
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return svc.getAuth(g)
+}
+
+func (svc *Service) GetRemoteRefs(id primitive.ObjectID) (gitRefs []vcs.GitRef, err error) {
+	// spider fs service
+	fsSvc, err := svc.syncSvc.GetFsService(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// git client
+	gitClient, err := svc.GetGitClient(id, fsSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	// upstream remote
+	r, err := gitClient.GetRemote(constants.GitRemoteNameUpstream)
+	if err != nil {
+		if err == git.ErrRemoteNotFound {
+			return nil, nil
+		}
+		return nil, trace.TraceError(err)
+	}
+
+	// auth
+	auth, err := svc.GetAuth(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// refs
+	refs, err := r.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return nil, trace.TraceError(err)
+	}
+	for _, ref := range refs {
+		var refType string
+		if ref.Name().IsBranch() {
+			refType = vcs.GitRefTypeBranch
+		} else if ref.Name().IsTag() {
+			refType = vcs.GitRefTypeTag
+		} else {
+			continue
+		}
+		gitRefs = append(gitRefs, vcs.GitRef{
+			Type:     refType,
+			Name:     ref.Name().Short(),
+			FullName: ref.Name().String(),
+			Hash:     ref.Hash().String(),
+		})
+	}
+
+	// timestamps of refs of which the commits exist in the spider repo
+	logs, err := gitClient.GetLogs()
+	if err != nil {
+		return nil, err
+	}
+	logsMap := map[string]vcs.GitLog{}
+	for _, l := range logs {
+		logsMap[l.Hash] = l
+	}
+	for i, gitRef := range gitRefs {
+		l, ok := logsMap[gitRef.Hash]
+		if !ok {
+			continue
+		}
+		gitRefs[i].Timestamp = l.Timestamp
+	}
+
+	// latest first
+	sort.Slice(gitRefs, func(i, j int) bool {
+		return gitRefs[i].Timestamp.Unix() > gitRefs[j].Timestamp.Unix()
+	})
+
+	return gitRefs, nil
+}
+
+func (svc *Service) GenerateSshKey(id primitive.ObjectID, args ...interface{}) (err error) {
+	privateKey, err := newSshKey()
+	if err != nil {
+		return err
+	}
+	return svc.saveSshKey(id, privateKey, true, args...)
+}
+
+func (svc *Service) SaveSshKey(id primitive.ObjectID, privateKey string, args ...interface{}) (err error) {
+	return svc.saveSshKey(id, privateKey, false, args...)
+}
+
+func (svc *Service) Sync(id primitive.ObjectID, opts *interfaces.SpiderGitSyncOptions, args ...interface{}) (logId primitive.ObjectID, err error) {
+	u := utils.GetUserFromArgs(args...)
+	if opts == nil {
+		opts = &interfaces.SpiderGitSyncOptions{}
+	}
+
+	// spider
+	s, err := svc.modelSvc.GetSpiderById(id)
+	if err != nil {
+		return logId, err
+	}
+
+	// sync log
+	l := &models.GitSyncLog{
+		SpiderId: id,
+		Trigger:  opts.Trigger,
+		Status:   constants.GitSyncStatusSuccess,
+		StartTs:  time.Now(),
+	}
+	if l.Trigger == "" {
+		l.Trigger = constants.GitSyncTriggerManual
+	}
+	if u != nil {
+		l.UserId = u.GetId()
+	}
+
+	// pull, one sync of the spider at a time, of which the lock is released
+	// before running on new commits as runs lock the spider files as well
+	mu := svc.syncSvc.GetLock(id)
+	mu.Lock()
+	syncErr := svc.pull(s, opts, l)
+	mu.Unlock()
+	if syncErr != nil {
+		l.Status = constants.GitSyncStatusError
+		l.Error = syncErr.Error()
+		log.Warnf("git sync of spider[%s] failed: %v", id.Hex(), syncErr)
+	}
+
+	// run on new commits
+	if syncErr == nil && isRunOnSync(s, l) {
+		l.TaskIds, err = svc.adminSvc.ScheduleWithTaskId(id, svc.getRunOptions(s))
+		if err != nil {
+			trace.PrintError(err)
+		}
+	}
+
+	// record
+	l.EndTs = time.Now()
+	if err := delegate.NewModelDelegate(l, u).Add(); err != nil {
+		return logId, err
+	}
+	if err := mongo.GetMongoCol(interfaces.ModelColNameSpider).UpdateId(id, bson.M{
+		"$set": bson.M{
+			"git_sync_error": l.Error,
+		},
+	}); err != nil {
+		return l.Id, err
+	}
+
+	return l.Id, syncErr
+}
+
+func (svc *Service) Fetch(id primitive.ObjectID) (err error) {
+	// git
+	g, err := svc.modelSvc.GetGitById(id)
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			return errors.ErrorSpiderGitNotFound
+		}
+		return err
+	}
+
+	// spider fs service
+	fsSvc, err := svc.syncSvc.GetFsService(id)
+	if err != nil {
+		return err
+	}
+
+	// git client
+	gitClient, err := svc.GetGitClient(id, fsSvc)
+	if err != nil {
+		return err
+	}
+
+	// upstream remote
+	if err := svc.setRemote(gitClient, g); err != nil {
+		return err
+	}
+
+	// auth
+	auth, err := svc.getAuth(g)
+	if err != nil {
+		return err
+	}
+
+	// fetch
+	if err := gitClient.GetRepository().Fetch(&git.FetchOptions{
+		RemoteName: constants.GitRemoteNameUpstream,
+		Auth:       auth,
+		Tags:       git.AllTags,
+		Force:      true,
+	}); err != nil {
+		if err == git.NoErrAlreadyUpToDate || err == transport.ErrEmptyRemoteRepository {
+			return nil
+		}
+		return trace.TraceError(err)
+	}
+
+	return nil
+}
+
+func (svc *Service) Checkout(id primitive.ObjectID, opts *interfaces.SpiderGitRefOptions, args ...interface{}) (err error) {
+	u := utils.GetUserFromArgs(args...)
+
+	// one sync or checkout of the spider at a time
+	mu := svc.syncSvc.GetLock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// spider
+	s, err := svc.modelSvc.GetSpiderById(id)
+	if err != nil {
+		return err
+	}
+
+	// detached head of tags or commits cannot be pulled by auto sync
+	if opts.Branch == "" && s.GitAutoSync {
+		return errors.ErrorSpiderGitDetachedAutoSync
+	}
+
+	// spider fs service
+	fsSvc, err := svc.syncSvc.GetFsService(id)
+	if err != nil {
+		return err
+	}
+
+	// target commit
+	gitClient := fsSvc.GetFsService().GetGitClient()
+	h, err := ResolveRevision(gitClient.GetRepository(), opts)
+	if err != nil {
+		return err
+	}
+
+	// checkout, of which uncommitted changes are discarded
+	force := func(o *git.CheckoutOptions) {
+		o.Force = true
+	}
+	if opts.Branch != "" {
+		// branch, created from the remote-tracking branch if not exists
+		if _, err := gitClient.GetRepository().Reference(plumbing.NewBranchReferenceName(opts.Branch), false); err == nil {
+			err = gitClient.Checkout(vcs.WithBranch(opts.Branch), force)
+		} else {
+			ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(opts.Branch), h)
+			err = gitClient.CheckoutBranchWithRemoteFromRef(opts.Branch, constants.GitRemoteNameUpstream, ref, force)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		// tag or commit with detached head
+		if err := gitClient.CheckoutHash(h.String(), force); err != nil {
+			return err
+		}
+	}
+
+	// sync to fs
+	if err := fsSvc.GetFsService().SyncToFs(interfaces.WithOnlyFromWorkspace()); err != nil {
+		return err
+	}
+
+	// regenerate scrapy project files of configurable spider from Spiderfile
+	if err := svc.configSpiderSvc.Regenerate(id); err != nil {
+		return err
+	}
+
+	// branch to pull
+	if opts.Branch != "" {
+		if err := svc.modelSvc.GetBaseService(interfaces.ModelIdSpider).UpdateById(id, bson.M{"git_branch": opts.Branch}, u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (svc *Service) Reset(id primitive.ObjectID, opts *interfaces.SpiderGitRefOptions) (err error) {
+	// one sync or reset of the spider at a time
+	mu := svc.syncSvc.GetLock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// spider fs service
+	fsSvc, err := svc.syncSvc.GetFsService(id)
+	if err != nil {
+		return err
+	}
+
+	// target commit
+	gitClient := fsSvc.GetFsService().GetGitClient()
+	h, err := ResolveRevision(gitClient.GetRepository(), opts)
+	if err != nil {
+		return err
+	}
+
+	// hard reset of the current branch and workspace
+	if err := gitClient.Reset(vcs.WithCommit(h)); err != nil {
+		return err
+	}
+
+	// sync to fs
+	if err := fsSvc.GetFsService().SyncToFs(interfaces.WithOnlyFromWorkspace()); err != nil {
+		return err
+	}
+
+	// regenerate scrapy project files of configurable spider from Spiderfile
+	return svc.configSpiderSvc.Regenerate(id)
+}
+
+// pull the branch of the upstream remote into the spider repo and fs, of
+// which the commits before and after are recorded in the sync log
+func (svc *Service) pull(s *models.Spider, opts *interfaces.SpiderGitSyncOptions, l *models.GitSyncLog) (err error) {
+	// git
+	g, err := svc.modelSvc.GetGitById(s.Id)
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			return errors.ErrorSpiderGitNotFound
+		}
+		return err
+	}
+
+	// spider fs service
+	fsSvc, err := svc.syncSvc.GetFsService(s.Id)
+	if err != nil {
+		return err
+	}
+
+	// git client
+	gitClient, err := svc.GetGitClient(s.Id, fsSvc)
+	if err != nil {
+		return err
+	}
+
+	// upstream remote
+	if err := svc.setRemote(gitClient, g); err != nil {
+		return err
+	}
+
+	// branch to pull
+	l.Branch = opts.Branch
+	if l.Branch == "" {
+		l.Branch = s.GitBranch
+	}
+	if l.Branch == "" {
+		// by default current branch
+		l.Branch, err = gitClient.GetCurrentBranch()
+		if err != nil {
+			return err
+		}
+	}
+
+	// commit before pull
+	l.PrevCommit, err = fsSvc.GetVersion()
+	if err != nil {
+		return err
+	}
+
+	// reset
+	_ = gitClient.Reset()
+
+	// auth
+	auth, err := svc.getAuth(g)
+	if err != nil {
+		return err
+	}
+
+	// pull
+	if err := gitClient.Pull(
+		vcs.WithRemoteNamePull(constants.GitRemoteNameUpstream),
+		vcs.WithBranchNamePull(l.Branch),
+		vcs.WithAuthPull(auth),
+	); err != nil {
+		return trace.TraceError(err)
+	}
+
+	// reset
+	_ = gitClient.Reset()
+
+	// commit after pull
+	l.Commit, err = fsSvc.GetVersion()
+	if err != nil {
+		return err
+	}
+
+	// sync to fs
+	if err := fsSvc.GetFsService().SyncToFs(interfaces.WithOnlyFromWorkspace()); err != nil {
+		return err
+	}
+
+	// regenerate scrapy project files of configurable spider from Spiderfile
+	return svc.configSpiderSvc.Regenerate(s.Id)
+}
+
+// setRemote create upstream remote with the url of git settings if not exists
+// or urls not matched
+func (svc *Service) setRemote(gitClient *vcs.GitClient, g *models.Git) (err error) {
+	r, err := gitClient.GetRemote(constants.GitRemoteNameUpstream)
+	if err != nil {
+		if err != git.ErrRemoteNotFound {
+			return err
+		}
+	} else {
+		if len(r.Config().URLs) > 0 && r.Config().URLs[0] == g.Url {
+			return nil
+		}
+
+		// delete existing upstream remote
+		if err := gitClient.DeleteRemote(constants.GitRemoteNameUpstream); err != nil {
+			return err
+		}
+	}
+
+	// create upstream remote
+	if _, err := gitClient.CreateRemote(&gitConfig.RemoteConfig{
+		Name: constants.GitRemoteNameUpstream,
+		URLs: []string{g.Url},
+	}); err != nil {
+		return trace.TraceError(err)
+	}
+
+	return nil
+}
+
+// getAuth auth of the git remote with git settings, nil if no credentials
+func (svc *Service) getAuth(g *models.Git) (auth transport.AuthMethod, err error) {
+	switch g.AuthType {
+	case constants.GitAuthTypeHttp:
+		if g.Username == "" && g.Password == "" {
+			return nil, nil
+		}
+		return &http.BasicAuth{
+			Username: g.Username,
+			Password: g.Password,
+		}, nil
+	case constants.GitAuthTypeSsh:
+		privateKey, err := svc.getPrivateKey(g)
+		if err != nil {
+			return nil, err
+		}
+		if privateKey == "" {
+			return nil, nil
+		}
+		signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+		if err != nil {
+			return nil, errors.ErrorGitInvalidSshKey
+		}
+		callback, err := getHostKeyCallback(g, func(knownHosts string) error {
+			g.KnownHosts = knownHosts
+			return svc.modelSvc.GetBaseService(interfaces.ModelIdGit).UpdateById(g.Id, bson.M{"known_hosts": knownHosts})
+		})
+		if err != nil {
+			return nil, err
+		}
+		user := g.Username
+		if user == "" {
+			user = gitssh.DefaultUsername
+		}
+		return &gitssh.PublicKeys{
+			User:   user,
+			Signer: signer,
+			HostKeyCallbackHelper: gitssh.HostKeyCallbackHelper{
+				HostKeyCallback: callback,
+			},
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// getPrivateKey decrypted private key of the ssh key of the git repo, or the
+// password if no ssh key, which is where private keys were saved previously
+func (svc *Service) getPrivateKey(g *models.Git) (privateKey string, err error) {
+	k, err := svc.modelSvc.GetGitSshKeyById(g.Id)
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			return g.Password, nil
+		}
+		return "", err
+	}
+	secretKey, err := getSecretKey()
+	if err != nil {
+		return "", err
+	}
+	privateKey, err = utils.DecryptAes(k.PrivateKey, secretKey)
+	if err != nil {
+		return "", trace.TraceError(err)
+	}
+	return privateKey, nil
+}
+
+// saveSshKey add or replace the ssh key of the git repo, of which the private
+// key is encrypted
+func (svc *Service) saveSshKey(id primitive.ObjectID, privateKey string, isGenerated bool, args ...interface{}) (err error) {
+	u := utils.GetUserFromArgs(args...)
+
+	// public key
+	publicKey, fingerprint, err := parseSshKey(privateKey)
+	if err != nil {
+		return err
+	}
+
+	// encrypt private key
+	secretKey, err := getSecretKey()
+	if err != nil {
+		return err
+	}
+	encrypted, err := utils.EncryptAes(privateKey, secretKey)
+	if err != nil {
+		return trace.TraceError(err)
+	}
+
+	// ssh key
+	k := &models.GitSshKey{
+		Id:          id,
+		PrivateKey:  encrypted,
+		PublicKey:   publicKey,
+		Fingerprint: fingerprint,
+		IsGenerated: isGenerated,
+		Ts:          time.Now(),
+	}
+
+	// replace if exists
+	if _, err := svc.modelSvc.GetGitSshKeyById(id); err != nil {
+		if err != mongo2.ErrNoDocuments {
+			return err
+		}
+		return delegate.NewModelDelegate(k, u).Add()
+	}
+	return delegate.NewModelDelegate(k, u).Save()
+}
+
+// isRunOnSync whether to run the spider after the sync, i.e. new commits are
+// pulled by auto sync of the spider with auto run enabled
+func isRunOnSync(s *models.Spider, l *models.GitSyncLog) (ok bool) {
+	return s.GitAutoRun && l.Trigger == constants.GitSyncTriggerAuto && l.Status == constants.GitSyncStatusSuccess && l.Commit != l.PrevCommit
+}
+
+// getRunOptions options of the spider run on new commits, which are default
+// ones of the spider run by its creator
+func (svc *Service) getRunOptions(s *models.Spider) (opts *interfaces.SpiderRunOptions) {
+	opts = &interfaces.SpiderRunOptions{
+		Mode:          s.Mode,
+		NodeIds:       s.NodeIds,
+		NodeTags:      s.NodeTags,
+		NodeTagsMatch: s.NodeTagsMatch,
+		Cmd:           s.Cmd,
+		Param:         s.Param,
+		Priority:      s.Priority,
+	}
+	if opts.Mode == "" {
+		opts.Mode = constants.RunTypeRandom
+	}
+	if opts.Priority == 0 {
+		opts.Priority = 5
+	}
+	if a, err := svc.modelSvc.GetArtifactById(s.Id); err == nil && a.Sys != nil {
+		opts.UserId = a.Sys.CreateUid
+	}
+	return opts
+}
+
+// update add, replace or remove cron entries according to the sync frequency
+// of auto-sync spiders
+func (svc *Service) update() (err error) {
+	// auto-sync spiders
+	spiders, err := svc.modelSvc.GetSpiderList(bson.M{"git_auto_sync": true}, nil)
+	if err != nil && err != mongo2.ErrNoDocuments {
+		return err
+	}
+
+	// update entries and record invalid sync frequencies
+	for id, err := range svc.updateEntries(spiders) {
+		log.Warnf("invalid git sync frequency of spider[%s]: %v", id.Hex(), err)
+		if err := mongo.GetMongoCol(interfaces.ModelColNameSpider).UpdateId(id, bson.M{
+			"$set": bson.M{
+				"git_sync_error": err.Error(),
+			},
+		}); err != nil {
+			trace.PrintError(err)
+		}
+	}
+
+	return nil
+}
+
+// updateEntries add, replace or remove cron entries of the auto-sync spiders,
+// and return errors of those with invalid sync frequencies
+func (svc *Service) updateEntries(spiders []models.Spider) (errs map[primitive.ObjectID]error) {
+	errs = map[primitive.ObjectID]error{}
+
+	// iterate auto-sync spiders
+	ids := map[primitive.ObjectID]bool{}
+	for _, s := range spiders {
+		ids[s.Id] = true
+
+		// skip if sync frequency not changed
+		e, ok := svc.entries[s.Id]
+		if ok && e.spec == s.GitSyncFrequency {
+			continue
+		}
+
+		// replace entry
+		if ok && e.id != 0 {
+			svc.cron.Remove(e.id)
+		}
+		e = entry{spec: s.GitSyncFrequency}
+		id, err := svc.cron.AddFunc(s.GitSyncFrequency, svc.sync(s.Id))
+		if err != nil {
+			errs[s.Id] = err
+		} else {
+			e.id = id
+		}
+		svc.entries[s.Id] = e
+	}
+
+	// remove entries of spiders no longer auto-synced
+	for id, e := range svc.entries {
+		if ids[id] {
+			continue
+		}
+		if e.id != 0 {
+			svc.cron.Remove(e.id)
+		}
+		delete(svc.entries, id)
+	}
+
+	return errs
+}
+
+func (svc *Service) sync(id primitive.ObjectID) (fn func()) {
+	return func() {
+		if _, err := svc.Sync(id, &interfaces.SpiderGitSyncOptions{
+			Trigger: constants.GitSyncTriggerAuto,
+		}); err != nil {
+			trace.PrintError(err)
+		}
+	}
+}
+
+func NewSpiderGitSyncService(opts ...Option) (svc2 interfaces.SpiderGitSyncService, err error) {
+	// service
+	svc := &Service{
+		WithConfigPath: config.NewConfigPathService(),
+		updateInterval: 30 * time.Second,
+		entries:        map[primitive.ObjectID]entry{},
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	// dependency injection
+	c := dig.New()
+	if err := c.Provide(service.GetService); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Provide(sync2.ProvideSpiderSyncService(svc.GetConfigPath())); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Provide(admin.ProvideSpiderAdminService(svc.GetConfigPath())); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Provide(configspider.ProvideConfigSpiderService(svc.GetConfigPath())); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Invoke(func(
+		modelSvc service.ModelService,
+		syncSvc interfaces.SpiderSyncService,
+		adminSvc interfaces.SpiderAdminService,
+		configSpiderSvc interfaces.ConfigSpiderService,
+	) {
+		svc.modelSvc = modelSvc
+		svc.syncSvc = syncSvc
+		svc.adminSvc = adminSvc
+		svc.configSpiderSvc = configSpiderSvc
+	}); err != nil {
+		return nil, trace.TraceError(err)
+	}
+
+	// cron
+	logger := schedule.NewLogger()
+	svc.cron = cron.New(
+		cron.WithLogger(logger),
+		cron.WithChain(cron.SkipIfStillRunning(logger), cron.Recover(logger)),
+	)
+
+	// initialize
+	if err := svc.Init(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+func ProvideSpiderGitSyncService(path string, opts ...Option) func() (svc interfaces.SpiderGitSyncService, err error) {
+	opts = append(opts, WithConfigPath(path))
+	return func() (svc interfaces.SpiderGitSyncService, err error) {
+		return NewSpiderGitSyncService(opts...)
+	}
+}
+
+var store = sync.Map{}
+
+func GetSpiderGitSyncService(path string, opts ...Option) (svc interfaces.SpiderGitSyncService, err error) {
+	if path == "" {
+		path = config.DefaultConfigPath
+	}
+	opts = append(opts, WithConfigPath(path))
+	res, ok := store.Load(path)
+	if ok {
+		svc, ok = res.(interfaces.SpiderGitSyncService)
+		if ok {
+			return svc, nil
+		}
+	}
+	svc, err = NewSpiderGitSyncService(opts...)
+	if err != nil {
+		return nil, err
+	}
+	store.Store(path, svc)
+	return svc, nil
+}
+
+func ProvideGetSpiderGitSyncService(path string, opts ...Option) func() (svc interfaces.SpiderGitSyncService, err error) {
+	return func() (svc interfaces.SpiderGitSyncService, err error) {
+		return GetSpiderGitSyncService(path, opts...)
+	}
+}
+
+// ResolveRevision commit hash of the branch, tag or commit, of which a branch
+// not exists locally is looked up in remote-tracking ones
+func ResolveRevision(repo *git.Repository, opts *interfaces.SpiderGitRefOptions) (h plumbing.Hash, err error) {
+	var revs []string
+	switch {
+	case opts.Branch != "":
+		revs = []string{
+			plumbing.NewBranchReferenceName(opts.Branch).String(),
+			plumbing.NewRemoteReferenceName(constants.GitRemoteNameUpstream, opts.Branch).String(),
+		}
+	case opts.Tag != "":
+		revs = []string{plumbing.NewTagReferenceName(opts.Tag).String()}
+	case opts.Commit != "":
+		revs = []string{opts.Commit}
+	default:
+		return h, errors.ErrorSpiderMissingRequiredOption
+	}
+	for _, rev := range revs {
+		res, err := repo.ResolveRevision(plumbing.Revision(rev))
+		if err == nil {
+			return *res, nil
+		}
+	}
+	return h, errors.ErrorSpiderGitRefNotFound
+}
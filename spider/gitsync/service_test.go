@@ -0,0 +1,96 @@
+package gitsync
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestService_UpdateEntries(t *testing.T) {
+	svc := &Service{
+		cron:    cron.New(),
+		entries: map[primitive.ObjectID]entry{},
+	}
+	s1 := models.Spider{Id: primitive.NewObjectID(), GitSyncFrequency: "*/5 * * * *"}
+	s2 := models.Spider{Id: primitive.NewObjectID(), GitSyncFrequency: "0 * * * *"}
+
+	// add
+	errs := svc.updateEntries([]models.Spider{s1, s2})
+	require.Len(t, errs, 0)
+	require.Len(t, svc.entries, 2)
+	require.Len(t, svc.cron.Entries(), 2)
+	e1 := svc.entries[s1.Id]
+	require.NotZero(t, e1.id)
+
+	// unchanged
+	svc.updateEntries([]models.Spider{s1, s2})
+	require.Equal(t, e1, svc.entries[s1.Id])
+	require.Len(t, svc.cron.Entries(), 2)
+
+	// replace
+	s1.GitSyncFrequency = "*/10 * * * *"
+	svc.updateEntries([]models.Spider{s1, s2})
+	require.NotEqual(t, e1.id, svc.entries[s1.Id].id)
+	require.Equal(t, s1.GitSyncFrequency, svc.entries[s1.Id].spec)
+	require.Len(t, svc.cron.Entries(), 2)
+
+	// invalid frequency, of which the entry is kept without cron entry
+	s1.GitSyncFrequency = "invalid"
+	errs = svc.updateEntries([]models.Spider{s1, s2})
+	require.Len(t, errs, 1)
+	require.NotNil(t, errs[s1.Id])
+	require.Zero(t, svc.entries[s1.Id].id)
+	require.Len(t, svc.cron.Entries(), 1)
+
+	// not reported again until the frequency changes
+	errs = svc.updateEntries([]models.Spider{s1, s2})
+	require.Len(t, errs, 0)
+
+	// remove
+	svc.updateEntries([]models.Spider{s1})
+	require.Len(t, svc.entries, 1)
+	require.Len(t, svc.cron.Entries(), 0)
+	svc.updateEntries(nil)
+	require.Len(t, svc.entries, 0)
+}
+
+func TestIsRunOnSync(t *testing.T) {
+	s := &models.Spider{GitAutoRun: true}
+	newLog := func() *models.GitSyncLog {
+		return &models.GitSyncLog{
+			Trigger:    constants.GitSyncTriggerAuto,
+			Status:     constants.GitSyncStatusSuccess,
+			PrevCommit: "a",
+			Commit:     "b",
+		}
+	}
+
+	// new commits pulled by auto sync
+	require.True(t, isRunOnSync(s, newLog()))
+
+	// first pull
+	l := newLog()
+	l.PrevCommit = ""
+	require.True(t, isRunOnSync(s, l))
+
+	// no new commits
+	l = newLog()
+	l.Commit = l.PrevCommit
+	require.False(t, isRunOnSync(s, l))
+
+	// manual sync
+	l = newLog()
+	l.Trigger = constants.GitSyncTriggerManual
+	require.False(t, isRunOnSync(s, l))
+
+	// failed sync
+	l = newLog()
+	l.Status = constants.GitSyncStatusError
+	require.False(t, isRunOnSync(s, l))
+
+	// auto run disabled
+	require.False(t, isRunOnSync(&models.Spider{}, newLog()))
+}
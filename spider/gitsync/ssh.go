@@ -0,0 +1,88 @@
+package gitsync
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/utils"
+	"github.com/crawlab-team/go-trace"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+)
+
+// getSecretKey secret key to encrypt private keys of git repos, which must
+// be configured as a key known to all would not protect private keys
+func getSecretKey() (key string, err error) {
+	key = utils.GetSecretKey()
+	if key == "" {
+		return "", errors.ErrorGitNoSecretKey
+	}
+	return key, nil
+}
+
+// newSshKey generate an RSA private key in PEM
+func newSshKey() (privateKey string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, constants.GitSshKeyBits)
+	if err != nil {
+		return "", trace.TraceError(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+	return string(data), nil
+}
+
+// parseSshKey public key in authorized_keys format and its SHA256
+// fingerprint of the private key in PEM
+func parseSshKey(privateKey string) (publicKey string, fingerprint string, err error) {
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return "", "", errors.ErrorGitInvalidSshKey
+	}
+	publicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	fingerprint = ssh.FingerprintSHA256(signer.PublicKey())
+	return publicKey, fingerprint, nil
+}
+
+// getHostKeyCallback verify host keys against known hosts of the git repo
+// unless host key checking is disabled. The host key is pinned on first use
+// with pin if there are no known hosts.
+func getHostKeyCallback(g *models.Git, pin func(knownHosts string) error) (callback ssh.HostKeyCallback, err error) {
+	if g.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	if strings.TrimSpace(g.KnownHosts) == "" {
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			return pin(knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key))
+		}, nil
+	}
+
+	// knownhosts only reads from files
+	f, err := ioutil.TempFile("", "crawlab-known-hosts-")
+	if err != nil {
+		return nil, trace.TraceError(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(g.KnownHosts); err != nil {
+		_ = f.Close()
+		return nil, trace.TraceError(err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, trace.TraceError(err)
+	}
+
+	callback, err = knownhosts.New(f.Name())
+	if err != nil {
+		return nil, trace.TraceError(err)
+	}
+	return callback, nil
+}
@@ -0,0 +1,74 @@
+package gitsync
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"net"
+	"testing"
+)
+
+func TestParseSshKey(t *testing.T) {
+	privateKey, err := newSshKey()
+	require.Nil(t, err)
+
+	publicKey, fingerprint, err := parseSshKey(privateKey)
+	require.Nil(t, err)
+	k, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	require.Nil(t, err)
+	require.Equal(t, ssh.FingerprintSHA256(k), fingerprint)
+
+	_, _, err = parseSshKey("invalid")
+	require.Equal(t, errors.ErrorGitInvalidSshKey, err)
+}
+
+func TestGetHostKeyCallback(t *testing.T) {
+	newHostKey := func() ssh.PublicKey {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.Nil(t, err)
+		pub, err := ssh.NewPublicKey(&key.PublicKey)
+		require.Nil(t, err)
+		return pub
+	}
+	hostKey := newHostKey()
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 22}
+
+	// not checked
+	callback, err := getHostKeyCallback(&models.Git{InsecureIgnoreHostKey: true}, nil)
+	require.Nil(t, err)
+	require.Nil(t, callback("example.com:22", addr, hostKey))
+
+	// pinned on first use
+	var pinned string
+	callback, err = getHostKeyCallback(&models.Git{}, func(knownHosts string) error {
+		pinned = knownHosts
+		return nil
+	})
+	require.Nil(t, err)
+	require.Nil(t, callback("example.com:22", addr, hostKey))
+	require.Equal(t, knownhosts.Line([]string{"example.com"}, hostKey), pinned)
+
+	// checked against known hosts
+	callback, err = getHostKeyCallback(&models.Git{KnownHosts: pinned}, nil)
+	require.Nil(t, err)
+	require.Nil(t, callback("example.com:22", addr, hostKey))
+	require.NotNil(t, callback("example.com:22", addr, newHostKey()))
+	require.NotNil(t, callback("unknown.com:22", addr, hostKey))
+}
+
+func TestGetSecretKey(t *testing.T) {
+	viper.Set("secret.key", "")
+	_, err := getSecretKey()
+	require.Equal(t, errors.ErrorGitNoSecretKey, err)
+
+	viper.Set("secret.key", "test")
+	defer viper.Set("secret.key", "")
+	key, err := getSecretKey()
+	require.Nil(t, err)
+	require.Equal(t, "test", key)
+}
@@ -0,0 +1,402 @@
+package scrapy
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const scrapyCfgPath = "scrapy.cfg"
+
+var settingNameRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+var importScrapyRegexp = regexp.MustCompile(`(?m)^import scrapy\s*$`)
+
+// Project scrapy project in the spider fs, which is parsed from project files
+// instead of running scrapy commands as the master may not have python
+type Project struct {
+	fsSvc        interfaces.SpiderFsService
+	module       string // project module, e.g. tutorial
+	settingsPath string // path of the settings module, e.g. tutorial/settings.py
+}
+
+// NewProject scrapy project of which scrapy.cfg is in the root of the spider fs
+func NewProject(fsSvc interfaces.SpiderFsService) (p *Project, err error) {
+	content, ok, err := getFile(fsSvc, scrapyCfgPath)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.ErrorSpiderScrapyProjectNotFound
+	}
+	settingsModule := getSettingsModule(content)
+	if settingsModule == "" {
+		return nil, errors.ErrorSpiderScrapyProjectNotFound
+	}
+	p = &Project{
+		fsSvc:        fsSvc,
+		settingsPath: getModulePath(settingsModule) + ".py",
+	}
+	if idx := strings.LastIndex(settingsModule, "."); idx > 0 {
+		p.module = settingsModule[:idx]
+	}
+	return p, nil
+}
+
+// CreateProject scaffold a scrapy project in the root of the spider fs
+func CreateProject(fsSvc interfaces.SpiderFsService, name string) (err error) {
+	if !identifierRegexp.MatchString(name) {
+		return errors.ErrorSpiderInvalidScrapyName
+	}
+	if _, ok, err := getFile(fsSvc, scrapyCfgPath); err != nil {
+		return err
+	} else if ok {
+		return errors.ErrorSpiderScrapyProjectExists
+	}
+
+	// files
+	r := strings.NewReplacer("${Project}", getCamelCase(name), "$project", name)
+	for filePath, content := range projectTemplate {
+		if err := fsSvc.GetFsService().Save(r.Replace(filePath), []byte(r.Replace(content)), interfaces.WithNotSyncToWorkspace()); err != nil {
+			return err
+		}
+	}
+	return fsSvc.GetFsService().SyncToWorkspace()
+}
+
+// GetSpiders spiders in the spider modules, i.e. SPIDER_MODULES
+func (p *Project) GetSpiders() (spiders []entity.ScrapySpider, err error) {
+	settings, err := p.getSettingsMap()
+	if err != nil {
+		return nil, err
+	}
+
+	// spider modules
+	var modules []string
+	if values, ok := settings["SPIDER_MODULES"].([]interface{}); ok {
+		for _, v := range values {
+			if m, ok := v.(string); ok {
+				modules = append(modules, m)
+			}
+		}
+	}
+	if len(modules) == 0 {
+		modules = []string{p.module + ".spiders"}
+	}
+
+	// spider files
+	for _, m := range modules {
+		files, err := p.fsSvc.List(getModulePath(m))
+		if err != nil {
+			// skip modules not found
+			continue
+		}
+		if err := p.walkFiles(files, func(f interfaces.FsFileInfo) error {
+			if f.GetExtension() != "py" && path.Ext(f.GetName()) != ".py" {
+				return nil
+			}
+			data, err := p.fsSvc.GetFile(f.GetPath())
+			if err != nil {
+				return err
+			}
+			for _, s := range parseSpiders(string(data)) {
+				s.Path = strings.TrimPrefix(f.GetPath(), "/")
+				spiders = append(spiders, s)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return spiders, nil
+}
+
+// CreateSpider scaffold a spider with the template in the module of new
+// spiders, i.e. NEWSPIDER_MODULE
+func (p *Project) CreateSpider(name, domain, template string) (err error) {
+	// validate
+	if !identifierRegexp.MatchString(name) {
+		return errors.ErrorSpiderInvalidScrapyName
+	}
+	if template == "" {
+		template = constants.ScrapySpiderTemplateBasic
+	}
+	content, ok := spiderTemplates[template]
+	if !ok {
+		return errors.ErrorSpiderInvalidScrapyTemplate
+	}
+	domain = getDomain(domain)
+	if domain == "" {
+		return errors.ErrorSpiderMissingRequiredOption
+	}
+
+	// skip if exists
+	spiders, err := p.GetSpiders()
+	if err != nil {
+		return err
+	}
+	for _, s := range spiders {
+		if s.Name == name {
+			return errors.ErrorSpiderScrapySpiderExists
+		}
+	}
+
+	// spider file path
+	settings, err := p.getSettingsMap()
+	if err != nil {
+		return err
+	}
+	module, _ := settings["NEWSPIDER_MODULE"].(string)
+	if module == "" {
+		module = p.module + ".spiders"
+	}
+	filePath := getModulePath(module) + "/" + name + ".py"
+	if _, ok, err := getFile(p.fsSvc, filePath); err != nil {
+		return err
+	} else if ok {
+		return errors.ErrorSpiderScrapySpiderExists
+	}
+
+	// save
+	content = strings.NewReplacer(
+		"$classname", getCamelCase(name)+"Spider",
+		"$name", name,
+		"$domain", domain,
+		"$url", "https://"+domain+"/",
+	).Replace(content)
+	return p.fsSvc.Save(filePath, []byte(content))
+}
+
+// GetSettings settings in the settings module, of which values are python
+// literals or expressions (constants.Expression)
+func (p *Project) GetSettings() (params []entity.ScrapySettingParam, err error) {
+	content, _, err := getFile(p.fsSvc, p.settingsPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseSettings(content), nil
+}
+
+// SaveSettings replace settings in the settings module with the params, of
+// which settings not in the params are removed while other code is kept
+func (p *Project) SaveSettings(params []entity.ScrapySettingParam) (err error) {
+	return p.saveSettings(params, false)
+}
+
+// GetItems item classes in the items module
+func (p *Project) GetItems() (items []entity.ScrapyItem, err error) {
+	content, _, err := getFile(p.fsSvc, p.getPath("items"))
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range parseItemClasses(splitLines(content)) {
+		item := entity.ScrapyItem{Name: c.name, Fields: []string{}}
+		for _, f := range c.fields {
+			item.Fields = append(item.Fields, f.name)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// SaveItems replace item classes in the items module with the items, of which
+// definitions of existing fields and other code are kept
+func (p *Project) SaveItems(items []entity.ScrapyItem) (err error) {
+	filePath := p.getPath("items")
+	content, _, err := getFile(p.fsSvc, filePath)
+	if err != nil {
+		return err
+	}
+	content, err = setItems(content, items)
+	if err != nil {
+		return err
+	}
+	return p.fsSvc.Save(filePath, []byte(content))
+}
+
+// GetPipelines pipeline classes in the pipelines module and pipelines in
+// ITEM_PIPELINES, of which enabled ones are ordered by priority
+func (p *Project) GetPipelines() (pipelines []entity.ScrapyPipeline, err error) {
+	// enabled pipelines
+	settings, err := p.getSettingsMap()
+	if err != nil {
+		return nil, err
+	}
+	enabled, _ := settings["ITEM_PIPELINES"].(map[string]interface{})
+
+	// pipeline classes
+	content, _, err := getFile(p.fsSvc, p.getPath("pipelines"))
+	if err != nil {
+		return nil, err
+	}
+	names := parsePipelineClasses(content)
+	for i, name := range names {
+		names[i] = p.module + ".pipelines." + name
+	}
+	for name := range enabled {
+		names = append(names, name)
+	}
+
+	// pipelines
+	dict := map[string]bool{}
+	for _, name := range names {
+		if dict[name] {
+			continue
+		}
+		dict[name] = true
+		pipeline := entity.ScrapyPipeline{Name: name}
+		if priority, ok := enabled[name].(float64); ok {
+			pipeline.Enabled = true
+			pipeline.Priority = int(priority)
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+	sort.SliceStable(pipelines, func(i, j int) bool {
+		if pipelines[i].Enabled != pipelines[j].Enabled {
+			return pipelines[i].Enabled
+		}
+		return pipelines[i].Priority < pipelines[j].Priority
+	})
+
+	return pipelines, nil
+}
+
+// SavePipelines set ITEM_PIPELINES with enabled pipelines
+func (p *Project) SavePipelines(pipelines []entity.ScrapyPipeline) (err error) {
+	value := map[string]interface{}{}
+	for _, pipeline := range pipelines {
+		if pipeline.Enabled {
+			value[pipeline.Name] = pipeline.Priority
+		}
+	}
+	return p.saveSettings([]entity.ScrapySettingParam{{
+		Key:   "ITEM_PIPELINES",
+		Value: value,
+		Type:  constants.Object,
+	}}, true)
+}
+
+func (p *Project) saveSettings(params []entity.ScrapySettingParam, partial bool) (err error) {
+	content, _, err := getFile(p.fsSvc, p.settingsPath)
+	if err != nil {
+		return err
+	}
+	content, err = setSettings(content, params, partial)
+	if err != nil {
+		return err
+	}
+	return p.fsSvc.Save(p.settingsPath, []byte(content))
+}
+
+// getSettingsMap literal values of settings
+func (p *Project) getSettingsMap() (settings map[string]interface{}, err error) {
+	params, err := p.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	settings = map[string]interface{}{}
+	for _, param := range params {
+		if param.Type != constants.Expression {
+			settings[param.Key] = param.Value
+		}
+	}
+	return settings, nil
+}
+
+// getPath path of the module in the project module, e.g. tutorial/items.py
+func (p *Project) getPath(module string) (res string) {
+	if p.module == "" {
+		return module + ".py"
+	}
+	return getModulePath(p.module) + "/" + module + ".py"
+}
+
+func (p *Project) walkFiles(files []interfaces.FsFileInfo, fn func(f interfaces.FsFileInfo) error) (err error) {
+	for _, f := range files {
+		if f.GetIsDir() {
+			if err := p.walkFiles(f.GetChildren(), fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getFile content of the file in the spider fs, false if not exists
+func getFile(fsSvc interfaces.SpiderFsService, filePath string) (content string, ok bool, err error) {
+	if _, err := fsSvc.GetFileInfo(filePath); err != nil {
+		return "", false, nil
+	}
+	data, err := fsSvc.GetFile(filePath)
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
+
+// getSettingsModule settings module in the [settings] section of scrapy.cfg
+func getSettingsModule(cfg string) (module string) {
+	section := ""
+	for _, line := range splitLines(cfg) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != "settings" {
+			continue
+		}
+		idx := strings.IndexAny(line, "=:")
+		if idx > 0 && strings.TrimSpace(line[:idx]) == "default" {
+			return strings.TrimSpace(line[idx+1:])
+		}
+	}
+	return ""
+}
+
+// getModulePath path of the python module, e.g. tutorial/spiders of tutorial.spiders
+func getModulePath(module string) (res string) {
+	return strings.Replace(module, ".", "/", -1)
+}
+
+// getCamelCase class name of the name as scrapy does, e.g. MySpider of my_spider
+func getCamelCase(name string) (res string) {
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		res += strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+	}
+	return res
+}
+
+// getDomain domain of the url or domain
+func getDomain(s string) (domain string) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return ""
+		}
+		return u.Host
+	}
+	return strings.SplitN(s, "/", 2)[0]
+}
+
+func splitLines(content string) (lines []string) {
+	return strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n")
+}
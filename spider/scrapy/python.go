@@ -0,0 +1,467 @@
+package scrapy
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/luke513009828/crawlab-core/errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// minimal support of python sources of scrapy projects, which covers literals
+// and top-level statements instead of the whole grammar
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var numberRegexp = regexp.MustCompile(`^[-+]?(\d[\d_]*\.?[\d_]*|\.\d[\d_]*)([eE][-+]?\d+)?`)
+
+// statement top-level statement of a python source, of which lines are
+// [start, end)
+type statement struct {
+	start int
+	end   int
+	text  string
+}
+
+// getStatements top-level statements of python source lines at the indent,
+// e.g. "" for the module and "    " for a class body, of which lines inside
+// brackets, strings or after backslashes are continuations
+func getStatements(lines []string, start, end int, indent string) (statements []statement) {
+	for i := start; i < end; i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || !strings.HasPrefix(line, indent) {
+			continue
+		}
+		rest := line[len(indent):]
+		if rest == "" || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '#' {
+			continue
+		}
+		j := getStatementEnd(lines, i)
+		if j > end {
+			j = end
+		}
+		statements = append(statements, statement{
+			start: i,
+			end:   j,
+			text:  strings.Join(lines[i:j], "\n"),
+		})
+		i = j - 1
+	}
+	return statements
+}
+
+// getStatementEnd index of the line after the statement starting at the line
+func getStatementEnd(lines []string, start int) (end int) {
+	depth := 0
+	quote := ""
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		continued := false
+		for j := 0; j < len(line); j++ {
+			c := line[j]
+			if quote != "" {
+				if c == '\\' {
+					j++
+				} else if strings.HasPrefix(line[j:], quote) {
+					j += len(quote) - 1
+					quote = ""
+				}
+				continue
+			}
+			switch c {
+			case '#':
+				j = len(line)
+			case '\'', '"':
+				quote = string(c)
+				if strings.HasPrefix(line[j:], strings.Repeat(quote, 3)) {
+					quote = strings.Repeat(quote, 3)
+					j += 2
+				}
+			case '(', '[', '{':
+				depth++
+			case ')', ']', '}':
+				depth--
+			case '\\':
+				if j == len(line)-1 {
+					continued = true
+				}
+			}
+		}
+		// single-quoted strings end at the end of lines
+		if len(quote) == 1 {
+			quote = ""
+		}
+		if depth <= 0 && quote == "" && !continued {
+			return i + 1
+		}
+	}
+	return len(lines)
+}
+
+// getBlockEnd index of the line after the statement starting at the line
+// with its indented block, e.g. a class at the indent "" or a method at the
+// indent "    ", of which trailing blank lines are excluded
+func getBlockEnd(lines []string, start int, indent string) (end int) {
+	end = getStatementEnd(lines, start)
+	for i := end; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, indent) || len(line) == len(indent) {
+			break
+		}
+		if c := line[len(indent)]; c != ' ' && c != '\t' {
+			break
+		}
+		end = i + 1
+	}
+	return end
+}
+
+// getIndent indent of the first non-blank line in [start, end)
+func getIndent(lines []string, start, end int) (indent string) {
+	for i := start; i < end; i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		return lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+	}
+	return "    "
+}
+
+// parseAssignment name and value of an assignment statement "NAME = value"
+func parseAssignment(text string) (name string, value string, ok bool) {
+	idx := strings.Index(text, "=")
+	if idx <= 0 || strings.HasPrefix(text[idx:], "==") {
+		return "", "", false
+	}
+	name = strings.TrimSpace(text[:idx])
+	if !identifierRegexp.MatchString(name) {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(text[idx+1:]), true
+}
+
+// parseClass name and bases of a class statement "class Name(Base):"
+func parseClass(text string) (name string, bases string, ok bool) {
+	if !strings.HasPrefix(text, "class ") {
+		return "", "", false
+	}
+	def := strings.TrimSpace(strings.TrimPrefix(strings.SplitN(text, "\n", 2)[0], "class "))
+	idx := strings.IndexAny(def, "(:")
+	if idx <= 0 {
+		return "", "", false
+	}
+	name = strings.TrimSpace(def[:idx])
+	if def[idx] == '(' {
+		end := strings.LastIndex(def, ")")
+		if end < idx {
+			return "", "", false
+		}
+		bases = def[idx+1 : end]
+	}
+	return name, bases, true
+}
+
+// parseLiteral value of a python literal, which is string, float64, bool,
+// []interface{} or map[string]interface{}, false if not a literal
+func parseLiteral(s string) (value interface{}, ok bool) {
+	p := &literalParser{s: s}
+	value, ok = p.parseValue()
+	if !ok {
+		return nil, false
+	}
+	p.skipSpace()
+	if p.i < len(p.s) {
+		return nil, false
+	}
+	return value, true
+}
+
+type literalParser struct {
+	s string
+	i int
+}
+
+func (p *literalParser) skipSpace() {
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.i++
+		case c == '\\' && p.i+1 < len(p.s) && p.s[p.i+1] == '\n':
+			p.i += 2
+		case c == '#':
+			for p.i < len(p.s) && p.s[p.i] != '\n' {
+				p.i++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *literalParser) parseValue() (value interface{}, ok bool) {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return nil, false
+	}
+	c := p.s[p.i]
+	switch {
+	case c == '[' || c == '(':
+		return p.parseList()
+	case c == '{':
+		return p.parseDict()
+	case c == '\'' || c == '"' || p.hasStringPrefix():
+		return p.parseStrings()
+	case p.hasKeyword("True"):
+		p.i += 4
+		return true, true
+	case p.hasKeyword("False"):
+		p.i += 5
+		return false, true
+	default:
+		m := numberRegexp.FindString(p.s[p.i:])
+		if m == "" {
+			return nil, false
+		}
+		v, err := strconv.ParseFloat(strings.Replace(m, "_", "", -1), 64)
+		if err != nil {
+			return nil, false
+		}
+		p.i += len(m)
+		return v, true
+	}
+}
+
+func (p *literalParser) parseList() (value interface{}, ok bool) {
+	open := p.s[p.i]
+	close := byte(']')
+	if open == '(' {
+		close = ')'
+	}
+	p.i++
+	list := []interface{}{}
+	hasComma := false
+	for {
+		p.skipSpace()
+		if p.i < len(p.s) && p.s[p.i] == close {
+			p.i++
+			break
+		}
+		v, ok := p.parseValue()
+		if !ok {
+			return nil, false
+		}
+		list = append(list, v)
+		p.skipSpace()
+		if p.i >= len(p.s) {
+			return nil, false
+		}
+		if p.s[p.i] == ',' {
+			hasComma = true
+			p.i++
+			continue
+		}
+		if p.s[p.i] != close {
+			return nil, false
+		}
+		p.i++
+		break
+	}
+	// parenthesized value instead of tuple
+	if open == '(' && len(list) == 1 && !hasComma {
+		return list[0], true
+	}
+	return list, true
+}
+
+func (p *literalParser) parseDict() (value interface{}, ok bool) {
+	p.i++
+	dict := map[string]interface{}{}
+	for {
+		p.skipSpace()
+		if p.i < len(p.s) && p.s[p.i] == '}' {
+			p.i++
+			return dict, true
+		}
+		k, ok := p.parseValue()
+		if !ok {
+			return nil, false
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, false
+		}
+		p.skipSpace()
+		if p.i >= len(p.s) || p.s[p.i] != ':' {
+			return nil, false
+		}
+		p.i++
+		v, ok := p.parseValue()
+		if !ok {
+			return nil, false
+		}
+		dict[key] = v
+		p.skipSpace()
+		if p.i >= len(p.s) {
+			return nil, false
+		}
+		if p.s[p.i] == ',' {
+			p.i++
+			continue
+		}
+		if p.s[p.i] != '}' {
+			return nil, false
+		}
+		p.i++
+		return dict, true
+	}
+}
+
+// hasKeyword whether at the keyword, e.g. True instead of TrueValue
+func (p *literalParser) hasKeyword(keyword string) bool {
+	rest := p.s[p.i:]
+	if !strings.HasPrefix(rest, keyword) {
+		return false
+	}
+	return len(rest) == len(keyword) || !identifierRegexp.MatchString("_"+rest[len(keyword):len(keyword)+1])
+}
+
+// hasStringPrefix whether at a string with prefix, e.g. r'\d+', of which
+// f-strings and bytes are not literals of settings
+func (p *literalParser) hasStringPrefix() bool {
+	rest := p.s[p.i:]
+	for _, prefix := range []string{"r", "R", "u", "U"} {
+		if strings.HasPrefix(rest, prefix+"'") || strings.HasPrefix(rest, prefix+"\"") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStrings value of adjacent strings, e.g. 'a' "b"
+func (p *literalParser) parseStrings() (value interface{}, ok bool) {
+	var sb strings.Builder
+	for {
+		s, ok := p.parseString()
+		if !ok {
+			return nil, false
+		}
+		sb.WriteString(s)
+		p.skipSpace()
+		if p.i >= len(p.s) || !(p.s[p.i] == '\'' || p.s[p.i] == '"' || p.hasStringPrefix()) {
+			return sb.String(), true
+		}
+	}
+}
+
+func (p *literalParser) parseString() (value string, ok bool) {
+	raw := false
+	if c := p.s[p.i]; c != '\'' && c != '"' {
+		raw = c == 'r' || c == 'R'
+		p.i++
+	}
+	quote := p.s[p.i : p.i+1]
+	if strings.HasPrefix(p.s[p.i:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	p.i += len(quote)
+	var sb strings.Builder
+	for p.i < len(p.s) {
+		if strings.HasPrefix(p.s[p.i:], quote) {
+			p.i += len(quote)
+			return sb.String(), true
+		}
+		c := p.s[p.i]
+		if c == '\n' && len(quote) == 1 {
+			return "", false
+		}
+		if c != '\\' || p.i+1 >= len(p.s) {
+			sb.WriteByte(c)
+			p.i++
+			continue
+		}
+		next := p.s[p.i+1]
+		if raw {
+			sb.WriteByte(c)
+			sb.WriteByte(next)
+			p.i += 2
+			continue
+		}
+		p.i += 2
+		switch next {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case '\\', '\'', '"':
+			sb.WriteByte(next)
+		case '\n':
+		default:
+			sb.WriteByte(c)
+			sb.WriteByte(next)
+		}
+	}
+	return "", false
+}
+
+// formatLiteral python literal of the value decoded from json
+func formatLiteral(value interface{}) (res string, err error) {
+	switch v := value.(type) {
+	case nil:
+		return "None", nil
+	case string:
+		return formatString(v), nil
+	case bool:
+		if v {
+			return "True", nil
+		}
+		return "False", nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			s, err := formatLiteral(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}", nil
+		}
+		var keys []string
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		res = "{\n"
+		for _, key := range keys {
+			s, err := formatLiteral(v[key])
+			if err != nil {
+				return "", err
+			}
+			res += fmt.Sprintf("    %s: %s,\n", formatString(key), s)
+		}
+		return res + "}", nil
+	default:
+		return "", errors.ErrorSpiderInvalidScrapySetting
+	}
+}
+
+// formatString python string literal, as json strings are valid ones
+func formatString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,301 @@
+package scrapy
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/luke513009828/crawlab-core/errors"
+	"strings"
+)
+
+// itemClass scrapy.Item class in the items module, of which lines are [start, end)
+type itemClass struct {
+	name    string
+	bases   string
+	start   int
+	end     int
+	indent  string
+	fields  []itemMember
+	members []itemMember // non-field members, e.g. docstrings or methods
+}
+
+type itemMember struct {
+	name string
+	text string
+}
+
+// parseSettings settings of the settings module, which are upper-case
+// top-level assignments
+func parseSettings(content string) (params []entity.ScrapySettingParam) {
+	lines := splitLines(content)
+	idx := map[string]int{}
+	for _, st := range getStatements(lines, 0, len(lines), "") {
+		name, value, ok := parseAssignment(st.text)
+		if !ok || !settingNameRegexp.MatchString(name) {
+			continue
+		}
+		param := newSettingParam(name, value)
+
+		// the last assignment takes effect
+		if i, ok := idx[name]; ok {
+			params[i] = param
+			continue
+		}
+		idx[name] = len(params)
+		params = append(params, param)
+	}
+	return params
+}
+
+func newSettingParam(name, value string) (param entity.ScrapySettingParam) {
+	v, ok := parseLiteral(value)
+	if !ok {
+		return entity.ScrapySettingParam{Key: name, Value: value, Type: constants.Expression}
+	}
+	param = entity.ScrapySettingParam{Key: name, Value: v}
+	switch v.(type) {
+	case string:
+		param.Type = constants.String
+	case float64:
+		param.Type = constants.Number
+	case bool:
+		param.Type = constants.Boolean
+	case []interface{}:
+		param.Type = constants.Array
+	case map[string]interface{}:
+		param.Type = constants.Object
+	}
+	return param
+}
+
+// setSettings set settings of the settings module with the params in place,
+// of which settings not in the params are removed unless partial
+func setSettings(content string, params []entity.ScrapySettingParam, partial bool) (res string, err error) {
+	// settings code
+	var keys []string
+	code := map[string]string{}
+	for _, param := range params {
+		if !settingNameRegexp.MatchString(param.Key) {
+			return "", errors.ErrorSpiderInvalidScrapySetting
+		}
+		var value string
+		if param.Type == constants.Expression {
+			s, ok := param.Value.(string)
+			if !ok || strings.TrimSpace(s) == "" {
+				return "", errors.ErrorSpiderInvalidScrapySetting
+			}
+			value = strings.TrimSpace(s)
+		} else {
+			value, err = formatLiteral(param.Value)
+			if err != nil {
+				return "", err
+			}
+		}
+		if _, ok := code[param.Key]; !ok {
+			keys = append(keys, param.Key)
+		}
+		code[param.Key] = param.Key + " = " + value
+	}
+
+	// replace or remove existing settings
+	lines := splitLines(content)
+	var resLines []string
+	written := map[string]bool{}
+	i := 0
+	for _, st := range getStatements(lines, 0, len(lines), "") {
+		name, _, ok := parseAssignment(st.text)
+		if !ok || !settingNameRegexp.MatchString(name) {
+			continue
+		}
+		_, hasCode := code[name]
+		if partial && !hasCode {
+			continue
+		}
+		resLines = append(resLines, lines[i:st.start]...)
+		i = st.end
+		if hasCode && !written[name] {
+			resLines = append(resLines, code[name])
+			written[name] = true
+			continue
+		}
+		i = skipBlankLines(lines, i, resLines)
+	}
+	resLines = append(resLines, lines[i:]...)
+
+	// append new settings
+	res = strings.TrimRight(strings.Join(resLines, "\n"), "\n")
+	for _, key := range keys {
+		if written[key] {
+			continue
+		}
+		if res != "" {
+			res += "\n"
+		}
+		res += code[key]
+	}
+	return res + "\n", nil
+}
+
+// skipBlankLines index of the line after blank lines following a removed
+// statement, if it is preceded by a blank line
+func skipBlankLines(lines []string, i int, resLines []string) int {
+	if len(resLines) == 0 || strings.TrimSpace(resLines[len(resLines)-1]) != "" {
+		return i
+	}
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	return i
+}
+
+// parseItemClasses classes of which bases are items, e.g. scrapy.Item
+func parseItemClasses(lines []string) (classes []itemClass) {
+	for _, st := range getStatements(lines, 0, len(lines), "") {
+		name, bases, ok := parseClass(st.text)
+		if !ok || !strings.Contains(bases, "Item") {
+			continue
+		}
+		c := itemClass{
+			name:  name,
+			bases: bases,
+			start: st.start,
+			end:   getBlockEnd(lines, st.start, ""),
+		}
+		c.indent = getIndent(lines, st.end, c.end)
+
+		// members
+		for _, m := range getStatements(lines, st.end, c.end, c.indent) {
+			end := getBlockEnd(lines, m.start, c.indent)
+			member := itemMember{text: strings.Join(lines[m.start:end], "\n")}
+			if name, value, ok := parseAssignment(m.text); ok && strings.Contains(value, "Field(") {
+				member.name = name
+				c.fields = append(c.fields, member)
+				continue
+			}
+			if strings.TrimSpace(m.text) == "pass" {
+				continue
+			}
+			c.members = append(c.members, member)
+		}
+		classes = append(classes, c)
+	}
+	return classes
+}
+
+// setItems set item classes of the items module with the items in place, of
+// which classes not in the items are removed
+func setItems(content string, items []entity.ScrapyItem) (res string, err error) {
+	// validate
+	for _, item := range items {
+		if !identifierRegexp.MatchString(item.Name) {
+			return "", errors.ErrorSpiderInvalidScrapyName
+		}
+		for _, f := range item.Fields {
+			if !identifierRegexp.MatchString(f) {
+				return "", errors.ErrorSpiderInvalidScrapyName
+			}
+		}
+	}
+	itemsMap := map[string]entity.ScrapyItem{}
+	for _, item := range items {
+		itemsMap[item.Name] = item
+	}
+
+	// replace or remove existing classes
+	lines := splitLines(content)
+	var resLines []string
+	written := map[string]bool{}
+	i := 0
+	for _, c := range parseItemClasses(lines) {
+		resLines = append(resLines, lines[i:c.start]...)
+		i = c.end
+		item, ok := itemsMap[c.name]
+		if !ok || written[c.name] {
+			i = skipBlankLines(lines, i, resLines)
+			continue
+		}
+		resLines = append(resLines, formatItemClass(item, c))
+		written[c.name] = true
+	}
+	resLines = append(resLines, lines[i:]...)
+
+	// append new classes
+	res = strings.TrimRight(strings.Join(resLines, "\n"), "\n")
+	for _, item := range items {
+		if written[item.Name] {
+			continue
+		}
+		written[item.Name] = true
+		if !importScrapyRegexp.MatchString(res) {
+			res = strings.TrimRight("import scrapy\n"+res, "\n")
+		}
+		res += "\n\n\n" + formatItemClass(item, itemClass{bases: "scrapy.Item", indent: "    "})
+	}
+	return strings.TrimLeft(res, "\n") + "\n", nil
+}
+
+// formatItemClass code of the item class, of which definitions of existing
+// fields and other members of the class are kept
+func formatItemClass(item entity.ScrapyItem, c itemClass) (res string) {
+	fields := map[string]string{}
+	for _, f := range c.fields {
+		fields[f.name] = f.text
+	}
+	res = "class " + item.Name + "(" + c.bases + "):"
+	for _, m := range c.members {
+		res += "\n" + m.text
+	}
+	for _, f := range item.Fields {
+		if text, ok := fields[f]; ok {
+			res += "\n" + text
+			continue
+		}
+		res += "\n" + c.indent + f + " = scrapy.Field()"
+	}
+	if len(c.members) == 0 && len(item.Fields) == 0 {
+		res += "\n" + c.indent + "pass"
+	}
+	return res
+}
+
+// parsePipelineClasses classes with process_item in the pipelines module
+func parsePipelineClasses(content string) (names []string) {
+	lines := splitLines(content)
+	for _, st := range getStatements(lines, 0, len(lines), "") {
+		name, _, ok := parseClass(st.text)
+		if !ok {
+			continue
+		}
+		end := getBlockEnd(lines, st.start, "")
+		body := strings.Join(lines[st.end:end], "\n")
+		if strings.Contains(body, "def process_item(") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseSpiders spider classes with names in the spider module
+func parseSpiders(content string) (spiders []entity.ScrapySpider) {
+	lines := splitLines(content)
+	for _, st := range getStatements(lines, 0, len(lines), "") {
+		className, bases, ok := parseClass(st.text)
+		if !ok || !strings.Contains(bases, "Spider") {
+			continue
+		}
+		end := getBlockEnd(lines, st.start, "")
+		indent := getIndent(lines, st.end, end)
+		for _, m := range getStatements(lines, st.end, end, indent) {
+			name, value, ok := parseAssignment(m.text)
+			if !ok || name != "name" {
+				continue
+			}
+			if v, ok := parseLiteral(value); ok {
+				if s, ok := v.(string); ok {
+					spiders = append(spiders, entity.ScrapySpider{Name: s, ClassName: className})
+				}
+			}
+			break
+		}
+	}
+	return spiders
+}
@@ -0,0 +1,228 @@
+package scrapy
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const testSettings = `# Scrapy settings for tutorial project
+import os
+
+BOT_NAME = 'tutorial'
+
+SPIDER_MODULES = ['tutorial.spiders']
+NEWSPIDER_MODULE = "tutorial.spiders"
+
+ROBOTSTXT_OBEY = True  # obey robots.txt
+CONCURRENT_REQUESTS = 16
+DOWNLOAD_DELAY = 0.5
+
+ITEM_PIPELINES = {
+    'tutorial.pipelines.TutorialPipeline': 300,
+    "crawlab.CrawlabPipeline": 888,
+}
+
+LOG_FILE = os.environ.get('LOG_FILE')
+
+if os.environ.get('DEBUG'):
+    LOG_LEVEL = 'DEBUG'
+`
+
+func TestParseLiteral(t *testing.T) {
+	cases := map[string]interface{}{
+		`'a\'b'`:             "a'b",
+		`"a" 'b'`:            "ab",
+		`r'\d+'`:             `\d+`,
+		`'''a"b'''`:          `a"b`,
+		`-1_000`:             float64(-1000),
+		`1.5e2`:              float64(150),
+		`True`:               true,
+		`(1)`:                float64(1),
+		`(1,)`:               []interface{}{float64(1)},
+		`['a', ("b", 2), ]`:  []interface{}{"a", []interface{}{"b", float64(2)}},
+		"{\n 'a': 1, # x\n}": map[string]interface{}{"a": float64(1)},
+	}
+	for s, expected := range cases {
+		v, ok := parseLiteral(s)
+		require.True(t, ok, s)
+		require.Equal(t, expected, v, s)
+	}
+	for _, s := range []string{`None`, `TrueValue`, `os.environ.get('A')`, `{1: 2}`, `[1, 2`, `f'{a}'`, `1 + 2`} {
+		_, ok := parseLiteral(s)
+		require.False(t, ok, s)
+	}
+
+	// round trip
+	v := map[string]interface{}{"a": []interface{}{"b\n'", float64(1.5), true}, "c": map[string]interface{}{}}
+	s, err := formatLiteral(v)
+	require.Nil(t, err)
+	res, ok := parseLiteral(s)
+	require.True(t, ok, s)
+	require.Equal(t, v, res)
+}
+
+func TestParseSettings(t *testing.T) {
+	params := parseSettings(testSettings)
+	settings := map[string]entity.ScrapySettingParam{}
+	var keys []string
+	for _, p := range params {
+		settings[p.Key] = p
+		keys = append(keys, p.Key)
+	}
+	require.Equal(t, []string{"BOT_NAME", "SPIDER_MODULES", "NEWSPIDER_MODULE", "ROBOTSTXT_OBEY", "CONCURRENT_REQUESTS", "DOWNLOAD_DELAY", "ITEM_PIPELINES", "LOG_FILE"}, keys)
+	require.Equal(t, entity.ScrapySettingParam{Key: "BOT_NAME", Value: "tutorial", Type: constants.String}, settings["BOT_NAME"])
+	require.Equal(t, constants.Boolean, settings["ROBOTSTXT_OBEY"].Type)
+	require.Equal(t, float64(0.5), settings["DOWNLOAD_DELAY"].Value)
+	require.Equal(t, constants.Object, settings["ITEM_PIPELINES"].Type)
+	require.Equal(t, entity.ScrapySettingParam{Key: "LOG_FILE", Value: "os.environ.get('LOG_FILE')", Type: constants.Expression}, settings["LOG_FILE"])
+}
+
+func TestSetSettings(t *testing.T) {
+	params := parseSettings(testSettings)
+	for i, p := range params {
+		switch p.Key {
+		case "DOWNLOAD_DELAY":
+			params[i].Value = float64(2)
+		case "ITEM_PIPELINES":
+			params[i].Value = map[string]interface{}{"crawlab.CrawlabPipeline": float64(888)}
+		}
+	}
+	params = append(params[1:], entity.ScrapySettingParam{Key: "COOKIES_ENABLED", Value: false})
+
+	content, err := setSettings(testSettings, params, false)
+	require.Nil(t, err)
+	require.Equal(t, `# Scrapy settings for tutorial project
+import os
+
+SPIDER_MODULES = ["tutorial.spiders"]
+NEWSPIDER_MODULE = "tutorial.spiders"
+
+ROBOTSTXT_OBEY = True
+CONCURRENT_REQUESTS = 16
+DOWNLOAD_DELAY = 2
+
+ITEM_PIPELINES = {
+    "crawlab.CrawlabPipeline": 888,
+}
+
+LOG_FILE = os.environ.get('LOG_FILE')
+
+if os.environ.get('DEBUG'):
+    LOG_LEVEL = 'DEBUG'
+COOKIES_ENABLED = False
+`, content)
+
+	// partial
+	content, err = setSettings(testSettings, []entity.ScrapySettingParam{{Key: "BOT_NAME", Value: "test"}}, true)
+	require.Nil(t, err)
+	require.Equal(t, len(parseSettings(testSettings)), len(parseSettings(content)))
+	require.Equal(t, "test", parseSettings(content)[0].Value)
+
+	// invalid
+	_, err = setSettings(testSettings, []entity.ScrapySettingParam{{Key: "bot_name", Value: "test"}}, true)
+	require.NotNil(t, err)
+	_, err = setSettings(testSettings, []entity.ScrapySettingParam{{Key: "BOT_NAME", Value: 1, Type: constants.Expression}}, true)
+	require.NotNil(t, err)
+}
+
+func TestSetItems(t *testing.T) {
+	content := `import scrapy
+from itemloaders.processors import TakeFirst
+
+
+class QuoteItem(scrapy.Item):
+    text = scrapy.Field(
+        output_processor=TakeFirst(),
+    )
+    author = scrapy.Field()
+
+
+class RemovedItem(scrapy.Item):
+    pass
+
+
+def helper():
+    pass
+`
+	classes := parseItemClasses(splitLines(content))
+	require.Len(t, classes, 2)
+	require.Equal(t, "QuoteItem", classes[0].name)
+	require.Len(t, classes[0].fields, 2)
+
+	res, err := setItems(content, []entity.ScrapyItem{
+		{Name: "QuoteItem", Fields: []string{"text", "tags"}},
+		{Name: "AuthorItem", Fields: []string{"name"}},
+	})
+	require.Nil(t, err)
+	require.Equal(t, `import scrapy
+from itemloaders.processors import TakeFirst
+
+
+class QuoteItem(scrapy.Item):
+    text = scrapy.Field(
+        output_processor=TakeFirst(),
+    )
+    tags = scrapy.Field()
+
+
+def helper():
+    pass
+
+
+class AuthorItem(scrapy.Item):
+    name = scrapy.Field()
+`, res)
+
+	// new items module
+	res, err = setItems("", []entity.ScrapyItem{{Name: "TestItem"}})
+	require.Nil(t, err)
+	require.Equal(t, "import scrapy\n\n\nclass TestItem(scrapy.Item):\n    pass\n", res)
+
+	_, err = setItems(content, []entity.ScrapyItem{{Name: "Quote-Item"}})
+	require.NotNil(t, err)
+}
+
+func TestParseSpiders(t *testing.T) {
+	content := `import scrapy
+from scrapy.spiders import CrawlSpider
+
+
+class QuotesSpider(scrapy.Spider):
+    name = "quotes"
+
+    def parse(self, response):
+        name = "not a spider name"
+
+
+class BooksSpider(CrawlSpider):
+    """books"""
+    name = 'books'
+
+
+class Helper:
+    name = 'helper'
+`
+	require.Equal(t, []entity.ScrapySpider{
+		{Name: "quotes", ClassName: "QuotesSpider"},
+		{Name: "books", ClassName: "BooksSpider"},
+	}, parseSpiders(content))
+
+	require.Equal(t, []string{"TutorialPipeline"}, parsePipelineClasses(`class TutorialPipeline:
+    def process_item(self, item, spider):
+        return item
+
+
+class Helper:
+    pass
+`))
+}
+
+func TestGetSettingsModule(t *testing.T) {
+	require.Equal(t, "tutorial.settings", getSettingsModule("# cfg\n[settings]\ndefault = tutorial.settings\n\n[deploy]\nproject = tutorial\n"))
+	require.Equal(t, "", getSettingsModule("[deploy]\ndefault = tutorial.settings\n"))
+	require.Equal(t, "MySpider", getCamelCase("my_spider"))
+	require.Equal(t, "example.com", getDomain("https://example.com/path"))
+	require.Equal(t, "example.com", getDomain("example.com/path"))
+}
@@ -0,0 +1,83 @@
+package scrapy
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+)
+
+// project templates as of "scrapy startproject", of which $project is
+// replaced by the project name
+var projectTemplate = map[string]string{
+	"scrapy.cfg": `[settings]
+default = $project.settings
+
+[deploy]
+project = $project
+`,
+	"$project/__init__.py": ``,
+	"$project/items.py": `# Define here the models for your scraped items
+
+import scrapy
+
+
+class ${Project}Item(scrapy.Item):
+    # define the fields for your item here like:
+    # name = scrapy.Field()
+    pass
+`,
+	"$project/pipelines.py": `# Define your item pipelines here
+#
+# Don't forget to add your pipeline to the ITEM_PIPELINES setting
+
+
+class ${Project}Pipeline:
+    def process_item(self, item, spider):
+        return item
+`,
+	"$project/settings.py": `# Scrapy settings for $project project
+
+BOT_NAME = "$project"
+
+SPIDER_MODULES = ["$project.spiders"]
+NEWSPIDER_MODULE = "$project.spiders"
+
+ROBOTSTXT_OBEY = True
+
+ITEM_PIPELINES = {
+    "crawlab.CrawlabPipeline": 888,
+}
+`,
+	"$project/spiders/__init__.py": `# This package will contain the spiders of your Scrapy project
+`,
+}
+
+// spider templates as of "scrapy genspider", of which $name, $classname,
+// $domain and $url are replaced
+var spiderTemplates = map[string]string{
+	constants.ScrapySpiderTemplateBasic: `import scrapy
+
+
+class $classname(scrapy.Spider):
+    name = "$name"
+    allowed_domains = ["$domain"]
+    start_urls = ["$url"]
+
+    def parse(self, response):
+        pass
+`,
+	constants.ScrapySpiderTemplateCrawl: `import scrapy
+from scrapy.linkextractors import LinkExtractor
+from scrapy.spiders import CrawlSpider, Rule
+
+
+class $classname(CrawlSpider):
+    name = "$name"
+    allowed_domains = ["$domain"]
+    start_urls = ["$url"]
+
+    rules = (Rule(LinkExtractor(allow=r"Items/"), callback="parse_item", follow=True),)
+
+    def parse_item(self, response):
+        item = {}
+        return item
+`,
+}
@@ -8,8 +8,11 @@ import (
 	"github.com/crawlab-team/go-trace"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/dig"
+	"sync"
 )
 
+var locks sync.Map
+
 type Service struct {
 	// dependencies
 	nodeCfgSvc interfaces.NodeConfigService
@@ -73,6 +76,13 @@ func (svc *Service) SyncToWorkspace(id primitive.ObjectID) (err error) {
 	return nil
 }
 
+// GetLock lock which serializes syncs, commits and other operations on the
+// files and repo of the spider, shared by all services in the process
+func (svc *Service) GetLock(id primitive.ObjectID) (mu *sync.Mutex) {
+	res, _ := locks.LoadOrStore(id, &sync.Mutex{})
+	return res.(*sync.Mutex)
+}
+
 func (svc *Service) getOptions() (opts []fs.Option) {
 	if svc.cfgPath != "" {
 		opts = append(opts, fs.WithConfigPath(svc.cfgPath))
@@ -37,7 +37,7 @@ func KillProcessWithTimeout(cmd *exec.Cmd, timeout time.Duration) error {
 		return nil
 	}
 	go func() {
-		if err := syscall.Kill(cmd.Process.Pid, syscall.SIGTERM); err != nil {
+		if err := syscall.Kill(getKillPid(cmd), syscall.SIGTERM); err != nil {
 			trace.PrintError(err)
 		}
 	}()
@@ -52,5 +52,14 @@ func KillProcessWithTimeout(cmd *exec.Cmd, timeout time.Duration) error {
 }
 
 func ForceKillProcess(cmd *exec.Cmd) error {
-	return syscall.Kill(cmd.Process.Pid, syscall.SIGKILL)
+	return syscall.Kill(getKillPid(cmd), syscall.SIGKILL)
+}
+
+// getKillPid get pid to send kill signals to, which is the negative pid
+// (the whole process group) if the process was started with Setpgid
+func getKillPid(cmd *exec.Cmd) (pid int) {
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		return -cmd.Process.Pid
+	}
+	return cmd.Process.Pid
 }
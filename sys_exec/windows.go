@@ -4,6 +4,7 @@ package sys_exec
 
 import (
 	"os/exec"
+	"time"
 )
 
 func BuildCmd(cmdStr string) *exec.Cmd {
@@ -22,3 +23,7 @@ func KillProcess(cmd *exec.Cmd) error {
 	}
 	return nil
 }
+
+func KillProcessWithTimeout(cmd *exec.Cmd, timeout time.Duration) error {
+	return KillProcess(cmd)
+}
@@ -38,3 +38,15 @@ func WithSubscribeTimeout(timeout time.Duration) RunnerOption {
 		r.SetSubscribeTimeout(timeout)
 	}
 }
+
+func WithLongTaskMaxErrors(maxErrors int) RunnerOption {
+	return func(r interfaces.TaskRunner) {
+		r.SetLongTaskMaxErrors(maxErrors)
+	}
+}
+
+func WithLongTaskBackoffMaxInterval(interval time.Duration) RunnerOption {
+	return func(r interfaces.TaskRunner) {
+		r.SetLongTaskBackoffMaxInterval(interval)
+	}
+}
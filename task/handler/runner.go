@@ -26,9 +26,11 @@ import (
 	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/dig"
 	"os"
 	"os/exec"
+	"sync"
 	"time"
 )
 
@@ -38,21 +40,33 @@ type Runner struct {
 	fsSvc interfaces.SpiderFsService    // spider fs service
 
 	// settings
-	logDriverType    string
-	subscribeTimeout time.Duration
+	logDriverType              string
+	subscribeTimeout           time.Duration
+	longTaskMaxErrors          int           // max errors (exits) of long tasks before giving up restarting
+	longTaskBackoffMaxInterval time.Duration // max interval between restarts of long tasks
 
 	// internals
-	cmd  *exec.Cmd                        // process command instance
-	pid  int                              // process id
-	tid  primitive.ObjectID               // task id
-	t    interfaces.Task                  // task model.Task
-	s    interfaces.Spider                // spider model.Spider
-	ch   chan constants.TaskSignal        // channel to communicate between Service and Runner
-	err  error                            // standard process error
-	envs []models.Env                     // environment variables
-	cwd  string                           // working directory
-	c    interfaces.GrpcClient            // grpc client
-	sub  grpc.TaskService_SubscribeClient // grpc task service stream client
+	cmd     *exec.Cmd                        // process command instance
+	pid     int                              // process id
+	tid     primitive.ObjectID               // task id
+	t       interfaces.Task                  // task model.Task
+	s       interfaces.Spider                // spider model.Spider
+	ch      chan constants.TaskSignal        // channel to communicate between Service and Runner
+	err     error                            // standard process error
+	envs    []models.Env                     // environment variables
+	secrets []string                         // secret values to be masked in logs
+	cwd     string                           // working directory
+	c       interfaces.GrpcClient            // grpc client
+	sub     grpc.TaskService_SubscribeClient // grpc task service stream client
+	stopped bool                             // whether the process is stopped by cancelling or timeout, which prevents long tasks from restarting
+	mu      sync.Mutex                       // lock of cmd and stopped, which are accessed by supervise of long tasks
+	done    chan struct{}                    // closed once the signal is received by Run, after which signals are dropped
+	stopCh  chan struct{}                    // closed once stopped, which interrupts the backoff of restarting long tasks
+
+	// process hooks of supervise, which are replaced in tests
+	waitFn         func() (err error)                 // wait for the current process to exit
+	restartFn      func() (err error)                 // restart the process
+	restartCountFn func(restartCount int) (err error) // save the restart count of the task
 
 	// log internals
 	scannerStdout *bufio.Scanner
@@ -72,6 +86,10 @@ func (r *Runner) Init() (err error) {
 
 	// working directory
 	r.cwd = r.fsSvc.GetWorkspacePath()
+	if r.t.GetIsVersionPinned() {
+		// files of the version the task is pinned to
+		r.cwd = r.fsSvc.GetVersionWorkspacePath(r.t.GetVersion())
+	}
 
 	// sync files to workspace
 	if err := r.syncFiles(); err != nil {
@@ -95,46 +113,36 @@ func (r *Runner) Run() (err error) {
 	// log task started
 	log.Infof("task[%s] started", r.tid.Hex())
 
-	// configure cmd
-	if err := r.configureCmd(); err != nil {
-		return err
-	}
+	if r.s.GetIsLongTask() {
+		// run long task under supervision with auto-restart
+		if err := r.restartProcess(); err != nil {
+			return err
+		}
+		go r.supervise()
+	} else {
+		// start process
+		if err := r.restartProcess(); err != nil {
+			return err
+		}
 
-	// configure environment variables
-	if err := r.configureEnv(); err != nil {
-		return err
-	}
+		// wait for process to finish
+		go r.wait()
 
-	// configure logging
-	if err := r.configureLogging(); err != nil {
-		return err
+		// start health check
+		go r.startHealthCheck()
 	}
 
-	// start process
-	if err := r.cmd.Start(); err != nil {
-		return err
+	// start timeout watch
+	if r.t.GetTimeout() > 0 {
+		go r.startTimeoutWatch(time.Duration(r.t.GetTimeout()) * time.Second)
 	}
 
-	// start logging
-	go r.startLogging()
-
-	// process id
-	if r.cmd.Process == nil {
-		return constants.ErrNotExists
-	}
-	r.pid = r.cmd.Process.Pid
-
-	// wait for process to finish
-	go r.wait()
-
-	// start health check
-	go r.startHealthCheck()
-
 	// declare task status
 	status := ""
 
 	// wait for signal
 	signal := <-r.ch
+	close(r.done)
 	switch signal {
 	case constants.TaskSignalFinish:
 		err = nil
@@ -148,6 +156,13 @@ func (r *Runner) Run() (err error) {
 	case constants.TaskSignalLost:
 		err = constants.ErrTaskLost
 		status = constants.TaskStatusError
+	case constants.TaskSignalTimeout:
+		err = constants.ErrTaskTimeout
+		status = constants.TaskStatusTimeout
+		log.Warnf("task[%s] timeout after %d seconds, killing process", r.tid.Hex(), r.t.GetTimeout())
+		if err := sys_exec.KillProcessWithTimeout(r.stop(), r.svc.GetExitWatchDuration()); err != nil {
+			trace.PrintError(err)
+		}
 	default:
 		err = constants.ErrInvalidSignal
 		status = constants.TaskStatusError
@@ -170,14 +185,20 @@ func (r *Runner) Run() (err error) {
 }
 
 func (r *Runner) Cancel() (err error) {
+	// stop restarting long task
+	cmd := r.stop()
+
 	// kill process
-	if err := sys_exec.KillProcess(r.cmd); err != nil {
+	if err := sys_exec.KillProcess(cmd); err != nil {
 		return err
 	}
+	if cmd == nil || cmd.Process == nil {
+		return nil
+	}
 
 	// make sure the process does not exist
 	op := func() error {
-		if exists, _ := process.PidExists(int32(r.pid)); exists {
+		if exists, _ := process.PidExists(int32(cmd.Process.Pid)); exists {
 			return errors.ErrorTaskProcessStillExists
 		}
 		return nil
@@ -210,10 +231,97 @@ func (r *Runner) SetSubscribeTimeout(timeout time.Duration) {
 	r.subscribeTimeout = timeout
 }
 
+func (r *Runner) SetLongTaskMaxErrors(maxErrors int) {
+	r.longTaskMaxErrors = maxErrors
+}
+
+func (r *Runner) SetLongTaskBackoffMaxInterval(interval time.Duration) {
+	r.longTaskBackoffMaxInterval = interval
+}
+
 func (r *Runner) GetTaskId() (id primitive.ObjectID) {
 	return r.tid
 }
 
+// stop prevent the long task from restarting and return the command of the
+// current process to kill
+func (r *Runner) stop() (cmd *exec.Cmd) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.stopped {
+		r.stopped = true
+		close(r.stopCh)
+	}
+	return r.cmd
+}
+
+func (r *Runner) isStopped() (ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopped
+}
+
+// getCmd command of the current process, which is replaced on restarts
+func (r *Runner) getCmd() (cmd *exec.Cmd) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cmd
+}
+
+// restartProcess start the process unless stopped, which is checked in the
+// same lock so that a process started before stopping is killed by Cancel
+func (r *Runner) restartProcess() (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return constants.ErrTaskCancelled
+	}
+	return r.startProcess()
+}
+
+// sendSignal send the task signal to Run, which is dropped if Run has
+// already received one, e.g. exits of processes killed on timeout
+func (r *Runner) sendSignal(signal constants.TaskSignal) {
+	select {
+	case r.ch <- signal:
+	case <-r.done:
+	}
+}
+
+// startProcess configure and start the process of the task
+func (r *Runner) startProcess() (err error) {
+	// configure cmd
+	if err := r.configureCmd(); err != nil {
+		return err
+	}
+
+	// configure environment variables
+	if err := r.configureEnv(); err != nil {
+		return err
+	}
+
+	// configure logging
+	if err := r.configureLogging(); err != nil {
+		return err
+	}
+
+	// start process
+	if err := r.cmd.Start(); err != nil {
+		return err
+	}
+
+	// start logging
+	go r.startLogging()
+
+	// process id
+	if r.cmd.Process == nil {
+		return constants.ErrNotExists
+	}
+	r.pid = r.cmd.Process.Pid
+
+	return nil
+}
+
 func (r *Runner) configureCmd() (err error) {
 	var cmdStr string
 	if r.t.GetType() == constants.TaskTypeSpider || r.t.GetType() == "" {
@@ -292,7 +400,7 @@ func (r *Runner) startLoggingReaderStdout() {
 	for r.scannerStdout.Scan() {
 		line := r.scannerStdout.Text()
 		utils.LogDebug(fmt.Sprintf("scannerStdout line: %s", line))
-		r.writeLogLine(line)
+		r.writeLogLine(line, constants.LogStreamStdout)
 	}
 	// reach end
 	utils.LogDebug("scannerStdout reached end")
@@ -303,7 +411,7 @@ func (r *Runner) startLoggingReaderStderr() {
 	for r.scannerStderr.Scan() {
 		line := r.scannerStderr.Text()
 		utils.LogDebug(fmt.Sprintf("scannerStderr line: %s", line))
-		r.writeLogLine(line)
+		r.writeLogLine(line, constants.LogStreamStderr)
 	}
 	// reach end
 	utils.LogDebug("scannerStderr reached end")
@@ -314,13 +422,26 @@ func (r *Runner) startHealthCheck() {
 		exists, _ := process.PidExists(int32(r.pid))
 		if !exists {
 			// process lost
-			r.ch <- constants.TaskSignalLost
+			r.sendSignal(constants.TaskSignalLost)
 			return
 		}
 		time.Sleep(1 * time.Second)
 	}
 }
 
+// startTimeoutWatch send timeout signal to task runner's channel (Runner.ch)
+// after the given timeout, which is cancelled once Run has received a signal,
+// e.g. the process has exited
+func (r *Runner) startTimeoutWatch(timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		r.sendSignal(constants.TaskSignalTimeout)
+	case <-r.done:
+	}
+}
+
 func (r *Runner) configureEnv() (err error) {
 	// TODO: refactor
 	//envs := r.s.Envs
@@ -350,8 +471,43 @@ func (r *Runner) configureEnv() (err error) {
 	// default results collection
 	//col := utils.GetSpiderCol(r.s.Col, r.s.Name)
 
+	// environment variables are appended from the lowest to the highest precedence
+	// (node os envs < global variables < spider envs < task envs), as only the last
+	// value of a duplicated key takes effect in exec.Cmd.Env
+	r.cmd.Env = os.Environ()
+	r.envs = nil
+	r.secrets = nil
+
+	// global environment variables
+	variables, err := r.getVariables()
+	if err != nil {
+		return err
+	}
+	for _, v := range variables {
+		r.cmd.Env = append(r.cmd.Env, v.Key+"="+v.Value)
+		if v.Secret && v.Value != "" {
+			r.secrets = append(r.secrets, v.Value)
+		}
+	}
+
+	// spider environment variables
+	if s, ok := r.s.(*models.Spider); ok {
+		r.envs = append(r.envs, s.Envs...)
+	}
+
+	// task environment variables
+	if t, ok := r.t.(*models.Task); ok {
+		r.envs = append(r.envs, t.Envs...)
+	}
+	for _, env := range r.envs {
+		r.cmd.Env = append(r.cmd.Env, env.Name+"="+env.Value)
+		if env.Secret && env.Value != "" {
+			r.secrets = append(r.secrets, env.Value)
+		}
+	}
+
 	// default envs
-	r.cmd.Env = append(os.Environ(), "CRAWLAB_TASK_ID="+r.tid.Hex())
+	r.cmd.Env = append(r.cmd.Env, "CRAWLAB_TASK_ID="+r.tid.Hex())
 	if viper.GetString("grpc.address") != "" {
 		r.cmd.Env = append(r.cmd.Env, "CRAWLAB_GRPC_ADDRESS="+viper.GetString("grpc.address"))
 	}
@@ -386,47 +542,151 @@ func (r *Runner) configureEnv() (err error) {
 	//	r.cmd.Env = append(r.cmd.Env, "CRAWLAB_IS_DEDUP=0")
 	//}
 
-	// TODO: implement task environment variables
-	//for _, env := range r.s.Envs {
-	//	r.cmd.Env = append(r.cmd.Env, env.Name+"="+env.Value)
-	//}
-
-	// TODO: implement global environment variables
-	//variables, err := models.MustGetRootService().GetVariableList(nil, nil)
-	//if err != nil {
-	//	return err
-	//}
-	//for _, variable := range variables {
-	//	r.cmd.Env = append(r.cmd.Env, variable.Key+"="+variable.Value)
-	//}
 	return nil
 }
 
+// getVariables get global variables (models.Variable) from master
+func (r *Runner) getVariables() (variables []models.Variable, err error) {
+	if r.svc.GetNodeConfigService().IsMaster() {
+		if err := mongo.GetMongoCol(interfaces.ModelColNameVariable).Find(nil, nil).All(&variables); err != nil {
+			if err == mongo2.ErrNoDocuments {
+				return nil, nil
+			}
+			return nil, trace.TraceError(err)
+		}
+		return variables, nil
+	}
+
+	modelSvc, err := client.NewBaseServiceDelegate(
+		client.WithBaseServiceModelId(interfaces.ModelIdVariable),
+		client.WithBaseServiceConfigPath(r.svc.GetConfigPath()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	list, err := modelSvc.GetList(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range list.Values() {
+		v, ok := item.(models.Variable)
+		if !ok {
+			return nil, trace.TraceError(errors.ErrorModelInvalidType)
+		}
+		variables = append(variables, v)
+	}
+	return variables, nil
+}
+
 // wait for process to finish and send task signal (constants.TaskSignal)
 // to task runner's channel (Runner.ch) according to exit code
 func (r *Runner) wait() {
 	// wait for process to finish
-	if err := r.cmd.Wait(); err != nil {
+	if err := r.getCmd().Wait(); err != nil {
 		exitError, ok := err.(*exec.ExitError)
 		if !ok {
-			r.ch <- constants.TaskSignalError
+			r.sendSignal(constants.TaskSignalError)
 			return
 		}
 		exitCode := exitError.ExitCode()
 		if exitCode == -1 {
 			// cancel error
-			r.ch <- constants.TaskSignalCancel
+			r.sendSignal(constants.TaskSignalCancel)
 			return
 		}
 
 		// standard error
 		r.err = err
-		r.ch <- constants.TaskSignalError
+		r.sendSignal(constants.TaskSignalError)
 		return
 	}
 
 	// success
-	r.ch <- constants.TaskSignalFinish
+	r.sendSignal(constants.TaskSignalFinish)
+}
+
+// supervise wait for the process of the long task to exit and restart it with
+// exponential backoff, similar to process.Daemon, until the task is cancelled
+// or the process exits with errors too many times. Exits with code 0 are not
+// counted as errors, and the error count is reset if the process has run
+// longer than the max backoff interval.
+func (r *Runner) supervise() {
+	b := backoff.NewExponentialBackOff()
+	b.MaxInterval = r.longTaskBackoffMaxInterval
+	if b.InitialInterval > b.MaxInterval {
+		b.InitialInterval = b.MaxInterval
+	}
+	b.MaxElapsedTime = 0
+	b.Reset()
+	errCount := 0
+	for {
+		// wait for process to exit
+		startTs := time.Now()
+		err := r.waitFn()
+
+		// stopped by cancelling or timeout
+		if r.isStopped() {
+			r.sendSignal(constants.TaskSignalCancel)
+			return
+		}
+
+		// increment errors
+		if time.Since(startTs) >= r.longTaskBackoffMaxInterval {
+			errCount = 0
+			b.Reset()
+		}
+		if err != nil {
+			errCount++
+			r.err = err
+		}
+
+		// validate if error count exceeds max errors
+		if errCount >= r.longTaskMaxErrors {
+			r.writeSystemLogLine(fmt.Sprintf("long task process exited with errors %d times in a row, stopped restarting", errCount))
+			r.err = errors.ErrorTaskReachedMaxErrors
+			r.sendSignal(constants.TaskSignalError)
+			return
+		}
+
+		// restart event
+		waitDuration := b.NextBackOff()
+		restartCount := r.t.GetRestartCount() + 1
+		exitReason := "exit code 0"
+		if err != nil {
+			exitReason = err.Error()
+		}
+		r.writeSystemLogLine(fmt.Sprintf("long task process exited (%s), restarting in %s (restart #%d)", exitReason, waitDuration.Round(time.Second), restartCount))
+		if err := r.restartCountFn(restartCount); err != nil {
+			trace.PrintError(err)
+		}
+
+		// re-attempt unless stopped during the backoff
+		select {
+		case <-time.After(waitDuration):
+		case <-r.stopCh:
+			r.sendSignal(constants.TaskSignalCancel)
+			return
+		}
+		if err := r.restartFn(); err != nil {
+			if err == constants.ErrTaskCancelled {
+				r.sendSignal(constants.TaskSignalCancel)
+				return
+			}
+			r.err = err
+			r.sendSignal(constants.TaskSignalError)
+			return
+		}
+		log.Infof("task[%s] long task process restarted (restart #%d)", r.tid.Hex(), restartCount)
+	}
+}
+
+// updateTaskRestartCount update restart count of the long task
+func (r *Runner) updateTaskRestartCount(restartCount int) (err error) {
+	r.t.SetRestartCount(restartCount)
+	if r.svc.GetNodeConfigService().IsMaster() {
+		return delegate.NewModelDelegate(r.t).Save()
+	}
+	return client.NewModelDelegate(r.t, client.WithDelegateConfigPath(r.svc.GetConfigPath())).Save()
 }
 
 // updateTask update and get updated info of task (Runner.t)
@@ -472,6 +732,9 @@ func (r *Runner) syncFiles() (err error) {
 	// lock files sync
 	r.svc.LockSync(r.s.GetId())
 	defer r.svc.UnlockSync(r.s.GetId())
+	if r.t.GetIsVersionPinned() {
+		return r.fsSvc.SyncVersionToWorkspace(r.t.GetVersion())
+	}
 	if err := r.fsSvc.GetFsService().SyncToWorkspace(); err != nil {
 		return err
 	}
@@ -487,10 +750,21 @@ func (r *Runner) initSub() (err error) {
 	return nil
 }
 
-func (r *Runner) writeLogLine(line string) {
+func (r *Runner) writeLogLine(line, stream string) {
+	r.sendLogLine(utils.NewTaskLogLine(utils.MaskSecrets(line, r.secrets), stream))
+}
+
+// writeSystemLogLine write a warning line of crawlab (e.g. restart events) to the task logs
+func (r *Runner) writeSystemLogLine(line string) {
+	l := utils.NewTaskLogLine(line, constants.LogStreamSystem)
+	l.Level = constants.LogLevelWarning
+	r.sendLogLine(l)
+}
+
+func (r *Runner) sendLogLine(l interfaces.TaskLogLine) {
 	data, err := json.Marshal(&entity.StreamMessageTaskData{
-		TaskId: r.tid,
-		Logs:   []string{line},
+		TaskId:   r.tid,
+		LogLines: []interfaces.TaskLogLine{l},
 	})
 	if err != nil {
 		trace.PrintError(err)
@@ -518,10 +792,11 @@ func (r *Runner) _updateTaskStat(status string) {
 	case constants.TaskStatusRunning:
 		ts.SetStartTs(time.Now())
 		ts.SetWaitDuration(ts.GetStartTs().Sub(ts.GetCreateTs()).Milliseconds())
-	case constants.TaskStatusFinished, constants.TaskStatusError, constants.TaskStatusCancelled:
+	case constants.TaskStatusFinished, constants.TaskStatusError, constants.TaskStatusCancelled, constants.TaskStatusTimeout:
 		ts.SetEndTs(time.Now())
 		ts.SetRuntimeDuration(ts.GetEndTs().Sub(ts.GetStartTs()).Milliseconds())
 		ts.SetTotalDuration(ts.GetEndTs().Sub(ts.GetCreateTs()).Milliseconds())
+		ts.SetTimedOut(status == constants.TaskStatusTimeout)
 	}
 	if r.svc.GetNodeConfigService().IsMaster() {
 		if err := delegate.NewModelDelegate(ts).Save(); err != nil {
@@ -557,7 +832,7 @@ func (r *Runner) _updateSpiderStat(status string) {
 				"wait_duration": ts.GetWaitDuration(), // wait duration
 			},
 		}
-	case constants.TaskStatusFinished, constants.TaskStatusError, constants.TaskStatusCancelled:
+	case constants.TaskStatusFinished, constants.TaskStatusError, constants.TaskStatusCancelled, constants.TaskStatusTimeout:
 		update = bson.M{
 			"$inc": bson.M{
 				"results":          ts.GetResultCount(),            // results
@@ -601,11 +876,28 @@ func NewTaskRunner(id primitive.ObjectID, svc interfaces.TaskHandlerService, opt
 
 	// runner
 	r := &Runner{
-		logDriverType:    clog.DriverTypeFs,
-		subscribeTimeout: 30 * time.Second,
-		svc:              svc,
-		tid:              id,
-		ch:               make(chan constants.TaskSignal),
+		logDriverType:              clog.DriverTypeFs,
+		subscribeTimeout:           30 * time.Second,
+		longTaskMaxErrors:          constants.DefaultLongTaskMaxErrors,
+		longTaskBackoffMaxInterval: constants.DefaultLongTaskBackoffMaxInterval * time.Second,
+		svc:                        svc,
+		tid:                        id,
+		ch:                         make(chan constants.TaskSignal),
+		done:                       make(chan struct{}),
+		stopCh:                     make(chan struct{}),
+	}
+	r.waitFn = func() (err error) {
+		return r.getCmd().Wait()
+	}
+	r.restartFn = r.restartProcess
+	r.restartCountFn = r.updateTaskRestartCount
+
+	// long task settings
+	if maxErrors := viper.GetInt("task.longTask.maxErrors"); maxErrors > 0 {
+		r.longTaskMaxErrors = maxErrors
+	}
+	if maxInterval := viper.GetInt("task.longTask.backoffMaxInterval"); maxInterval > 0 {
+		r.longTaskBackoffMaxInterval = time.Duration(maxInterval) * time.Second
 	}
 
 	// apply options
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"github.com/luke513009828/crawlab-core/constants"
+	errors2 "github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/models/models"
+	grpc "github.com/crawlab-team/crawlab-grpc"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSubscribeClient task service stream client recording sent log messages
+type testSubscribeClient struct {
+	grpc.TaskService_SubscribeClient
+	mu   sync.Mutex
+	msgs []*grpc.StreamMessage
+}
+
+func (c *testSubscribeClient) Send(msg *grpc.StreamMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.msgs = append(c.msgs, msg)
+	return nil
+}
+
+// testProcess fake process of a long task, of which exits are given by errs
+// in turn and the last one is repeated
+type testProcess struct {
+	mu       sync.Mutex
+	errs     []error
+	waits    int
+	restarts int
+}
+
+func (p *testProcess) wait() (err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i := p.waits
+	if i >= len(p.errs) {
+		i = len(p.errs) - 1
+	}
+	p.waits++
+	return p.errs[i]
+}
+
+func (p *testProcess) restart() (err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.restarts++
+	return nil
+}
+
+func (p *testProcess) getRestarts() (n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.restarts
+}
+
+func newTestRunner(p *testProcess, maxErrors int, maxInterval time.Duration) (r *Runner) {
+	r = &Runner{
+		longTaskMaxErrors:          maxErrors,
+		longTaskBackoffMaxInterval: maxInterval,
+		tid:                        primitive.NewObjectID(),
+		t:                          &models.Task{},
+		sub:                        &testSubscribeClient{},
+		ch:                         make(chan constants.TaskSignal),
+		done:                       make(chan struct{}),
+		stopCh:                     make(chan struct{}),
+		waitFn:                     p.wait,
+		restartFn:                  p.restart,
+	}
+	r.restartCountFn = func(restartCount int) (err error) {
+		r.t.SetRestartCount(restartCount)
+		return nil
+	}
+	return r
+}
+
+func receiveSignal(t *testing.T, r *Runner) (signal constants.TaskSignal) {
+	select {
+	case signal = <-r.ch:
+		close(r.done)
+		return signal
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no signal received")
+		return signal
+	}
+}
+
+func TestRunner_SuperviseMaxErrors(t *testing.T) {
+	p := &testProcess{errs: []error{errors.New("exit status 1")}}
+	r := newTestRunner(p, 3, 50*time.Millisecond)
+	go r.supervise()
+
+	require.Equal(t, constants.TaskSignalError, receiveSignal(t, r))
+	require.Equal(t, errors2.ErrorTaskReachedMaxErrors, r.err)
+	require.Equal(t, 3, p.waits)
+	require.Equal(t, 2, p.getRestarts())
+	require.Equal(t, 2, r.t.GetRestartCount())
+}
+
+func TestRunner_SuperviseRestartOnZeroExits(t *testing.T) {
+	// zero exits are not counted towards max errors
+	p := &testProcess{errs: []error{nil, errors.New("exit status 1"), nil, nil, nil, errors.New("exit status 1")}}
+	r := newTestRunner(p, 2, 50*time.Millisecond)
+	go r.supervise()
+
+	require.Equal(t, constants.TaskSignalError, receiveSignal(t, r))
+	require.Equal(t, 6, p.waits)
+	require.Equal(t, 5, p.getRestarts())
+	require.Equal(t, 5, r.t.GetRestartCount())
+}
+
+func TestRunner_SuperviseCancelDuringBackoff(t *testing.T) {
+	p := &testProcess{errs: []error{errors.New("exit status 1")}}
+	r := newTestRunner(p, 10, time.Minute)
+	go r.supervise()
+
+	// cancel while waiting to restart
+	time.Sleep(100 * time.Millisecond)
+	r.stop()
+
+	require.Equal(t, constants.TaskSignalCancel, receiveSignal(t, r))
+	require.Equal(t, 0, p.getRestarts())
+}
+
+func TestRunner_SuperviseTimeoutDuringBackoff(t *testing.T) {
+	p := &testProcess{errs: []error{errors.New("exit status 1")}}
+	r := newTestRunner(p, 10, time.Minute)
+	finished := make(chan struct{})
+	go func() {
+		r.supervise()
+		close(finished)
+	}()
+
+	// timeout received by Run while waiting to restart
+	time.Sleep(100 * time.Millisecond)
+	close(r.done)
+	r.stop()
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "supervise not finished")
+	}
+	require.Equal(t, 0, p.getRestarts())
+}
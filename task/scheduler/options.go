@@ -18,3 +18,21 @@ func WithInterval(interval time.Duration) Option {
 		svc.SetInterval(interval)
 	}
 }
+
+func WithBatchSize(size int) Option {
+	return func(svc interfaces.TaskSchedulerService) {
+		svc.SetBatchSize(size)
+	}
+}
+
+func WithMaxRunningPerSpider(max int) Option {
+	return func(svc interfaces.TaskSchedulerService) {
+		svc.SetMaxRunningPerSpider(max)
+	}
+}
+
+func WithMaxRunningPerUser(max int) Option {
+	return func(svc interfaces.TaskSchedulerService) {
+		svc.SetMaxRunningPerUser(max)
+	}
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"bytes"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+)
+
+// getFairShareTaskQueueItems select task queue items to dequeue from the given
+// items sorted by priority. Items of the same priority are interleaved across
+// spiders in a round-robin manner so that a spider with a large number of queued
+// tasks does not starve others, and items of spiders or users whose running tasks
+// reach the caps (0 means no cap) are left in the queue. Caps of spiders in
+// spiderCaps (Spider.MaxConcurrency) take precedence over maxPerSpider if lower.
+func getFairShareTaskQueueItems(tqList []models.TaskQueueItem, spiderCounts, userCounts, spiderCaps map[primitive.ObjectID]int, maxPerSpider, maxPerUser int) (res []models.TaskQueueItem) {
+	// copy counts to avoid modifying the given maps
+	sc := map[primitive.ObjectID]int{}
+	for id, c := range spiderCounts {
+		sc[id] = c
+	}
+	uc := map[primitive.ObjectID]int{}
+	for id, c := range userCounts {
+		uc[id] = c
+	}
+
+	res = make([]models.TaskQueueItem, 0, len(tqList))
+	for i := 0; i < len(tqList); {
+		// items of the same priority
+		j := i
+		for j < len(tqList) && tqList[j].Priority == tqList[i].Priority {
+			j++
+		}
+
+		// group items by spider in order of first appearance
+		var spiderIds []primitive.ObjectID
+		groups := map[primitive.ObjectID][]models.TaskQueueItem{}
+		for _, tq := range tqList[i:j] {
+			if _, ok := groups[tq.SpiderId]; !ok {
+				spiderIds = append(spiderIds, tq.SpiderId)
+			}
+			groups[tq.SpiderId] = append(groups[tq.SpiderId], tq)
+		}
+
+		// round-robin across spiders
+		for len(spiderIds) > 0 {
+			var nextSpiderIds []primitive.ObjectID
+			for _, spiderId := range spiderIds {
+				group := groups[spiderId]
+				tq := group[0]
+				groups[spiderId] = group[1:]
+
+				// skip the rest of the spider if it reaches the cap
+				if max := getSpiderCap(tq.SpiderId, spiderCaps, maxPerSpider); max > 0 && !tq.SpiderId.IsZero() && sc[tq.SpiderId] >= max {
+					continue
+				}
+
+				// skip the item if its user reaches the cap
+				if maxPerUser > 0 && !tq.UserId.IsZero() && uc[tq.UserId] >= maxPerUser {
+					if len(groups[spiderId]) > 0 {
+						nextSpiderIds = append(nextSpiderIds, spiderId)
+					}
+					continue
+				}
+
+				res = append(res, tq)
+				sc[tq.SpiderId]++
+				uc[tq.UserId]++
+
+				if len(groups[spiderId]) > 0 {
+					nextSpiderIds = append(nextSpiderIds, spiderId)
+				}
+			}
+			spiderIds = nextSpiderIds
+		}
+
+		i = j
+	}
+
+	return res
+}
+
+// getSpiderCap get the max number of running tasks of the spider, 0 means no cap
+func getSpiderCap(id primitive.ObjectID, spiderCaps map[primitive.ObjectID]int, maxPerSpider int) (max int) {
+	c := spiderCaps[id]
+	if c > 0 && (maxPerSpider <= 0 || c < maxPerSpider) {
+		return c
+	}
+	return maxPerSpider
+}
+
+// getResourcesList get runner slots of the given nodes, interleaved across nodes
+// and starting from nodes with the most available runners, so that tasks are
+// spread evenly over nodes
+func getResourcesList(nodes []models.Node) (resourcesList []models.Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].AvailableRunners != nodes[j].AvailableRunners {
+			return nodes[i].AvailableRunners > nodes[j].AvailableRunners
+		}
+		return bytes.Compare(nodes[i].Id[:], nodes[j].Id[:]) < 0
+	})
+	for i := 0; len(nodes) > 0 && i < nodes[0].AvailableRunners; i++ {
+		for _, n := range nodes {
+			if i < n.AvailableRunners {
+				resourcesList = append(resourcesList, n)
+			}
+		}
+	}
+	return resourcesList
+}
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func newTestTaskQueueItems(n, spiders, users int) (tqList []models.TaskQueueItem) {
+	spiderIds := make([]primitive.ObjectID, spiders)
+	for i := range spiderIds {
+		spiderIds[i] = primitive.NewObjectID()
+	}
+	userIds := make([]primitive.ObjectID, users)
+	for i := range userIds {
+		userIds[i] = primitive.NewObjectID()
+	}
+	for i := 0; i < n; i++ {
+		tqList = append(tqList, models.TaskQueueItem{
+			Id:       primitive.NewObjectID(),
+			Priority: 5,
+			SpiderId: spiderIds[i%spiders],
+			UserId:   userIds[i%users],
+		})
+	}
+	return tqList
+}
+
+func TestGetFairShareTaskQueueItems(t *testing.T) {
+	a, b := primitive.NewObjectID(), primitive.NewObjectID()
+	u := primitive.NewObjectID()
+	tqList := []models.TaskQueueItem{
+		{Id: primitive.NewObjectID(), Priority: 1, SpiderId: b},
+		{Id: primitive.NewObjectID(), Priority: 5, SpiderId: a},
+		{Id: primitive.NewObjectID(), Priority: 5, SpiderId: a},
+		{Id: primitive.NewObjectID(), Priority: 5, SpiderId: a},
+		{Id: primitive.NewObjectID(), Priority: 5, SpiderId: b, UserId: u},
+		{Id: primitive.NewObjectID(), Priority: 5, SpiderId: b, UserId: u},
+	}
+
+	// interleaved across spiders within the same priority
+	res := getFairShareTaskQueueItems(tqList, nil, nil, nil, 0, 0)
+	require.Len(t, res, 6)
+	require.Equal(t, tqList[0].Id, res[0].Id)
+	require.Equal(t, tqList[1].Id, res[1].Id)
+	require.Equal(t, tqList[4].Id, res[2].Id)
+	require.Equal(t, tqList[2].Id, res[3].Id)
+	require.Equal(t, tqList[5].Id, res[4].Id)
+	require.Equal(t, tqList[3].Id, res[5].Id)
+
+	// capped by spider, where spider a already has 1 running task
+	res = getFairShareTaskQueueItems(tqList, map[primitive.ObjectID]int{a: 1}, nil, nil, 2, 0)
+	require.Len(t, res, 3)
+	require.Equal(t, tqList[0].Id, res[0].Id)
+	require.Equal(t, tqList[1].Id, res[1].Id)
+	require.Equal(t, tqList[4].Id, res[2].Id)
+
+	// capped by Spider.MaxConcurrency
+	res = getFairShareTaskQueueItems(tqList, nil, nil, map[primitive.ObjectID]int{b: 1}, 0, 0)
+	require.Len(t, res, 4)
+	require.Equal(t, tqList[0].Id, res[0].Id)
+	require.Equal(t, tqList[1].Id, res[1].Id)
+	require.Equal(t, tqList[2].Id, res[2].Id)
+	require.Equal(t, tqList[3].Id, res[3].Id)
+
+	// capped by user
+	res = getFairShareTaskQueueItems(tqList, nil, map[primitive.ObjectID]int{u: 1}, nil, 0, 1)
+	require.Len(t, res, 4)
+	for _, tq := range res {
+		require.NotEqual(t, u, tq.UserId)
+	}
+}
+
+func TestGetResourcesList(t *testing.T) {
+	n1 := models.Node{Id: primitive.NewObjectID(), AvailableRunners: 1}
+	n2 := models.Node{Id: primitive.NewObjectID(), AvailableRunners: 3}
+	res := getResourcesList([]models.Node{n1, n2})
+	require.Len(t, res, 4)
+	require.Equal(t, n2.Id, res[0].Id)
+	require.Equal(t, n1.Id, res[1].Id)
+	require.Equal(t, n2.Id, res[2].Id)
+	require.Equal(t, n2.Id, res[3].Id)
+}
+
+func BenchmarkGetFairShareTaskQueueItems(b *testing.B) {
+	tqList := newTestTaskQueueItems(10000, 100, 10)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		getFairShareTaskQueueItems(tqList, nil, nil, nil, 0, 0)
+	}
+}
+
+func BenchmarkGetFairShareTaskQueueItemsWithCaps(b *testing.B) {
+	tqList := newTestTaskQueueItems(10000, 100, 10)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		getFairShareTaskQueueItems(tqList, nil, nil, nil, 5, 50)
+	}
+}
+
+func BenchmarkGetFairShareTaskQueueItemsSkewed(b *testing.B) {
+	// one spider with 10k queued tasks and 100 spiders with 1 queued task each
+	tqList := newTestTaskQueueItems(10000, 1, 1)
+	tqList = append(tqList, newTestTaskQueueItems(100, 100, 100)...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		getFairShareTaskQueueItems(tqList, nil, nil, nil, 0, 0)
+	}
+}
+
+func BenchmarkGetResourcesList(b *testing.B) {
+	var nodes []models.Node
+	for i := 0; i < 100; i++ {
+		nodes = append(nodes, models.Node{Id: primitive.NewObjectID(), AvailableRunners: i%16 + 1})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		getResourcesList(nodes)
+	}
+}
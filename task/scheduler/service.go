@@ -1,11 +1,11 @@
 package scheduler
 
 import (
-	"fmt"
 	"github.com/apex/log"
 	config2 "github.com/luke513009828/crawlab-core/config"
 	"github.com/luke513009828/crawlab-core/constants"
 	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/event"
 	"github.com/luke513009828/crawlab-core/grpc/server"
 	"github.com/luke513009828/crawlab-core/interfaces"
 	"github.com/luke513009828/crawlab-core/models/client"
@@ -25,11 +25,12 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	mongo2 "go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/dig"
-	"math/rand"
 	"sync"
 	"time"
 )
 
+const taskRetryEventKey = "task:scheduler:retry"
+
 type Service struct {
 	// dependencies
 	interfaces.TaskBaseService
@@ -39,16 +40,29 @@ type Service struct {
 	handlerSvc interfaces.TaskHandlerService
 
 	// settings
-	interval time.Duration
+	interval            time.Duration
+	batchSize           int
+	maxRunningPerSpider int
+	maxRunningPerUser   int
+
+	// internals
+	retries sync.Map // ids of failed tasks of which retries are being enqueued
 }
 
 func (svc *Service) Start() {
 	go svc.DequeueAndSchedule()
+	go svc.handleTaskRetries()
 	svc.Wait()
 	svc.Stop()
 }
 
 func (svc *Service) Enqueue(t interfaces.Task) (err error) {
+	return svc.enqueue(t, time.Time{})
+}
+
+// enqueue add the task to the task queue, from which it is not dequeued until
+// notBefore if it is set
+func (svc *Service) enqueue(t interfaces.Task, notBefore time.Time) (err error) {
 	// set task status
 	t.SetStatus(constants.TaskStatusPending)
 
@@ -65,8 +79,11 @@ func (svc *Service) Enqueue(t interfaces.Task) (err error) {
 
 	// task queue item
 	tq := &models.TaskQueueItem{
-		Id:       t.GetId(),
-		Priority: t.GetPriority(),
+		Id:        t.GetId(),
+		Priority:  t.GetPriority(),
+		SpiderId:  t.GetSpiderId(),
+		UserId:    t.GetUserId(),
+		NotBefore: notBefore,
 	}
 
 	// task stat
@@ -110,6 +127,8 @@ func (svc *Service) EnqueueWithTaskId(t interfaces.Task) (taskId primitive.Objec
 	tq := &models.TaskQueueItem{
 		Id:       t.GetId(),
 		Priority: t.GetPriority(),
+		SpiderId: t.GetSpiderId(),
+		UserId:   t.GetUserId(),
 	}
 
 	// task stat
@@ -170,8 +189,20 @@ func (svc *Service) DequeueAndSchedule() {
 }
 
 func (svc *Service) Dequeue() (tasks []interfaces.Task, err error) {
+	// get max concurrency of spiders
+	spiderCaps, err := svc.getSpiderCaps()
+	if err != nil {
+		return nil, err
+	}
+
+	// get counts of running tasks by spider and by user
+	spiderCounts, userCounts, err := svc.getRunningTaskCounts(len(spiderCaps) > 0)
+	if err != nil {
+		return nil, err
+	}
+
 	// get task queue items
-	tqList, err := svc.getTaskQueueItems()
+	tqList, err := svc.getTaskQueueItems(spiderCounts, userCounts, spiderCaps)
 	if err != nil {
 		return nil, err
 	}
@@ -179,6 +210,9 @@ func (svc *Service) Dequeue() (tasks []interfaces.Task, err error) {
 		return nil, nil
 	}
 
+	// apply fair share
+	tqList = getFairShareTaskQueueItems(tqList, spiderCounts, userCounts, spiderCaps, svc.maxRunningPerSpider, svc.maxRunningPerUser)
+
 	// match resources
 	tasks, nodesMap, err := svc.matchResources(tqList)
 	if err != nil {
@@ -267,6 +301,24 @@ func (svc *Service) Schedule(tasks []interfaces.Task) (err error) {
 
 func (svc *Service) Cancel(id primitive.ObjectID, args ...interface{}) (err error) {
 	u := utils.GetUserFromArgs(args...)
+
+	// remove from task queue if the task has not been dequeued yet
+	total, err := mongo.GetMongoCol(interfaces.ModelColNameTaskQueue).Count(bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		if err := mongo.GetMongoCol(interfaces.ModelColNameTaskQueue).DeleteId(id); err != nil {
+			return err
+		}
+		t, err := svc.modelSvc.GetTaskById(id)
+		if err != nil {
+			return err
+		}
+		t.Status = constants.TaskStatusCancelled
+		return delegate.NewModelDelegate(t, u).Save()
+	}
+
 	if svc.nodeCfgSvc.IsMaster() {
 		// cancel task on master
 		if err := svc.handlerSvc.Cancel(id); err != nil {
@@ -306,14 +358,167 @@ func (svc *Service) SetInterval(interval time.Duration) {
 	svc.interval = interval
 }
 
-func (svc *Service) getTaskQueueItems() (tqList []models.TaskQueueItem, err error) {
+func (svc *Service) SetBatchSize(size int) {
+	svc.batchSize = size
+}
+
+func (svc *Service) SetMaxRunningPerSpider(max int) {
+	svc.maxRunningPerSpider = max
+}
+
+func (svc *Service) SetMaxRunningPerUser(max int) {
+	svc.maxRunningPerUser = max
+}
+
+// handleTaskRetries subscribe to task change events and re-enqueue failed tasks
+// according to the retry policy of their schedule or spider
+func (svc *Service) handleTaskRetries() {
+	ch := make(chan interfaces.EventData)
+	eventSvc := event.NewEventService()
+	eventSvc.Register(taskRetryEventKey, "^model:"+interfaces.ModelColNameTask+":change$", "^$", &ch)
+	defer eventSvc.Unregister(taskRetryEventKey)
+
+	for {
+		if svc.IsStopped() {
+			return
+		}
+
+		ed := <-ch
+		doc, ok := ed.GetData().(*models.Task)
+		if !ok {
+			continue
+		}
+		if err := svc.retry(doc.GetId()); err != nil {
+			trace.PrintError(err)
+		}
+	}
+}
+
+func (svc *Service) retry(id primitive.ObjectID) (err error) {
+	// skip if retry of the task is being enqueued
+	if _, loaded := svc.retries.LoadOrStore(id, true); loaded {
+		return nil
+	}
+	defer svc.retries.Delete(id)
+
+	// task
+	t, err := svc.modelSvc.GetTaskById(id)
+	if err != nil {
+		return err
+	}
+
+	// retry policy
+	p, err := svc.getRetryPolicy(t)
+	if err != nil {
+		return err
+	}
+	if !p.IsRetryable(t) {
+		return nil
+	}
+
+	// original task
+	parentId := t.ParentId
+	if t.Attempt == 0 || parentId.IsZero() {
+		parentId = t.Id
+	}
+
+	// skip if the retry has already been enqueued
+	total, err := mongo.GetMongoCol(interfaces.ModelColNameTask).Count(bson.M{
+		"parent_id": parentId,
+		"attempt":   t.Attempt + 1,
+	})
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return nil
+	}
+
+	// retry task
+	rt := &models.Task{
+		SpiderId:        t.SpiderId,
+		Type:            t.Type,
+		Cmd:             t.Cmd,
+		Param:           t.Param,
+		ScheduleId:      t.ScheduleId,
+		Mode:            t.Mode,
+		NodeIds:         t.NodeIds,
+		NodeTags:        t.NodeTags,
+		NodeTagsMatch:   t.NodeTagsMatch,
+		ParentId:        parentId,
+		Attempt:         t.Attempt + 1,
+		Priority:        t.Priority,
+		Envs:            t.Envs,
+		Timeout:         t.Timeout,
+		Version:         t.Version,
+		IsVersionPinned: t.IsVersionPinned,
+	}
+	if t.Mode != constants.RunTypeRandom {
+		// retry on the same node if the task was assigned to a specific node
+		rt.NodeId = t.NodeId
+	}
+	if a, err := svc.modelSvc.GetArtifactById(t.Id); err == nil && a.Sys != nil {
+		rt.UserId = a.Sys.CreateUid
+	}
+
+	// enqueue with backoff delay, which is persisted in the task queue item
+	// so that pending retries survive restarts of the master node
+	delay := p.GetDelay(rt.Attempt)
+	if delay > 0 {
+		rt.PendingReason = constants.TaskPendingReasonRetryBackoff
+	}
+	log.Infof("task[%s] failed with status %s, retry attempt %d/%d in %v", t.Id.Hex(), t.Status, rt.Attempt, p.MaxAttempts, delay)
+	return svc.enqueue(rt, time.Now().Add(delay))
+}
+
+// getRetryPolicy get retry policy of the task, where the policy of the schedule
+// takes precedence over that of the spider
+func (svc *Service) getRetryPolicy(t *models.Task) (p *models.RetryPolicy, err error) {
+	if !t.ScheduleId.IsZero() {
+		s, err := svc.modelSvc.GetScheduleById(t.ScheduleId)
+		if err != nil && err != mongo2.ErrNoDocuments {
+			return nil, err
+		}
+		if s != nil && s.RetryPolicy.IsEnabled() {
+			return &s.RetryPolicy, nil
+		}
+	}
+	s, err := svc.modelSvc.GetSpiderById(t.SpiderId)
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s.RetryPolicy, nil
+}
+
+// getTaskQueueItems get a batch of task queue items sorted by priority, excluding
+// those of spiders or users whose running tasks reach the caps. Items of tasks
+// with no nodes matching their node tags are deferred after the others, so that
+// they do not fill up the batch and starve the rest of the queue
+func (svc *Service) getTaskQueueItems(spiderCounts, userCounts, spiderCaps map[primitive.ObjectID]int) (tqList []models.TaskQueueItem, err error) {
+	query := bson.M{
+		"$or": bson.A{
+			bson.M{"nb": bson.M{"$exists": false}},
+			bson.M{"nb": bson.M{"$lte": time.Now()}},
+		},
+	}
+	if ids := svc.getCappedIds(spiderCounts, spiderCaps, svc.maxRunningPerSpider); len(ids) > 0 {
+		query["sid"] = bson.M{"$nin": ids}
+	}
+	if ids := svc.getCappedIds(userCounts, nil, svc.maxRunningPerUser); len(ids) > 0 {
+		query["uid"] = bson.M{"$nin": ids}
+	}
 	opts := &mongo.FindOptions{
 		Sort: bson.D{
+			{"d", 1},
 			{"p", 1},
 			{"_id", 1},
 		},
+		Limit: svc.batchSize,
 	}
-	if err := mongo.GetMongoCol(interfaces.ModelColNameTaskQueue).Find(nil, opts).All(&tqList); err != nil {
+	if err := mongo.GetMongoCol(interfaces.ModelColNameTaskQueue).Find(query, opts).All(&tqList); err != nil {
 		if err == mongo2.ErrNoDocuments {
 			return nil, nil
 		}
@@ -322,9 +527,109 @@ func (svc *Service) getTaskQueueItems() (tqList []models.TaskQueueItem, err erro
 	return tqList, nil
 }
 
-func (svc *Service) getResourcesAndNodesMap() (resources map[string]models.Node, nodesMap map[primitive.ObjectID]models.Node, err error) {
+func (svc *Service) getCappedIds(counts, caps map[primitive.ObjectID]int, max int) (ids []primitive.ObjectID) {
+	for id, c := range counts {
+		if max := getSpiderCap(id, caps, max); max > 0 && !id.IsZero() && c >= max {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// getSpiderCaps get max concurrency of spiders which have it set
+func (svc *Service) getSpiderCaps() (spiderCaps map[primitive.ObjectID]int, err error) {
+	spiderCaps = map[primitive.ObjectID]int{}
+	query := bson.M{
+		"max_concurrency": bson.M{"$gt": 0},
+	}
+	var spiders []models.Spider
+	if err := mongo.GetMongoCol(interfaces.ModelColNameSpider).Find(query, nil).All(&spiders); err != nil {
+		if err == mongo2.ErrNoDocuments {
+			return spiderCaps, nil
+		}
+		return nil, err
+	}
+	for _, s := range spiders {
+		spiderCaps[s.Id] = s.MaxConcurrency
+	}
+	return spiderCaps, nil
+}
+
+// getRunningTaskCounts get counts of running tasks by spider and by user if
+// corresponding caps are set, including tasks which have been dequeued and
+// assigned to nodes but are not running yet
+func (svc *Service) getRunningTaskCounts(hasSpiderCaps bool) (spiderCounts, userCounts map[primitive.ObjectID]int, err error) {
+	spiderCounts = map[primitive.ObjectID]int{}
+	userCounts = map[primitive.ObjectID]int{}
+	match := mongo2.Pipeline{
+		{{"$match", bson.M{
+			"$or": bson.A{
+				bson.M{"status": constants.TaskStatusRunning},
+				bson.M{
+					"status":  constants.TaskStatusPending,
+					"node_id": bson.M{"$ne": primitive.NilObjectID},
+				},
+			},
+		}}},
+		// exclude pending tasks still in the task queue, i.e. not dequeued yet
+		{{"$lookup", bson.M{
+			"from":         interfaces.ModelColNameTaskQueue,
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "_q",
+		}}},
+		{{"$match", bson.M{"_q": bson.M{"$size": 0}}}},
+	}
+
+	// by spider
+	if svc.maxRunningPerSpider > 0 || hasSpiderCaps {
+		pipeline := append(mongo2.Pipeline{}, match...)
+		pipeline = append(pipeline, bson.D{{"$group", bson.M{"_id": "$spider_id", "count": bson.M{"$sum": 1}}}})
+		if err := svc.aggregateCounts(pipeline, spiderCounts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// by user, who is the creator of the task artifact
+	if svc.maxRunningPerUser > 0 {
+		pipeline := append(mongo2.Pipeline{}, match...)
+		pipeline = append(pipeline, mongo2.Pipeline{
+			{{"$lookup", bson.M{
+				"from":         interfaces.ModelColNameArtifact,
+				"localField":   "_id",
+				"foreignField": "_id",
+				"as":           "_a",
+			}}},
+			{{"$unwind", "$_a"}},
+			{{"$group", bson.M{"_id": "$_a._sys.create_uid", "count": bson.M{"$sum": 1}}}},
+		}...)
+		if err := svc.aggregateCounts(pipeline, userCounts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return spiderCounts, userCounts, nil
+}
+
+func (svc *Service) aggregateCounts(pipeline mongo2.Pipeline, counts map[primitive.ObjectID]int) (err error) {
+	var results []struct {
+		Id    primitive.ObjectID `bson:"_id"`
+		Count int                `bson:"count"`
+	}
+	if err := mongo.GetMongoCol(interfaces.ModelColNameTask).Aggregate(pipeline, nil).All(&results); err != nil {
+		if err == mongo2.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+	for _, r := range results {
+		counts[r.Id] = r.Count
+	}
+	return nil
+}
+
+func (svc *Service) getResourcesAndNodesMap() (resourcesList []models.Node, nodesMap map[primitive.ObjectID]models.Node, err error) {
 	nodesMap = map[primitive.ObjectID]models.Node{}
-	resources = map[string]models.Node{}
 	query := bson.M{
 		// enabled: true
 		"enabled": true,
@@ -344,70 +649,242 @@ func (svc *Service) getResourcesAndNodesMap() (resources map[string]models.Node,
 	}
 	for _, n := range nodes {
 		nodesMap[n.Id] = n
-		for i := 0; i < n.AvailableRunners; i++ {
-			key := fmt.Sprintf("%s:%d", n.Id.Hex(), i)
-			resources[key] = n
-		}
 	}
-	return resources, nodesMap, nil
+	return getResourcesList(nodes), nodesMap, nil
 }
 
 func (svc *Service) matchResources(tqList []models.TaskQueueItem) (tasks []interfaces.Task, nodesMap map[primitive.ObjectID]models.Node, err error) {
 	// get resources and nodes map
-	resources, nodesMap, err := svc.getResourcesAndNodesMap()
+	resourcesList, nodesMap, err := svc.getResourcesAndNodesMap()
 	if err != nil {
 		return nil, nil, err
 	}
-	if resources == nil || len(resources) == 0 {
-		return nil, nil, nil
+	if len(resourcesList) == 0 {
+		// record pending reasons of tasks with node tags
+		return nil, nil, svc.updatePendingReasons(tqList, nil)
 	}
 
-	// resources list
-	var resourcesList []models.Node
-	for _, r := range resources {
-		resourcesList = append(resourcesList, r)
+	// get tasks of task queue items in batch
+	tasksMap, err := svc.getTasksMap(tqList)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// shuffle resources list
-	rand.Seed(time.Now().Unix())
-	rand.Shuffle(len(resourcesList), func(i, j int) {
-		resourcesList[i], resourcesList[j] = resourcesList[j], resourcesList[i]
-	})
+	// get schedules with overlap policy "queue" and whether their previous runs are still running
+	queueSchedules, err := svc.getQueueSchedules(tasksMap)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// iterate task queue items
+	var unmatchedIds []primitive.ObjectID
 	for _, tq := range tqList {
 		// task
-		t, err := svc.modelSvc.GetTaskById(tq.GetId())
-		if err != nil {
-			return nil, nil, err
+		t, ok := tasksMap[tq.GetId()]
+		if !ok {
+			continue
+		}
+
+		// hold the task until the previous run of its schedule ends
+		if queueSchedules[t.ScheduleId] {
+			if err := svc.setPendingReason(t, constants.TaskPendingReasonScheduleStillRunning); err != nil {
+				return nil, nil, err
+			}
+			continue
 		}
 
-		// iterate shuffled resources to match a resource
+		// iterate resources to match a resource
+		matched := false
 		for i, r := range resourcesList {
-			// If node id is unset or node id of task matches with resource id (node id),
-			// assign corresponding resource id to the task
-			if t.GetNodeId().IsZero() ||
-				t.GetNodeId() == r.GetId() {
-				// assign resource id
-				t.NodeId = r.GetId()
+			if !svc.isResourceMatched(t, &r) {
+				continue
+			}
+
+			// assign resource id
+			t.NodeId = r.GetId()
+			t.PendingReason = ""
+
+			// mark the schedule as running
+			if _, ok := queueSchedules[t.ScheduleId]; ok {
+				queueSchedules[t.ScheduleId] = true
+			}
+
+			// append to tasks
+			tasks = append(tasks, t)
+
+			// delete from resources list
+			resourcesList = append(resourcesList[:i], resourcesList[(i+1):]...)
+
+			// decrement available runners
+			n := nodesMap[r.GetId()]
+			n.DecrementAvailableRunners()
+			nodesMap[r.GetId()] = n
 
-				// append to tasks
-				tasks = append(tasks, t)
+			// break loop
+			matched = true
+			break
+		}
 
-				// delete from resources list
-				resourcesList = append(resourcesList[:i], resourcesList[(i+1):]...)
+		// unmatched task
+		if !matched {
+			unmatchedIds = append(unmatchedIds, t.Id)
+		}
+	}
 
-				// decrement available runners
-				n := nodesMap[r.GetId()]
-				n.DecrementAvailableRunners()
+	// record pending reasons of unmatched tasks with node tags
+	if err := svc.updatePendingReasons(nil, unmatchedIds); err != nil {
+		return nil, nil, err
+	}
 
-				// break loop
+	return tasks, nodesMap, nil
+}
+
+// getQueueSchedules get schedules of the tasks with overlap policy constants.ScheduleOverlapPolicyQueue,
+// mapped to whether they have running tasks
+func (svc *Service) getQueueSchedules(tasksMap map[primitive.ObjectID]*models.Task) (res map[primitive.ObjectID]bool, err error) {
+	res = map[primitive.ObjectID]bool{}
+
+	// schedule ids
+	var ids []primitive.ObjectID
+	for _, t := range tasksMap {
+		if !t.ScheduleId.IsZero() {
+			ids = append(ids, t.ScheduleId)
+		}
+	}
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	// schedules
+	var schedules []models.Schedule
+	if err := mongo.GetMongoCol(interfaces.ModelColNameSchedule).Find(bson.M{
+		"_id":            bson.M{"$in": ids},
+		"overlap_policy": constants.ScheduleOverlapPolicyQueue,
+	}, nil).All(&schedules); err != nil && err != mongo2.ErrNoDocuments {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return res, nil
+	}
+	ids = nil
+	for _, s := range schedules {
+		res[s.Id] = false
+		ids = append(ids, s.Id)
+	}
+
+	// running tasks of schedules
+	var tasks []models.Task
+	if err := mongo.GetMongoCol(interfaces.ModelColNameTask).Find(bson.M{
+		"schedule_id": bson.M{"$in": ids},
+		"status":      constants.TaskStatusRunning,
+	}, nil).All(&tasks); err != nil && err != mongo2.ErrNoDocuments {
+		return nil, err
+	}
+	for _, t := range tasks {
+		res[t.ScheduleId] = true
+	}
+
+	return res, nil
+}
+
+func (svc *Service) getTasksMap(tqList []models.TaskQueueItem) (tasksMap map[primitive.ObjectID]*models.Task, err error) {
+	tasksMap = map[primitive.ObjectID]*models.Task{}
+	var ids []primitive.ObjectID
+	for _, tq := range tqList {
+		ids = append(ids, tq.GetId())
+	}
+	if len(ids) == 0 {
+		return tasksMap, nil
+	}
+	list, err := svc.modelSvc.GetTaskList(bson.M{"_id": bson.M{"$in": ids}}, nil)
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			return tasksMap, nil
+		}
+		return nil, err
+	}
+	for i := range list {
+		tasksMap[list[i].Id] = &list[i]
+	}
+	return tasksMap, nil
+}
+
+// isResourceMatched whether the task can be assigned to the resource (node),
+// i.e. node id of the task is unset or matches with the node, and the node
+// carries node tags of the task if run mode is constants.RunTypeSelectedTags
+func (svc *Service) isResourceMatched(t *models.Task, n *models.Node) (ok bool) {
+	if !t.GetNodeId().IsZero() && t.GetNodeId() != n.GetId() {
+		return false
+	}
+	if t.Mode == constants.RunTypeSelectedTags && !n.HasTags(t.NodeTags, t.NodeTagsMatch) {
+		return false
+	}
+	return true
+}
+
+// updatePendingReasons save reasons why tasks with node tags, either in the task
+// queue items or of the given ids, are not matched with any node
+func (svc *Service) updatePendingReasons(tqList []models.TaskQueueItem, ids []primitive.ObjectID) (err error) {
+	for _, tq := range tqList {
+		ids = append(ids, tq.GetId())
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// tasks with node tags
+	tasks, err := svc.modelSvc.GetTaskList(bson.M{
+		"_id":  bson.M{"$in": ids},
+		"mode": constants.RunTypeSelectedTags,
+	}, nil)
+	if err != nil {
+		if err == mongo2.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	// enabled and active nodes
+	nodes, err := svc.modelSvc.GetNodeList(bson.M{
+		"enabled": true,
+		"active":  true,
+	}, nil)
+	if err != nil && err != mongo2.ErrNoDocuments {
+		return err
+	}
+
+	for _, t := range tasks {
+		reason := constants.TaskPendingReasonNoMatchingNodes
+		for _, n := range nodes {
+			if svc.isResourceMatched(&t, &n) {
+				reason = constants.TaskPendingReasonNoAvailableRunners
 				break
 			}
 		}
+		if err := svc.setPendingReason(&t, reason); err != nil {
+			return err
+		}
 	}
 
-	return tasks, nodesMap, nil
+	return nil
+}
+
+func (svc *Service) setPendingReason(t *models.Task, reason string) (err error) {
+	if t.PendingReason == reason {
+		return nil
+	}
+	t.PendingReason = reason
+	if err := delegate.NewModelDelegate(t).Save(); err != nil {
+		return err
+	}
+
+	// defer task queue item if no nodes match node tags of the task
+	return mongo.GetMongoCol(interfaces.ModelColNameTaskQueue).UpdateId(t.Id, bson.M{
+		"$set": bson.M{"d": reason == constants.TaskPendingReasonNoMatchingNodes},
+	})
 }
 
 func (svc *Service) updateResources(nodesMap map[primitive.ObjectID]models.Node) (err error) {
@@ -456,6 +933,7 @@ func NewTaskSchedulerService(opts ...Option) (svc2 interfaces.TaskSchedulerServi
 	svc := &Service{
 		TaskBaseService: baseSvc,
 		interval:        15 * time.Second,
+		batchSize:       100,
 	}
 
 	// apply options
@@ -528,6 +1006,18 @@ func ProvideGetTaskSchedulerService(path string, opts ...Option) func() (svr int
 	if intervalSeconds > 0 {
 		opts = append(opts, WithInterval(time.Duration(intervalSeconds)*time.Second))
 	}
+	batchSize := viper.GetInt("task.scheduler.batchSize")
+	if batchSize > 0 {
+		opts = append(opts, WithBatchSize(batchSize))
+	}
+	maxRunningPerSpider := viper.GetInt("task.scheduler.maxRunningPerSpider")
+	if maxRunningPerSpider > 0 {
+		opts = append(opts, WithMaxRunningPerSpider(maxRunningPerSpider))
+	}
+	maxRunningPerUser := viper.GetInt("task.scheduler.maxRunningPerUser")
+	if maxRunningPerUser > 0 {
+		opts = append(opts, WithMaxRunningPerUser(maxRunningPerUser))
+	}
 	return func() (svr interfaces.TaskSchedulerService, err error) {
 		return GetTaskSchedulerService(path, opts...)
 	}
@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"github.com/luke513009828/crawlab-core/result"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sync"
+	"time"
+)
+
+// fieldStatsFlushInterval interval of saving aggregated stats of fields of
+// records inserted by tasks
+const fieldStatsFlushInterval = 15 * time.Second
+
+// taskFieldStats stats of fields of records inserted by a task, which are
+// aggregated until saved to fields of the data collection
+type taskFieldStats struct {
+	mu      sync.Mutex
+	colId   primitive.ObjectID
+	stats   *result.DataFieldStats // nil if no records inserted since last flush
+	removed bool                   // whether fs has been removed, which should be replaced with a new one
+}
+
+// add aggregate stats of fields of records, false if fs has been removed
+func (fs *taskFieldStats) add(records []bson.M) (ok bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.removed {
+		return false
+	}
+	if fs.stats == nil {
+		fs.stats = result.NewDataFieldStats(records)
+	} else {
+		fs.stats.Add(records)
+	}
+	return true
+}
+
+// flush save aggregated stats of fields and reset them, of which fs is
+// removed with remove if no records inserted since last flush
+func (fs *taskFieldStats) flush(taskId primitive.ObjectID, remove func()) (err error) {
+	fs.mu.Lock()
+	stats := fs.stats
+	fs.stats = nil
+	if stats == nil && remove != nil && !fs.removed {
+		fs.removed = true
+		remove()
+	}
+	fs.mu.Unlock()
+
+	if stats == nil {
+		return nil
+	}
+	return stats.Save(fs.colId, taskId)
+}
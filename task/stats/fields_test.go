@@ -0,0 +1,29 @@
+package stats
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestTaskFieldStats_Add(t *testing.T) {
+	fs := &taskFieldStats{colId: primitive.NewObjectID()}
+	require.True(t, fs.add([]bson.M{{"title": "a"}}))
+	require.True(t, fs.add([]bson.M{{"title": "b"}, {"price": 1}}))
+	require.NotNil(t, fs.stats)
+
+	// removed on flush if no records since last flush
+	fs.stats = nil
+	removed := 0
+	require.Nil(t, fs.flush(primitive.NewObjectID(), func() {
+		removed++
+	}))
+	require.Equal(t, 1, removed)
+	require.False(t, fs.add([]bson.M{{"title": "c"}}))
+
+	// kept on flush without remove
+	fs = &taskFieldStats{colId: primitive.NewObjectID()}
+	require.Nil(t, fs.flush(primitive.NewObjectID(), nil))
+	require.True(t, fs.add([]bson.M{{"title": "d"}}))
+}
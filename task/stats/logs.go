@@ -0,0 +1,80 @@
+package stats
+
+import (
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"sync"
+)
+
+// logSubscriberBufferSize size of the buffer of each log subscriber, beyond
+// which lines are dropped for the subscriber and should be read from the log
+// driver instead
+const logSubscriberBufferSize = 1024
+
+// taskLogs numbers inserted log lines of a task and publishes them to subscribers
+type taskLogs struct {
+	mu      sync.Mutex
+	total   int // number of inserted log lines
+	subs    map[int]chan interfaces.TaskLogLine
+	next    int    // id of the next subscriber
+	ended   bool   // whether the task has ended, after which tl is removed once no subscribers left
+	removed bool   // whether tl has been removed, which should be replaced with a new one
+	remove  func() // remove tl from the cache
+}
+
+// subscribe should be called with tl.mu locked
+func (tl *taskLogs) subscribe() (ch <-chan interfaces.TaskLogLine, unsubscribe func()) {
+	id := tl.next
+	tl.next++
+	c := make(chan interfaces.TaskLogLine, logSubscriberBufferSize)
+	tl.subs[id] = c
+	return c, func() {
+		tl.mu.Lock()
+		defer tl.mu.Unlock()
+		delete(tl.subs, id)
+		tl.removeIfIdle()
+	}
+}
+
+// publish number the lines and send them to subscribers without blocking,
+// should be called with tl.mu locked
+func (tl *taskLogs) publish(lines []interfaces.TaskLogLine) {
+	for _, l := range lines {
+		l.Number = tl.total
+		tl.total++
+		for _, c := range tl.subs {
+			select {
+			case c <- l:
+			default:
+			}
+		}
+	}
+}
+
+// end mark the task as ended, and remove tl if no subscribers left
+func (tl *taskLogs) end() {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.ended = true
+	tl.removeIfIdle()
+}
+
+// removeIfIdle remove tl if the task has ended and no subscribers left,
+// should be called with tl.mu locked
+func (tl *taskLogs) removeIfIdle() {
+	if !tl.ended || len(tl.subs) > 0 || tl.removed {
+		return
+	}
+	tl.removed = true
+	if tl.remove != nil {
+		tl.remove()
+	}
+}
+
+func newTaskLogs(total int, ended bool, remove func()) (tl *taskLogs) {
+	return &taskLogs{
+		total:  total,
+		subs:   map[int]chan interfaces.TaskLogLine{},
+		ended:  ended,
+		remove: remove,
+	}
+}
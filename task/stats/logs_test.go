@@ -0,0 +1,66 @@
+package stats
+
+import (
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTaskLogs_Publish(t *testing.T) {
+	tl := newTaskLogs(10, false, nil)
+	ch, unsubscribe := tl.subscribe()
+
+	// numbered from existing lines
+	tl.publish([]interfaces.TaskLogLine{{Content: "line 1"}, {Content: "line 2"}})
+	l := <-ch
+	require.Equal(t, 10, l.Number)
+	require.Equal(t, "line 1", l.Content)
+	l = <-ch
+	require.Equal(t, 11, l.Number)
+
+	// unsubscribed
+	unsubscribe()
+	tl.publish([]interfaces.TaskLogLine{{Content: "line 3"}})
+	require.Len(t, ch, 0)
+	require.Equal(t, 13, tl.total)
+
+	// lines beyond the buffer are dropped without blocking
+	ch, unsubscribe = tl.subscribe()
+	defer unsubscribe()
+	lines := make([]interfaces.TaskLogLine, logSubscriberBufferSize+10)
+	tl.publish(lines)
+	require.Len(t, ch, logSubscriberBufferSize)
+	require.Equal(t, 13, (<-ch).Number)
+}
+
+func TestTaskLogs_Remove(t *testing.T) {
+	removed := 0
+	tl := newTaskLogs(0, false, func() {
+		removed++
+	})
+	_, unsubscribe1 := tl.subscribe()
+	_, unsubscribe2 := tl.subscribe()
+
+	// kept until the task ends
+	unsubscribe1()
+	require.Equal(t, 0, removed)
+
+	// kept until the last subscriber leaves
+	tl.end()
+	require.Equal(t, 0, removed)
+	unsubscribe2()
+	require.Equal(t, 1, removed)
+	require.True(t, tl.removed)
+
+	// removed once only
+	tl.end()
+	require.Equal(t, 1, removed)
+
+	// removed on end if no subscribers
+	removed = 0
+	tl = newTaskLogs(0, false, func() {
+		removed++
+	})
+	tl.end()
+	require.Equal(t, 1, removed)
+}
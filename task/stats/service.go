@@ -1,21 +1,31 @@
 package stats
 
 import (
+	"fmt"
 	config2 "github.com/luke513009828/crawlab-core/config"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/ds"
+	"github.com/luke513009828/crawlab-core/event"
 	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/models"
 	"github.com/luke513009828/crawlab-core/models/service"
 	"github.com/luke513009828/crawlab-core/node/config"
 	"github.com/luke513009828/crawlab-core/result"
 	"github.com/luke513009828/crawlab-core/task"
+	"github.com/luke513009828/crawlab-core/utils"
 	"github.com/crawlab-team/crawlab-db/mongo"
 	clog "github.com/crawlab-team/crawlab-log"
 	"github.com/crawlab-team/go-trace"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/dig"
+	"strings"
 	"sync"
+	"time"
 )
 
+const taskEndEventKey = "task:stats:end"
+
 type Service struct {
 	// dependencies
 	interfaces.TaskBaseService
@@ -27,26 +37,205 @@ type Service struct {
 	cache          sync.Map
 	logDrivers     sync.Map
 	resultServices sync.Map
+	resultSinks    sync.Map // task id -> interfaces.ResultSink, nil if results are saved in MongoDB
+	dedupOptions   sync.Map // task id -> *interfaces.ResultDedupOptions
+	schemas        sync.Map // task id -> *taskSchema
+	logs           sync.Map // task id -> *taskLogs
+	fieldStats     sync.Map // task id -> *taskFieldStats
 }
 
-func (svc *Service) InsertData(id primitive.ObjectID, records ...interface{}) (err error) {
+func (svc *Service) InsertData(id primitive.ObjectID, docs ...interface{}) (err error) {
 	resultSvc, err := svc.getResultService(id)
 	if err != nil {
 		return err
 	}
-	if err := resultSvc.Insert(records...); err != nil {
+
+	// infer fields of the data collection
+	records, err := result.ToResultRecords(docs...)
+	if err != nil {
 		return err
 	}
-	go svc.updateTaskStats(id, len(records))
-	return nil
+	svc.addFieldStats(id, resultSvc.GetId(), records)
+
+	// validate against declared fields of the data collection
+	var errCount int
+	records, errCount, err = svc.validateRecords(id, records)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		// all records are rejected
+		go svc.updateTaskStats(id, interfaces.ResultInsertStats{Errors: errCount})
+		return nil
+	}
+	docs = make([]interface{}, len(records))
+	for i, r := range records {
+		docs[i] = r
+	}
+
+	// data source other than mongo
+	sink, err := svc.getResultSink(id)
+	if err != nil {
+		return err
+	}
+	if sink != nil {
+		if err := sink.Insert(docs...); err != nil {
+			return err
+		}
+		go svc.updateTaskStats(id, interfaces.ResultInsertStats{Inserted: len(docs), Errors: errCount})
+		return nil
+	}
+
+	dedupOpts, err := svc.getResultDedupOptions(id)
+	if err != nil {
+		return err
+	}
+	stats, err := resultSvc.InsertWithDedup(dedupOpts, docs...)
+	stats.Errors = errCount
+	go svc.updateTaskStats(id, stats)
+	return err
 }
 
 func (svc *Service) InsertLogs(id primitive.ObjectID, logs ...string) (err error) {
+	lines := make([]interfaces.TaskLogLine, len(logs))
+	for i, log := range logs {
+		lines[i] = utils.NewTaskLogLine(log, "")
+	}
+	return svc.InsertLogLines(id, lines...)
+}
+
+func (svc *Service) InsertLogLines(id primitive.ObjectID, lines ...interfaces.TaskLogLine) (err error) {
 	l, err := svc.getLogDriver(id)
 	if err != nil {
 		return err
 	}
-	return l.WriteLines(logs)
+
+	// encode lines with metadata
+	logs := make([]string, len(lines))
+	errorCount := 0
+	for i, line := range lines {
+		logs[i] = utils.EncodeTaskLogLine(line)
+		if line.Level == constants.LogLevelError {
+			errorCount++
+		}
+	}
+
+	// write and publish
+	tl := svc.lockTaskLogs(id, l)
+	defer tl.mu.Unlock()
+	if err := l.WriteLines(logs); err != nil {
+		return err
+	}
+	tl.publish(lines)
+	tl.removeIfIdle()
+
+	// error log count
+	if errorCount > 0 {
+		go svc.updateTaskErrorLogCount(id, errorCount)
+	}
+
+	return nil
+}
+
+func (svc *Service) SubscribeLogs(id primitive.ObjectID) (ch <-chan interfaces.TaskLogLine, unsubscribe func()) {
+	l, err := svc.getLogDriver(id)
+	if err != nil {
+		// no lines would be published without log driver
+		trace.PrintError(err)
+		return newTaskLogs(0, false, nil).subscribe()
+	}
+	tl := svc.lockTaskLogs(id, l)
+	defer tl.mu.Unlock()
+	return tl.subscribe()
+}
+
+func (svc *Service) Start() {
+	go svc.handleTaskEnds()
+	go svc.flushFieldStats()
+	svc.Wait()
+	svc.Stop()
+}
+
+// handleTaskEnds subscribe to task change events and clean up internals of
+// ended tasks
+func (svc *Service) handleTaskEnds() {
+	ch := make(chan interfaces.EventData)
+	eventSvc := event.NewEventService()
+	eventSvc.Register(taskEndEventKey, "^model:"+interfaces.ModelColNameTask+":change$", "^$", &ch)
+	defer eventSvc.Unregister(taskEndEventKey)
+
+	for {
+		if svc.IsStopped() {
+			return
+		}
+
+		ed := <-ch
+		t, ok := ed.GetData().(*models.Task)
+		if !ok || !utils.IsTaskEnded(t.Status) {
+			continue
+		}
+
+		// remove task logs once subscribers of live lines leave
+		if res, ok := svc.logs.Load(t.Id); ok {
+			res.(*taskLogs).end()
+		}
+
+		// close the sink of results, which is opened again if records are
+		// inserted after the task has ended
+		if res, ok := svc.resultSinks.Load(t.Id); ok {
+			svc.resultSinks.Delete(t.Id)
+			if sink, ok := res.(interfaces.ResultSink); ok && sink != nil {
+				if err := sink.Close(); err != nil {
+					trace.PrintError(err)
+				}
+			}
+		}
+		svc.dedupOptions.Delete(t.Id)
+
+		// schema is loaded again if records are inserted after the task has ended
+		svc.schemas.Delete(t.Id)
+
+		// save stats of fields without waiting for the next flush
+		if res, ok := svc.fieldStats.Load(t.Id); ok {
+			if err := res.(*taskFieldStats).flush(t.Id, nil); err != nil {
+				trace.PrintError(err)
+			}
+		}
+	}
+}
+
+// flushFieldStats periodically save aggregated stats of fields of records
+// inserted by tasks, of which those of tasks inserting no more records are
+// removed
+func (svc *Service) flushFieldStats() {
+	for {
+		if svc.IsStopped() {
+			return
+		}
+
+		time.Sleep(fieldStatsFlushInterval)
+
+		svc.fieldStats.Range(func(key, value interface{}) bool {
+			id := key.(primitive.ObjectID)
+			if err := value.(*taskFieldStats).flush(id, func() {
+				svc.fieldStats.Delete(id)
+			}); err != nil {
+				trace.PrintError(err)
+			}
+			return true
+		})
+	}
+}
+
+// addFieldStats aggregate stats of fields of records inserted by the task,
+// which are saved by flushFieldStats
+func (svc *Service) addFieldStats(id, colId primitive.ObjectID, records []bson.M) {
+	for {
+		res, _ := svc.fieldStats.LoadOrStore(id, &taskFieldStats{colId: colId})
+		if res.(*taskFieldStats).add(records) {
+			return
+		}
+	}
 }
 
 func (svc *Service) getResultService(id primitive.ObjectID) (resultSvc interfaces.ResultService, err error) {
@@ -86,6 +275,142 @@ func (svc *Service) getResultService(id primitive.ObjectID) (resultSvc interface
 	return resultSvc, nil
 }
 
+func (svc *Service) getResultSink(id primitive.ObjectID) (sink interfaces.ResultSink, err error) {
+	// attempt to get from cache
+	res, ok := svc.resultSinks.Load(id)
+	if ok {
+		sink, _ = res.(interfaces.ResultSink)
+		return sink, nil
+	}
+
+	// task
+	t, err := svc.modelSvc.GetTaskById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// spider
+	s, err := svc.modelSvc.GetSpiderById(t.SpiderId)
+	if err != nil {
+		return nil, err
+	}
+
+	// data source
+	if !s.DataSourceId.IsZero() {
+		dataSource, err := svc.modelSvc.GetDataSourceById(s.DataSourceId)
+		if err != nil {
+			return nil, err
+		}
+		if dataSource.Type != "" && dataSource.Type != constants.DataSourceTypeMongo {
+			// results are written to the target named after the data collection
+			dc, err := svc.modelSvc.GetDataCollectionById(s.ColId)
+			if err != nil {
+				return nil, err
+			}
+			sink, err = ds.GetResultSink(dataSource, dc.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// store in cache
+	svc.resultSinks.Store(id, sink)
+
+	return sink, nil
+}
+
+// validateRecords validate records against the schema of the data collection
+// of the task, of which those not conforming are flagged or rejected with a
+// warning in logs of the task
+func (svc *Service) validateRecords(id primitive.ObjectID, records []bson.M) (res []bson.M, errCount int, err error) {
+	schema, err := svc.getSchema(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if schema == nil {
+		return records, 0, nil
+	}
+	res, errs := schema.ValidateRecords(records)
+	if len(errs) == 0 {
+		return res, 0, nil
+	}
+	action := "flagged"
+	if schema.Mode == constants.DataCollectionSchemaModeReject {
+		action = "rejected"
+	}
+	line := utils.NewTaskLogLine(fmt.Sprintf("%s %d records not conforming to the schema of the data collection (%s)", action, len(errs), strings.Join(errs[0], "; ")), constants.LogStreamSystem)
+	line.Level = constants.LogLevelWarning
+	if err := svc.InsertLogLines(id, line); err != nil {
+		trace.PrintError(err)
+	}
+	return res, len(errs), nil
+}
+
+// taskSchema schema of the data collection of a task, nil if records are not
+// validated, which is reloaded after schemaCacheTtl to apply changes of
+// declared fields to running tasks
+type taskSchema struct {
+	schema *result.Schema
+	ts     time.Time
+}
+
+// schemaCacheTtl duration for which schemas of data collections of tasks are cached
+const schemaCacheTtl = 30 * time.Second
+
+func (svc *Service) getSchema(id primitive.ObjectID) (schema *result.Schema, err error) {
+	// attempt to get from cache
+	res, ok := svc.schemas.Load(id)
+	if ok && time.Since(res.(*taskSchema).ts) < schemaCacheTtl {
+		return res.(*taskSchema).schema, nil
+	}
+
+	// data collection
+	resultSvc, err := svc.getResultService(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// schema
+	schema, err = result.GetSchema(resultSvc.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	// store in cache
+	svc.schemas.Store(id, &taskSchema{schema: schema, ts: time.Now()})
+
+	return schema, nil
+}
+
+func (svc *Service) getResultDedupOptions(id primitive.ObjectID) (opts *interfaces.ResultDedupOptions, err error) {
+	// attempt to get from cache
+	res, ok := svc.dedupOptions.Load(id)
+	if ok {
+		return res.(*interfaces.ResultDedupOptions), nil
+	}
+
+	// task
+	t, err := svc.modelSvc.GetTaskById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// spider
+	s, err := svc.modelSvc.GetSpiderById(t.SpiderId)
+	if err != nil {
+		return nil, err
+	}
+
+	// dedup options
+	opts = result.NewResultDedupOptions(s.IsDedup, s.DedupMethod, s.DedupField)
+
+	// store in cache
+	svc.dedupOptions.Store(id, opts)
+
+	return opts, nil
+}
+
 func (svc *Service) getLogDriver(id primitive.ObjectID) (l clog.Driver, err error) {
 	// attempt to get from cache
 	res, ok := svc.logDrivers.Load(id)
@@ -114,10 +439,60 @@ func (svc *Service) getLogDriver(id primitive.ObjectID) (l clog.Driver, err erro
 	return l, nil
 }
 
-func (svc *Service) updateTaskStats(id primitive.ObjectID, resultCount int) {
+func (svc *Service) getTaskLogs(id primitive.ObjectID, l clog.Driver) (tl *taskLogs) {
+	// attempt to get from cache
+	res, ok := svc.logs.Load(id)
+	if ok {
+		return res.(*taskLogs)
+	}
+
+	// number lines from the count of existing lines
+	total, err := l.Count("")
+	if err != nil {
+		total = 0
+	}
+
+	// lines may be inserted after the task has ended, of which task logs
+	// are removed right after being published
+	ended := false
+	if t, err := svc.modelSvc.GetTaskById(id); err == nil {
+		ended = utils.IsTaskEnded(t.Status)
+	}
+
+	res, _ = svc.logs.LoadOrStore(id, newTaskLogs(total, ended, func() {
+		svc.logs.Delete(id)
+	}))
+	return res.(*taskLogs)
+}
+
+// lockTaskLogs get task logs of the task with its lock held, of which those
+// removed concurrently are replaced with new ones
+func (svc *Service) lockTaskLogs(id primitive.ObjectID, l clog.Driver) (tl *taskLogs) {
+	for {
+		tl = svc.getTaskLogs(id, l)
+		tl.mu.Lock()
+		if !tl.removed {
+			return tl
+		}
+		tl.mu.Unlock()
+	}
+}
+
+func (svc *Service) updateTaskStats(id primitive.ObjectID, stats interfaces.ResultInsertStats) {
+	_ = mongo.GetMongoCol(interfaces.ModelColNameTaskStat).UpdateId(id, bson.M{
+		"$inc": bson.M{
+			"result_count":        stats.Inserted,
+			"result_skip_count":   stats.Skipped,
+			"result_update_count": stats.Updated,
+			"result_error_count":  stats.Errors,
+		},
+	})
+}
+
+func (svc *Service) updateTaskErrorLogCount(id primitive.ObjectID, errorLogCount int) {
 	_ = mongo.GetMongoCol(interfaces.ModelColNameTaskStat).UpdateId(id, bson.M{
 		"$inc": bson.M{
-			"result_count": resultCount,
+			"error_log_count": errorLogCount,
 		},
 	})
 }
@@ -12,12 +12,18 @@ import (
 	"github.com/crawlab-team/go-trace"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/dig"
 	"time"
 )
 
+// tokenLastUsedInterval minimum interval of updating the last used time of
+// api tokens, which saves writes of frequently used tokens
+const tokenLastUsedInterval = time.Minute
+
 type Service struct {
 	// settings variables
 	jwtSecret        string
@@ -116,6 +122,11 @@ func (svc *Service) Login(opts *interfaces.UserLoginOptions) (token string, u in
 }
 
 func (svc *Service) CheckToken(tokenStr string) (u interfaces.User, err error) {
+	u, _, err = svc.checkToken(tokenStr)
+	return u, err
+}
+
+func (svc *Service) CheckTokenWithScopes(tokenStr string) (u interfaces.User, scopes []string, err error) {
 	return svc.checkToken(tokenStr)
 }
 
@@ -137,6 +148,10 @@ func (svc *Service) MakeToken(user interfaces.User) (tokenStr string, err error)
 	return svc.makeToken(user)
 }
 
+func (svc *Service) MakeApiToken(user interfaces.User, opts *interfaces.UserTokenOptions) (tokenStr string, err error) {
+	return svc._makeToken(user, opts)
+}
+
 func (svc *Service) GetCurrentUser(c *gin.Context) (user interfaces.User, err error) {
 	// token string
 	tokenStr := c.GetHeader("Authorization")
@@ -150,18 +165,40 @@ func (svc *Service) GetCurrentUser(c *gin.Context) (user interfaces.User, err er
 	return u, nil
 }
 
+// makeToken make a login token, which expires in user.tokenExpireHours
+// (constants.DefaultTokenExpireHours by default)
 func (svc *Service) makeToken(user interfaces.User) (tokenStr string, err error) {
-	token := jwt.NewWithClaims(svc.jwtSigningMethod, jwt.MapClaims{
+	expireHours := viper.GetInt("user.tokenExpireHours")
+	if expireHours <= 0 {
+		expireHours = constants.DefaultTokenExpireHours
+	}
+	return svc._makeToken(user, &interfaces.UserTokenOptions{
+		ExpireTs: time.Now().Add(time.Duration(expireHours) * time.Hour),
+	})
+}
+
+func (svc *Service) _makeToken(user interfaces.User, opts *interfaces.UserTokenOptions) (tokenStr string, err error) {
+	claims := jwt.MapClaims{
 		"id":       user.GetId(),
 		"username": user.GetUsername(),
 		"nbf":      time.Now().Unix(),
-	})
+	}
+	if !opts.Id.IsZero() {
+		claims["tid"] = opts.Id.Hex()
+	}
+	if !opts.ExpireTs.IsZero() {
+		claims["exp"] = opts.ExpireTs.Unix()
+	}
+	token := jwt.NewWithClaims(svc.jwtSigningMethod, claims)
 	return token.SignedString([]byte(svc.jwtSecret))
 }
 
-func (svc *Service) checkToken(tokenStr string) (user interfaces.User, err error) {
+func (svc *Service) checkToken(tokenStr string) (user interfaces.User, scopes []string, err error) {
 	token, err := jwt.Parse(tokenStr, svc.getSecretFunc())
 	if err != nil {
+		if ve, ok := err.(*jwt.ValidationError); ok && ve.Errors&jwt.ValidationErrorExpired != 0 {
+			err = errors.ErrorUserTokenExpired
+		}
 		return
 	}
 
@@ -178,7 +215,7 @@ func (svc *Service) checkToken(tokenStr string) (user interfaces.User, err error
 
 	id, err := primitive.ObjectIDFromHex(claim["id"].(string))
 	if err != nil {
-		return user, err
+		return user, nil, err
 	}
 	username := claim["username"].(string)
 	user, err = svc.modelSvc.GetUserById(id)
@@ -192,9 +229,66 @@ func (svc *Service) checkToken(tokenStr string) (user interfaces.User, err error
 		return
 	}
 
+	// api token
+	if tid, ok := claim["tid"].(string); ok {
+		scopes, err = svc.checkApiToken(tid, tokenStr, user)
+		if err != nil {
+			return nil, nil, err
+		}
+		return
+	}
+
+	// tokens without expiration are legacy tokens, which cannot be revoked
+	if _, ok := claim["exp"]; !ok {
+		return nil, nil, errors.ErrorUserInvalidToken
+	}
+
 	return
 }
 
+// checkApiToken check if the api token is revoked or expired and get its scopes,
+// and track the last time it was used
+func (svc *Service) checkApiToken(tid, tokenStr string, user interfaces.User) (scopes []string, err error) {
+	id, err := primitive.ObjectIDFromHex(tid)
+	if err != nil {
+		return nil, errors.ErrorUserInvalidToken
+	}
+	t, err := svc.modelSvc.GetTokenById(id)
+	if err != nil {
+		if err.Error() == mongo.ErrNoDocuments.Error() {
+			return nil, errors.ErrorUserTokenRevoked
+		}
+		return nil, err
+	}
+	if t.TokenHash != utils.HashToken(tokenStr) {
+		return nil, errors.ErrorUserTokenRevoked
+	}
+	if !t.UserId.IsZero() && t.UserId != user.GetId() {
+		return nil, errors.ErrorUserMismatch
+	}
+	now := time.Now()
+	if t.IsExpired(now) {
+		return nil, errors.ErrorUserTokenExpired
+	}
+
+	// last used
+	if now.Sub(t.LastUsedTs) > tokenLastUsedInterval {
+		go func() {
+			_ = mongo2.GetMongoCol(interfaces.ModelColNameToken).UpdateId(id, bson.M{
+				"$set": bson.M{
+					"last_used_ts": now,
+				},
+			})
+		}()
+	}
+
+	// empty scopes of api tokens allow nothing
+	if t.Scopes == nil {
+		return []string{}, nil
+	}
+	return t.Scopes, nil
+}
+
 func (svc *Service) getSecretFunc() jwt.Keyfunc {
 	return func(token *jwt.Token) (interface{}, error) {
 		return []byte(svc.jwtSecret), nil
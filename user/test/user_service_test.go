@@ -2,10 +2,15 @@ package test
 
 import (
 	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
 	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
 	"github.com/luke513009828/crawlab-core/utils"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"testing"
+	"time"
 )
 
 func TestUserService_Init(t *testing.T) {
@@ -59,3 +64,48 @@ func TestUserService_ChangePassword(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, utils.EncryptPassword(T.TestNewPassword), u2.Password)
 }
+
+func TestUserService_MakeApiToken(t *testing.T) {
+	var err error
+	T.Setup(t)
+
+	u, err := T.modelSvc.GetUserByUsername(constants.DefaultAdminUsername, nil)
+	require.Nil(t, err)
+
+	// api token
+	tk := &models.Token{
+		Name:   "ci",
+		UserId: u.Id,
+		Scopes: []string{constants.TokenScopeRunTasks},
+	}
+	tk.Id = primitive.NewObjectID()
+	tk.Token, err = T.userSvc.MakeApiToken(u, &interfaces.UserTokenOptions{Id: tk.Id})
+	require.Nil(t, err)
+	tk.TokenHash = utils.HashToken(tk.Token)
+	err = delegate.NewModelDelegate(tk).Add()
+	require.Nil(t, err)
+
+	// scopes
+	u2, scopes, err := T.userSvc.CheckTokenWithScopes(tk.Token)
+	require.Nil(t, err)
+	require.Equal(t, u.Username, u2.GetUsername())
+	require.Equal(t, tk.Scopes, scopes)
+
+	// revoked
+	err = delegate.NewModelDelegate(tk).Delete()
+	require.Nil(t, err)
+	_, err = T.userSvc.CheckToken(tk.Token)
+	require.Equal(t, errors.ErrorUserTokenRevoked, err)
+
+	// expired
+	token, err := T.userSvc.MakeApiToken(u, &interfaces.UserTokenOptions{ExpireTs: time.Now().Add(-time.Hour)})
+	require.Nil(t, err)
+	_, err = T.userSvc.CheckToken(token)
+	require.Equal(t, errors.ErrorUserTokenExpired, err)
+
+	// legacy tokens without token id or expiration
+	token, err = T.userSvc.MakeApiToken(u, &interfaces.UserTokenOptions{})
+	require.Nil(t, err)
+	_, err = T.userSvc.CheckToken(token)
+	require.Equal(t, errors.ErrorUserInvalidToken, err)
+}
@@ -62,6 +62,24 @@ func (b *ColNameBinder) Bind() (res interface{}, err error) {
 		return interfaces.ModelColNamePluginStatus, nil
 	case interfaces.ModelIdGit:
 		return interfaces.ModelColNameGit, nil
+	case interfaces.ModelIdWorkflow:
+		return interfaces.ModelColNameWorkflow, nil
+	case interfaces.ModelIdWorkflowRun:
+		return interfaces.ModelColNameWorkflowRun, nil
+	case interfaces.ModelIdNotificationSetting:
+		return interfaces.ModelColNameNotificationSetting, nil
+	case interfaces.ModelIdNotificationDelivery:
+		return interfaces.ModelColNameNotificationDelivery, nil
+	case interfaces.ModelIdAuditLog:
+		return interfaces.ModelColNameAuditLog, nil
+	case interfaces.ModelIdResultExport:
+		return interfaces.ModelColNameResultExport, nil
+	case interfaces.ModelIdDataField:
+		return interfaces.ModelColNameDataField, nil
+	case interfaces.ModelIdGitSyncLog:
+		return interfaces.ModelColNameGitSyncLog, nil
+	case interfaces.ModelIdGitSshKey:
+		return interfaces.ModelColNameGitSshKey, nil
 
 	// invalid
 	default:
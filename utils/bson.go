@@ -5,6 +5,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"reflect"
+	"sort"
 )
 
 func BsonMEqual(v1, v2 bson.M) (ok bool) {
@@ -98,3 +99,25 @@ func NormalizeBsonMObjectId(m bson.M) (res bson.M) {
 	}
 	return m
 }
+
+// GetBsonMDiffKeys get sorted top-level keys of which values differ between v1 and v2
+func GetBsonMDiffKeys(v1, v2 bson.M) (keys []string) {
+	allKeys := hashset.New()
+	for key := range v1 {
+		allKeys.Add(key)
+	}
+	for key := range v2 {
+		allKeys.Add(key)
+	}
+	for _, keyRes := range allKeys.Values() {
+		key := keyRes.(string)
+		v1Value, ok1 := v1[key]
+		v2Value, ok2 := v2[key]
+		if ok1 == ok2 && reflect.DeepEqual(v1Value, v2Value) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
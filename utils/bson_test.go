@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestGetBsonMDiffKeys(t *testing.T) {
+	v1 := bson.M{"name": "a", "cron": "* * * * *", "tags": bson.A{"x"}, "removed": 1}
+	v2 := bson.M{"name": "a", "cron": "0 * * * *", "tags": bson.A{"x"}, "added": nil}
+	require.Equal(t, []string{"added", "cron", "removed"}, GetBsonMDiffKeys(v1, v2))
+	require.Empty(t, GetBsonMDiffKeys(v1, v1))
+	require.Equal(t, []string{"name"}, GetBsonMDiffKeys(nil, bson.M{"name": "a"}))
+}
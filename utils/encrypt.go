@@ -1,10 +1,16 @@
 package utils
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"github.com/spf13/viper"
+	"io"
 )
 
 func ComputeHmacSha256(message string, secret string) string {
@@ -14,3 +20,55 @@ func ComputeHmacSha256(message string, secret string) string {
 	sha := hex.EncodeToString(h.Sum(nil))
 	return base64.StdEncoding.EncodeToString([]byte(sha))
 }
+
+// EncryptAes encrypt the text with AES-GCM, of which the key is derived from
+// the secret, and return the nonce and cipher text encoded in base64
+func EncryptAes(text string, secret string) (res string, err error) {
+	gcm, err := newAesGcm(secret)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	data := gcm.Seal(nonce, nonce, []byte(text), nil)
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecryptAes decrypt the text encrypted by EncryptAes with the same secret
+func DecryptAes(text string, secret string) (res string, err error) {
+	gcm, err := newAesGcm(secret)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("invalid cipher text")
+	}
+	nonce, data := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// GetSecretKey secret key to encrypt secrets at rest, e.g. SSH deploy keys of
+// git repos or passwords of data sources, which is set by secret.key (or
+// CRAWLAB_SECRET_KEY), empty if not configured
+func GetSecretKey() (key string) {
+	return viper.GetString("secret.key")
+}
+
+func newAesGcm(secret string) (gcm cipher.AEAD, err error) {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
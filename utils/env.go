@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/models/models"
+)
+
+// MaskSecretEnvs copy of environment variables, of which values of secret
+// ones are masked with constants.SecretMask
+func MaskSecretEnvs(envs []models.Env) (res []models.Env) {
+	for _, env := range envs {
+		if env.Secret && env.Value != "" {
+			env.Value = constants.SecretMask
+		}
+		res = append(res, env)
+	}
+	return res
+}
+
+// RestoreSecretEnvs copy of environment variables, of which masked values of
+// secret ones are restored from the original ones of the same names, or
+// cleared if not found
+func RestoreSecretEnvs(envs, original []models.Env) (res []models.Env) {
+	values := map[string]string{}
+	for _, env := range original {
+		if env.Secret {
+			values[env.Name] = env.Value
+		}
+	}
+	for _, env := range envs {
+		if env.Secret && env.Value == constants.SecretMask {
+			env.Value = values[env.Name]
+		}
+		res = append(res, env)
+	}
+	return res
+}
+
+// HasMaskedSecretEnvs whether any value of secret environment variables is masked
+func HasMaskedSecretEnvs(envs []models.Env) bool {
+	for _, env := range envs {
+		if env.Secret && env.Value == constants.SecretMask {
+			return true
+		}
+	}
+	return false
+}
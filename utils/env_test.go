@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/models/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestMaskSecretEnvs(t *testing.T) {
+	Convey("Test masking and restoring secret envs", t, func() {
+		envs := []models.Env{
+			{Name: "ENV", Value: "prod"},
+			{Name: "TOKEN", Value: "abc", Secret: true},
+		}
+		res := MaskSecretEnvs(envs)
+		So(res[0].Value, ShouldEqual, "prod")
+		So(res[1].Value, ShouldEqual, constants.SecretMask)
+		So(HasMaskedSecretEnvs(res), ShouldBeTrue)
+
+		// original envs unchanged
+		So(envs[1].Value, ShouldEqual, "abc")
+
+		// restored from original envs
+		res = RestoreSecretEnvs(res, envs)
+		So(res[1].Value, ShouldEqual, "abc")
+		So(HasMaskedSecretEnvs(res), ShouldBeFalse)
+
+		// cleared without original envs
+		res = RestoreSecretEnvs(MaskSecretEnvs(envs), nil)
+		So(res[1].Value, ShouldBeEmpty)
+	})
+}
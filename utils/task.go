@@ -1,6 +1,9 @@
 package utils
 
-import "github.com/luke513009828/crawlab-core/constants"
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	"strings"
+)
 
 func IsCancellable(status string) bool {
 	switch status {
@@ -11,3 +14,27 @@ func IsCancellable(status string) bool {
 		return false
 	}
 }
+
+// IsTaskEnded whether the task of the status has reached a terminal status
+func IsTaskEnded(status string) bool {
+	switch status {
+	case constants.TaskStatusFinished,
+		constants.TaskStatusError,
+		constants.TaskStatusCancelled,
+		constants.TaskStatusTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// MaskSecrets replace secret values in the given line with constants.SecretMask
+func MaskSecrets(line string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		line = strings.ReplaceAll(line, secret, constants.SecretMask)
+	}
+	return line
+}
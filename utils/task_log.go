@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"encoding/json"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	errorLogRegex   = regexp.MustCompile("(?i)" + constants.ErrorRegexPattern)
+	warningLogRegex = regexp.MustCompile("(?i)" + constants.WarningRegexPattern)
+	debugLogRegex   = regexp.MustCompile("(?i)" + constants.DebugRegexPattern)
+)
+
+// DetectLogLevel detect level of the log line from its content
+func DetectLogLevel(line string) string {
+	switch {
+	case errorLogRegex.MatchString(line):
+		return constants.LogLevelError
+	case warningLogRegex.MatchString(line):
+		return constants.LogLevelWarning
+	case debugLogRegex.MatchString(line):
+		return constants.LogLevelDebug
+	default:
+		return constants.LogLevelInfo
+	}
+}
+
+// NewTaskLogLine create a log line of the content from the stream at now
+func NewTaskLogLine(content, stream string) interfaces.TaskLogLine {
+	return interfaces.TaskLogLine{
+		Content: content,
+		Ts:      time.Now(),
+		Stream:  stream,
+		Level:   DetectLogLevel(content),
+	}
+}
+
+// storedTaskLogLine log line stored by log drivers, of which the number is
+// omitted as it is given by the position of the line
+type storedTaskLogLine struct {
+	interfaces.TaskLogLine
+	Number *int `json:"n,omitempty"`
+}
+
+// EncodeTaskLogLine encode the log line with metadata to be stored by log drivers
+func EncodeTaskLogLine(l interfaces.TaskLogLine) string {
+	data, err := json.Marshal(&storedTaskLogLine{TaskLogLine: l})
+	if err != nil {
+		return l.Content
+	}
+	return string(data)
+}
+
+// DecodeTaskLogLine decode the log line stored by log drivers, which is a plain
+// line without metadata if it was not encoded by EncodeTaskLogLine
+func DecodeTaskLogLine(s string) (l interfaces.TaskLogLine) {
+	if strings.HasPrefix(s, "{") {
+		if err := json.Unmarshal([]byte(s), &l); err == nil && !l.Ts.IsZero() {
+			return l
+		}
+	}
+	return interfaces.TaskLogLine{
+		Content: s,
+		Level:   DetectLogLevel(s),
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestMaskSecrets(t *testing.T) {
+	Convey("Test mask secret values in log line", t, func() {
+		line := "connecting with password=s3cr3t and token abc"
+		res := MaskSecrets(line, []string{"s3cr3t", "", "abc"})
+		So(res, ShouldEqual, "connecting with password="+constants.SecretMask+" and token "+constants.SecretMask)
+		So(MaskSecrets(line, nil), ShouldEqual, line)
+	})
+}
+
+func TestDetectLogLevel(t *testing.T) {
+	Convey("Test detect level of log line", t, func() {
+		So(DetectLogLevel("2021-01-01 00:00:00 [scrapy.core] ERROR: Spider error processing"), ShouldEqual, constants.LogLevelError)
+		So(DetectLogLevel("Traceback (most recent call last):"), ShouldEqual, constants.LogLevelError)
+		So(DetectLogLevel("2021-01-01 00:00:00 [py.warnings] WARNING: deprecated"), ShouldEqual, constants.LogLevelWarning)
+		So(DetectLogLevel("2021-01-01 00:00:00 [scrapy.core] DEBUG: Crawled (200)"), ShouldEqual, constants.LogLevelDebug)
+		So(DetectLogLevel("crawled 10 pages"), ShouldEqual, constants.LogLevelInfo)
+		So(DetectLogLevel("errorless"), ShouldEqual, constants.LogLevelInfo)
+	})
+}
+
+func TestEncodeDecodeTaskLogLine(t *testing.T) {
+	Convey("Test encode and decode log line with metadata", t, func() {
+		l := NewTaskLogLine("ERROR: failed", constants.LogStreamStderr)
+		l.Number = 10
+		So(EncodeTaskLogLine(l), ShouldNotContainSubstring, `"n":`)
+		res := DecodeTaskLogLine(EncodeTaskLogLine(l))
+		So(res.Number, ShouldEqual, 0)
+		So(res.Content, ShouldEqual, l.Content)
+		So(res.Stream, ShouldEqual, constants.LogStreamStderr)
+		So(res.Level, ShouldEqual, constants.LogLevelError)
+		So(res.Ts.Equal(l.Ts), ShouldBeTrue)
+
+		// plain line
+		res = DecodeTaskLogLine(`{"foo": "bar"}`)
+		So(res.Content, ShouldEqual, `{"foo": "bar"}`)
+		So(res.Ts.IsZero(), ShouldBeTrue)
+		So(res.Level, ShouldEqual, constants.LogLevelInfo)
+	})
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/luke513009828/crawlab-core/constants"
+	"net/http"
+	"strings"
+)
+
+// tokenScopeRunTasksRoutes routes allowed by constants.TokenScopeRunTasks
+var tokenScopeRunTasksRoutes = map[string]bool{
+	http.MethodPut + " /tasks/run":                          true,
+	http.MethodPost + " /tasks/:id/restart":                 true,
+	http.MethodPost + " /tasks/:id/cancel":                  true,
+	http.MethodPost + " /spiders/:id/run":                   true,
+	http.MethodPost + " /workflows/:id/run":                 true,
+	http.MethodPost + " /workflows/:id/runs/:run_id/cancel": true,
+}
+
+// IsValidTokenScope whether the scope is one of constants.TokenScope*
+func IsValidTokenScope(scope string) bool {
+	switch scope {
+	case constants.TokenScopeAll,
+		constants.TokenScopeReadOnly,
+		constants.TokenScopeRunTasks,
+		constants.TokenScopeManageSpiders:
+		return true
+	default:
+		return false
+	}
+}
+
+// HashToken hash of the api token stored in place of the token itself
+func HashToken(tokenStr string) string {
+	sum := sha256.Sum256([]byte(tokenStr))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsTokenScopeAllowed whether the request of the method to the route path is
+// allowed by any of the token scopes, where nil scopes allow all requests.
+// Api tokens are never allowed to manage api tokens
+func IsTokenScopeAllowed(scopes []string, method, path string) bool {
+	if scopes == nil {
+		return true
+	}
+	if path == "/tokens" || strings.HasPrefix(path, "/tokens/") {
+		return false
+	}
+	for _, scope := range scopes {
+		switch scope {
+		case constants.TokenScopeAll:
+			return true
+		case constants.TokenScopeReadOnly:
+			if method == http.MethodGet || method == http.MethodHead {
+				return true
+			}
+		case constants.TokenScopeRunTasks:
+			if tokenScopeRunTasksRoutes[method+" "+path] {
+				return true
+			}
+		case constants.TokenScopeManageSpiders:
+			if path == "/spiders" || strings.HasPrefix(path, "/spiders/") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"github.com/luke513009828/crawlab-core/constants"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"testing"
+)
+
+func TestIsTokenScopeAllowed(t *testing.T) {
+	Convey("Test token scopes allowing requests", t, func() {
+		// login tokens
+		So(IsTokenScopeAllowed(nil, http.MethodDelete, "/users/:id"), ShouldBeTrue)
+
+		// empty scopes
+		So(IsTokenScopeAllowed([]string{}, http.MethodGet, "/spiders"), ShouldBeFalse)
+
+		// all
+		So(IsTokenScopeAllowed([]string{constants.TokenScopeAll}, http.MethodDelete, "/users/:id"), ShouldBeTrue)
+
+		// read-only
+		scopes := []string{constants.TokenScopeReadOnly}
+		So(IsTokenScopeAllowed(scopes, http.MethodGet, "/tasks/:id/logs"), ShouldBeTrue)
+		So(IsTokenScopeAllowed(scopes, http.MethodPut, "/tasks/run"), ShouldBeFalse)
+
+		// run-tasks
+		scopes = []string{constants.TokenScopeRunTasks}
+		So(IsTokenScopeAllowed(scopes, http.MethodPut, "/tasks/run"), ShouldBeTrue)
+		So(IsTokenScopeAllowed(scopes, http.MethodPost, "/spiders/:id/run"), ShouldBeTrue)
+		So(IsTokenScopeAllowed(scopes, http.MethodGet, "/tasks/:id"), ShouldBeFalse)
+		So(IsTokenScopeAllowed(scopes, http.MethodDelete, "/tasks/:id"), ShouldBeFalse)
+
+		// manage-spiders
+		scopes = []string{constants.TokenScopeManageSpiders, constants.TokenScopeReadOnly}
+		So(IsTokenScopeAllowed(scopes, http.MethodPost, "/spiders/:id/files/save"), ShouldBeTrue)
+		So(IsTokenScopeAllowed(scopes, http.MethodDelete, "/spiders/:id"), ShouldBeTrue)
+		So(IsTokenScopeAllowed(scopes, http.MethodGet, "/users"), ShouldBeTrue)
+		So(IsTokenScopeAllowed(scopes, http.MethodPost, "/users/:id"), ShouldBeFalse)
+
+		// api tokens
+		So(IsTokenScopeAllowed(nil, http.MethodGet, "/tokens"), ShouldBeTrue)
+		So(IsTokenScopeAllowed([]string{constants.TokenScopeAll}, http.MethodGet, "/tokens"), ShouldBeFalse)
+		So(IsTokenScopeAllowed([]string{constants.TokenScopeReadOnly}, http.MethodGet, "/tokens/:id"), ShouldBeFalse)
+	})
+}
+
+func TestHashToken(t *testing.T) {
+	Convey("Test hashing api tokens", t, func() {
+		So(HashToken("token"), ShouldEqual, HashToken("token"))
+		So(HashToken("token"), ShouldNotEqual, HashToken("token2"))
+		So(HashToken("token"), ShouldNotContainSubstring, "token")
+	})
+}
@@ -0,0 +1,13 @@
+package workflow
+
+import (
+	"github.com/luke513009828/crawlab-core/interfaces"
+)
+
+type Option func(svc interfaces.WorkflowService)
+
+func WithConfigPath(path string) Option {
+	return func(svc interfaces.WorkflowService) {
+		svc.SetConfigPath(path)
+	}
+}
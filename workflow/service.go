@@ -0,0 +1,458 @@
+package workflow
+
+import (
+	"github.com/apex/log"
+	"github.com/luke513009828/crawlab-core/config"
+	"github.com/luke513009828/crawlab-core/constants"
+	"github.com/luke513009828/crawlab-core/errors"
+	"github.com/luke513009828/crawlab-core/event"
+	"github.com/luke513009828/crawlab-core/interfaces"
+	"github.com/luke513009828/crawlab-core/models/delegate"
+	"github.com/luke513009828/crawlab-core/models/models"
+	"github.com/luke513009828/crawlab-core/models/service"
+	"github.com/luke513009828/crawlab-core/task/scheduler"
+	"github.com/luke513009828/crawlab-core/utils"
+	"github.com/crawlab-team/go-trace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/dig"
+	"sync"
+	"time"
+)
+
+const workflowEventKey = "workflow:service"
+
+type Service struct {
+	// dependencies
+	interfaces.WithConfigPath
+	modelSvc     service.ModelService
+	schedulerSvc interfaces.TaskSchedulerService
+
+	// internals
+	stopped bool
+	mu      sync.Mutex // serialize updates of workflow runs
+}
+
+func (svc *Service) Init() (err error) {
+	return nil
+}
+
+func (svc *Service) Start() {
+	go svc.handleTaskEvents()
+}
+
+func (svc *Service) Wait() {
+	utils.DefaultWait()
+	svc.Stop()
+}
+
+func (svc *Service) Stop() {
+	svc.stopped = true
+}
+
+func (svc *Service) Run(id primitive.ObjectID, args ...interface{}) (runId primitive.ObjectID, err error) {
+	u := utils.GetUserFromArgs(args...)
+
+	// workflow
+	w, err := svc.modelSvc.GetWorkflowById(id)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if err := w.Validate(); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	// workflow run
+	r := &models.WorkflowRun{
+		WorkflowId: w.Id,
+		Status:     constants.WorkflowRunStatusRunning,
+		StartTs:    time.Now(),
+	}
+	if u != nil {
+		r.UserId = u.GetId()
+	}
+	for _, n := range w.Nodes {
+		r.Nodes = append(r.Nodes, models.WorkflowRunNode{
+			Key:    n.Key,
+			Status: constants.WorkflowNodeStatusPending,
+		})
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	// add workflow run
+	if err := delegate.NewModelDelegate(r, u).Add(); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	// enqueue root nodes
+	if err := svc.evaluate(w, r); err != nil {
+		return r.Id, err
+	}
+
+	return r.Id, nil
+}
+
+func (svc *Service) Cancel(runId primitive.ObjectID, args ...interface{}) (err error) {
+	u := utils.GetUserFromArgs(args...)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	// workflow run
+	r, err := svc.modelSvc.GetWorkflowRunById(runId)
+	if err != nil {
+		return err
+	}
+	if r.Status != constants.WorkflowRunStatusRunning {
+		return errors.ErrorWorkflowRunNotCancellable
+	}
+
+	// cancel pending and running nodes
+	for i := range r.Nodes {
+		rn := &r.Nodes[i]
+		switch rn.Status {
+		case constants.WorkflowNodeStatusPending:
+			rn.Status = constants.TaskStatusCancelled
+		case constants.WorkflowNodeStatusRunning:
+			for _, taskId := range rn.TaskIds {
+				t, err := svc.modelSvc.GetTaskById(taskId)
+				if err != nil {
+					return err
+				}
+				if !utils.IsCancellable(t.Status) {
+					continue
+				}
+				if err := svc.schedulerSvc.Cancel(taskId, u); err != nil {
+					return err
+				}
+			}
+			rn.Status = constants.TaskStatusCancelled
+		}
+	}
+
+	// workflow run status
+	r.Status = constants.WorkflowRunStatusCancelled
+	r.EndTs = time.Now()
+
+	return delegate.NewModelDelegate(r, u).Save()
+}
+
+// handleTaskEvents subscribe to task change events and update the workflow runs
+// which the tasks belong to
+func (svc *Service) handleTaskEvents() {
+	ch := make(chan interfaces.EventData)
+	eventSvc := event.NewEventService()
+	eventSvc.Register(workflowEventKey, "^model:"+interfaces.ModelColNameTask+":change$", "^$", &ch)
+	defer eventSvc.Unregister(workflowEventKey)
+
+	for {
+		if svc.stopped {
+			return
+		}
+
+		ed := <-ch
+		doc, ok := ed.GetData().(*models.Task)
+		if !ok || doc.WorkflowRunId.IsZero() {
+			continue
+		}
+		if err := svc.update(doc.GetId()); err != nil {
+			trace.PrintError(err)
+		}
+	}
+}
+
+// update update status of the workflow run node which the task belongs to,
+// and evaluate downstream nodes if the node is done
+// TODO: retries of failed tasks are not tracked by workflow runs
+func (svc *Service) update(taskId primitive.ObjectID) (err error) {
+	// task
+	t, err := svc.modelSvc.GetTaskById(taskId)
+	if err != nil {
+		return err
+	}
+	if t.WorkflowRunId.IsZero() {
+		return nil
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	// workflow run
+	r, err := svc.modelSvc.GetWorkflowRunById(t.WorkflowRunId)
+	if err != nil {
+		return err
+	}
+	if r.Status != constants.WorkflowRunStatusRunning {
+		return nil
+	}
+
+	// workflow run node of the task
+	var rn *models.WorkflowRunNode
+	for i := range r.Nodes {
+		for _, id := range r.Nodes[i].TaskIds {
+			if id == t.Id {
+				rn = &r.Nodes[i]
+			}
+		}
+	}
+	if rn == nil || rn.Status != constants.WorkflowNodeStatusRunning {
+		return nil
+	}
+
+	// workflow run node status
+	status, err := svc.getNodeStatus(rn)
+	if err != nil {
+		return err
+	}
+	if status == rn.Status {
+		return nil
+	}
+	rn.Status = status
+
+	// workflow
+	w, err := svc.modelSvc.GetWorkflowById(r.WorkflowId)
+	if err != nil {
+		return err
+	}
+
+	return svc.evaluate(w, r)
+}
+
+// evaluate enqueue pending nodes of which upstream nodes are all done, skip those
+// of which edge conditions are not satisfied, and save the workflow run
+func (svc *Service) evaluate(w *models.Workflow, r *models.WorkflowRun) (err error) {
+	for changed := true; changed; {
+		changed = false
+		for i := range r.Nodes {
+			rn := &r.Nodes[i]
+			if rn.Status != constants.WorkflowNodeStatusPending {
+				continue
+			}
+
+			// upstream nodes
+			ready, satisfied := true, true
+			for _, e := range w.GetUpstreamEdges(rn.Key) {
+				up := r.GetNode(e.Source)
+				if up == nil || !models.IsWorkflowNodeDone(up.Status) {
+					ready = false
+					break
+				}
+				if !e.IsSatisfied(up.Status) {
+					satisfied = false
+				}
+			}
+			if !ready {
+				continue
+			}
+			changed = true
+
+			// skip if conditions are not satisfied
+			if !satisfied {
+				rn.Status = constants.WorkflowNodeStatusSkipped
+				continue
+			}
+
+			// enqueue tasks
+			taskIds, err := svc.enqueue(w.GetNode(rn.Key), r)
+			if err != nil {
+				trace.PrintError(err)
+				rn.Status = constants.TaskStatusError
+				continue
+			}
+			rn.Status = constants.WorkflowNodeStatusRunning
+			rn.TaskIds = taskIds
+		}
+	}
+
+	// workflow run status
+	done, failed := true, false
+	for _, rn := range r.Nodes {
+		if !models.IsWorkflowNodeDone(rn.Status) {
+			done = false
+		}
+		if rn.Status != constants.TaskStatusFinished && rn.Status != constants.WorkflowNodeStatusSkipped {
+			failed = true
+		}
+	}
+	if done {
+		if failed {
+			r.Status = constants.WorkflowRunStatusError
+		} else {
+			r.Status = constants.WorkflowRunStatusFinished
+		}
+		r.EndTs = time.Now()
+		log.Infof("workflow run[%s] of workflow[%s] ended with status %s", r.Id.Hex(), w.Id.Hex(), r.Status)
+	}
+
+	return delegate.NewModelDelegate(r).Save()
+}
+
+// enqueue enqueue tasks of the workflow node through task scheduler
+func (svc *Service) enqueue(n *models.WorkflowNode, r *models.WorkflowRun) (taskIds []primitive.ObjectID, err error) {
+	// spider
+	s, err := svc.modelSvc.GetSpiderById(n.SpiderId)
+	if err != nil {
+		return nil, err
+	}
+
+	// task type
+	taskType := n.Type
+	if taskType == "" {
+		taskType = constants.TaskTypeSpider
+	}
+
+	// timeout
+	timeout := n.Timeout
+	if timeout == 0 {
+		timeout = s.Timeout
+	}
+
+	// node ids
+	nodeIds, err := svc.getNodeIds(n)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nodeId := range nodeIds {
+		t := &models.Task{
+			SpiderId:      s.Id,
+			Type:          taskType,
+			Cmd:           n.Cmd,
+			Param:         n.Param,
+			Mode:          n.Mode,
+			NodeId:        nodeId,
+			NodeIds:       n.NodeIds,
+			Priority:      n.Priority,
+			Envs:          n.Envs,
+			Timeout:       timeout,
+			WorkflowRunId: r.Id,
+			UserId:        r.UserId,
+		}
+		if err := svc.schedulerSvc.Enqueue(t); err != nil {
+			return nil, err
+		}
+		taskIds = append(taskIds, t.Id)
+	}
+
+	return taskIds, nil
+}
+
+// getNodeIds get ids of nodes to run tasks of the workflow node on,
+// where a nil id means a random node
+func (svc *Service) getNodeIds(n *models.WorkflowNode) (nodeIds []primitive.ObjectID, err error) {
+	switch n.Mode {
+	case constants.RunTypeAllNodes:
+		query := bson.M{
+			"active":  true,
+			"enabled": true,
+			"status":  constants.NodeStatusOnline,
+		}
+		nodes, err := svc.modelSvc.GetNodeList(query, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			nodeIds = append(nodeIds, node.GetId())
+		}
+	case constants.RunTypeSelectedNodes:
+		nodeIds = n.NodeIds
+	}
+	if len(nodeIds) == 0 {
+		nodeIds = []primitive.ObjectID{primitive.NilObjectID}
+	}
+	return nodeIds, nil
+}
+
+// getNodeStatus get status of the workflow run node from its tasks, which is
+// running until all tasks end, and finished only if all tasks finished
+func (svc *Service) getNodeStatus(rn *models.WorkflowRunNode) (status string, err error) {
+	status = constants.TaskStatusFinished
+	for _, taskId := range rn.TaskIds {
+		t, err := svc.modelSvc.GetTaskById(taskId)
+		if err != nil {
+			return "", err
+		}
+		switch t.Status {
+		case constants.TaskStatusPending, constants.TaskStatusRunning:
+			return constants.WorkflowNodeStatusRunning, nil
+		case constants.TaskStatusFinished:
+		default:
+			status = t.Status
+		}
+	}
+	return status, nil
+}
+
+func NewWorkflowService(opts ...Option) (svc2 interfaces.WorkflowService, err error) {
+	// service
+	svc := &Service{
+		WithConfigPath: config.NewConfigPathService(),
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	// dependency injection
+	c := dig.New()
+	if err := c.Provide(service.GetService); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Provide(scheduler.ProvideGetTaskSchedulerService(svc.GetConfigPath())); err != nil {
+		return nil, trace.TraceError(err)
+	}
+	if err := c.Invoke(func(
+		modelSvc service.ModelService,
+		schedulerSvc interfaces.TaskSchedulerService,
+	) {
+		svc.modelSvc = modelSvc
+		svc.schedulerSvc = schedulerSvc
+	}); err != nil {
+		return nil, trace.TraceError(err)
+	}
+
+	// initialize
+	if err := svc.Init(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+func ProvideWorkflowService(path string, opts ...Option) func() (svc interfaces.WorkflowService, err error) {
+	opts = append(opts, WithConfigPath(path))
+	return func() (svc interfaces.WorkflowService, err error) {
+		return NewWorkflowService(opts...)
+	}
+}
+
+var store = sync.Map{}
+
+func GetWorkflowService(path string, opts ...Option) (svc interfaces.WorkflowService, err error) {
+	if path == "" {
+		path = config.DefaultConfigPath
+	}
+	opts = append(opts, WithConfigPath(path))
+	res, ok := store.Load(path)
+	if ok {
+		svc, ok = res.(interfaces.WorkflowService)
+		if ok {
+			return svc, nil
+		}
+	}
+	svc, err = NewWorkflowService(opts...)
+	if err != nil {
+		return nil, err
+	}
+	store.Store(path, svc)
+	return svc, nil
+}
+
+func ProvideGetWorkflowService(path string, opts ...Option) func() (svc interfaces.WorkflowService, err error) {
+	return func() (svc interfaces.WorkflowService, err error) {
+		return GetWorkflowService(path, opts...)
+	}
+}